@@ -6,32 +6,97 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"tv-tracker/internal/auth"
+	"tv-tracker/internal/backupstore"
 	"tv-tracker/internal/handler"
+	"tv-tracker/internal/logging"
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/notify"
 	"tv-tracker/internal/repository"
+	"tv-tracker/internal/rpc"
 	"tv-tracker/internal/service"
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/webhook"
 )
 
 // Config holds the application configuration
 type Config struct {
-	TMDBAPIKey       string
-	TelegramBotToken string
-	TelegramChatID   string
-	DBPath           string
-	Port             string
+	TMDBAPIKey        string
+	TMDBLanguage      string
+	TMDBFallbackLangs []string
+	TelegramBotToken  string
+	TelegramChatID    string
+	// TelegramChannelID, if set, is the separate channel TelegramBot.
+	// SendDailyReport broadcasts to in addition to TelegramChatID (see
+	// notify.NewTelegramBot's channelID parameter).
+	TelegramChannelID string
+	NotifyURLs        string
+	SyncCron          string
+	ReportCron        string
+	BackupCron        string
+	BackupS3Endpoint  string
+	BackupS3Bucket    string
+	BackupS3Region    string
+	BackupS3AccessKey string
+	BackupS3SecretKey string
+	BackupWebDAVURL   string
+	BackupWebDAVUser  string
+	BackupWebDAVPass  string
+	// SonarrURL/SonarrAPIKey/SonarrProfileID/SonarrRootFolder are the settings
+	// of a Sonarr downloader.Client (see notify.Dependencies.Downloader).
+	// loadConfig reads them, but main builds no downloader.Client from them
+	// and leaves notify.Dependencies.Downloader unset.
+	SonarrURL        string
+	SonarrAPIKey     string
+	SonarrProfileID  int
+	SonarrRootFolder string
+	// QBitURL/QBitUser/QBitPass/QBitIndexerURL are the settings of a
+	// qBittorrent downloader.Client; main builds no client from them either.
+	QBitURL        string
+	QBitUser       string
+	QBitPass       string
+	QBitIndexerURL string
+	// ScheduleRunOnInit is the runOnInit flag of
+	// internal/service/scheduler.NewTaskScheduler; main starts no
+	// TaskScheduler, so nothing reads it.
+	ScheduleRunOnInit bool
+	LogFormat         string
+	MetricsToken      string
+	// APIToken gates every /api/* route the handler's per-key scopes don't
+	// already cover (see auth.Manager, handler.HTTPHandler.authMiddleware).
+	APIToken string
+	// ICSEventDays is the VEVENT duration used by the /api/calendar.ics feed.
+	ICSEventDays int
+	// APIKeyRPM is the default per-key requests-per-minute budget handed to
+	// auth.NewManager; values <= 0 fall back to its own default.
+	APIKeyRPM int
+	// BackupDir is the local directory BackupService writes snapshots to,
+	// in addition to any configured S3/WebDAV targets.
+	BackupDir string
+	DBPath    string
+	Port      string
 }
 
 func main() {
+	// "migrate status|up|down N" is a separate subcommand, dispatched before
+	// the normal flag set since it doesn't run the server at all.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
 	// Parse CLI flags
 	reportMode := flag.Bool("report", false, "Send daily report and exit (for cron jobs)")
 	flag.Parse()
 
 	// Load configuration
 	config := loadConfig()
+	logging.Setup(config.LogFormat)
 
 	// Initialize database
 	db, err := repository.NewSQLiteDB(config.DBPath)
@@ -49,19 +114,89 @@ func main() {
 	showRepo := repository.NewTVShowRepository(db)
 	episodeRepo := repository.NewEpisodeRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	metadataCacheRepo := repository.NewMetadataCacheRepository(db)
+	subscriberRepo := repository.NewShowSubscriberRepository(db)
+	taskAuditRepo := repository.NewTaskAuditRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	scheduleRuleRepo := repository.NewScheduleRuleRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
 
-	// Initialize TMDB client
-	tmdbClient := tmdb.NewClient(config.TMDBAPIKey)
+	// Initialize TMDB client, preferring a key rotated live via the Telegram
+	// bot (see tmdb.Client.SetAPIKey) over the TMDB_API_KEY env var.
+	tmdbAPIKey := config.TMDBAPIKey
+	if storedKey, ok, err := settingsRepo.Get(repository.SettingKeyTMDBAPIKey); err != nil {
+		log.Printf("failed to load stored TMDB API key, falling back to env var: %v", err)
+	} else if ok {
+		tmdbAPIKey = storedKey
+	}
+	tmdbClient := tmdb.NewClient(tmdbAPIKey)
+	tmdbClient.SetLanguage(config.TMDBLanguage)
 
 	// Initialize services
-	subManager := service.NewSubscriptionManager(tmdbClient, showRepo, episodeRepo)
-	taskGenerator := service.NewTaskGenerator(tmdbClient, showRepo, episodeRepo, taskRepo)
-	taskBoard := service.NewTaskBoardService(taskRepo, showRepo)
+	cacheSvc := service.NewTMDBCacheService(tmdbClient, metadataCacheRepo)
+	cacheSvc.SetLanguagePreference(config.TMDBLanguage, config.TMDBFallbackLangs)
+
+	// providers is keyed by TVShow.MetadataSource; only the TMDB provider is
+	// wired by default; NewTaskGenerator and NewTaskBoardService fall back to
+	// it for any show not pinned to another provider.
+	tmdbProvider := metadata.NewTMDBProvider(tmdbClient)
+	providers := map[string]metadata.Provider{tmdbProvider.ID(): tmdbProvider}
+
+	webhookMgr := webhook.NewManager(webhookRepo)
+	notifyRegistry := notify.NewRegistry()
+	dispatcher := service.NewDispatcher()
+
+	subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, subscriberRepo)
+	taskGenerator := service.NewTaskGenerator(tmdbClient, cacheSvc, showRepo, episodeRepo, taskRepo, providers, webhookMgr, notifyRegistry, dispatcher)
+	taskBoard := service.NewTaskBoardService(taskRepo, showRepo, dispatcher, taskAuditRepo, providers)
+
+	backupSvc := service.NewBackupService(db.DB(), config.DBPath, config.BackupDir)
+	if config.BackupS3Endpoint != "" && config.BackupS3Bucket != "" {
+		backupSvc.AddTarget(backupstore.NewS3Target(config.BackupS3Endpoint, config.BackupS3Bucket, config.BackupS3Region, config.BackupS3AccessKey, config.BackupS3SecretKey))
+	}
+	if config.BackupWebDAVURL != "" {
+		backupSvc.AddTarget(backupstore.NewWebDAVTarget(config.BackupWebDAVURL, config.BackupWebDAVUser, config.BackupWebDAVPass))
+	}
+
+	syncScheduler, err := service.NewSyncScheduler(cacheSvc, showRepo, taskGenerator, syncRunRepo, config.SyncCron)
+	if err != nil {
+		log.Fatalf("Failed to configure sync scheduler: %v", err)
+	}
+	syncScheduler.Start()
+	defer syncScheduler.Stop()
+
+	authMgr := auth.NewManager(apiKeyRepo, config.APIKeyRPM)
 
 	// Initialize Telegram notifier (optional - may not be configured)
 	var notifier *notify.TelegramNotifier
+	var telegramBot *notify.TelegramBot
 	if config.TelegramBotToken != "" && config.TelegramChatID != "" {
-		notifier = notify.NewTelegramNotifier(config.TelegramBotToken, config.TelegramChatID)
+		chatID, err := strconv.ParseInt(config.TelegramChatID, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid TELEGRAM_CHAT_ID %q: %v", config.TelegramChatID, err)
+		}
+		var channelID int64
+		if config.TelegramChannelID != "" {
+			channelID, err = strconv.ParseInt(config.TelegramChannelID, 10, 64)
+			if err != nil {
+				log.Fatalf("invalid TELEGRAM_CHANNEL_ID %q: %v", config.TelegramChannelID, err)
+			}
+		}
+
+		telegramBot, err = notify.NewTelegramBot(config.TelegramBotToken, chatID, channelID, notify.Dependencies{
+			TMDB:        tmdbClient,
+			SubMgr:      subManager,
+			TaskGen:     taskGenerator,
+			TaskBoard:   taskBoard,
+			EpisodeRepo: episodeRepo,
+		})
+		if err != nil {
+			log.Fatalf("Failed to start Telegram bot: %v", err)
+		}
+		go telegramBot.Start()
+		notifier = notify.NewTelegramNotifier(telegramBot)
 	}
 
 	// CLI mode: send daily report and exit
@@ -88,7 +223,26 @@ func main() {
 
 	// Web server mode
 	// Initialize handler
-	h := handler.NewHandler(tmdbClient, subManager, taskGenerator, taskBoard, notifier)
+	h := handler.NewHTTPHandler(
+		tmdbClient,
+		subManager,
+		taskBoard,
+		episodeRepo,
+		showRepo,
+		taskRepo,
+		backupSvc,
+		webhookMgr,
+		notifyRegistry,
+		authMgr,
+		config.APIToken,
+		config.ICSEventDays,
+		syncScheduler,
+		config.MetricsToken,
+		scheduleRuleRepo,
+	)
+	if notifier != nil {
+		notifyRegistry.Register(notifier)
+	}
 
 	// Setup Gin router
 	router := gin.Default()
@@ -99,7 +253,7 @@ func main() {
 		router.Static("/assets", filepath.Join(webDistPath, "assets"))
 		router.StaticFile("/", filepath.Join(webDistPath, "index.html"))
 		router.StaticFile("/vite.svg", filepath.Join(webDistPath, "vite.svg"))
-		
+
 		// Handle SPA routing - serve index.html for non-API routes
 		router.NoRoute(func(c *gin.Context) {
 			c.File(filepath.Join(webDistPath, "index.html"))
@@ -109,6 +263,16 @@ func main() {
 	// Register API routes
 	h.RegisterRoutes(router)
 
+	// Mount the Twirp RPC surface for external automation clients. A
+	// StaticAuthorizer grants every caller RoleEditor, matching a trusted
+	// LAN deployment; per-caller identity isn't modeled here yet.
+	var reportSvc service.ReportSender
+	if telegramBot != nil {
+		reportSvc = telegramBot
+	}
+	rpcService := rpc.NewTVTrackerService(tmdbClient, subManager, taskRepo, cacheSvc, reportSvc, rpc.StaticAuthorizer{Role: rpc.RoleEditor})
+	router.Any("/twirp/*any", gin.WrapH(rpc.NewHandler(rpcService)))
+
 	// Start server
 	addr := ":" + config.Port
 	log.Printf("Starting TV Tracker server on %s", addr)
@@ -117,15 +281,121 @@ func main() {
 	}
 }
 
+// runMigrateCommand implements the "migrate status|up|down N" subcommand.
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: migrate status|up|down N")
+	}
+
+	config := loadConfig()
+	db, err := repository.NewSQLiteDB(config.DBPath)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "status":
+		statuses, err := repository.Status(db.DB())
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%4d  %-30s %s\n", s.Version, s.Name, state)
+		}
+
+	case "up":
+		if err := repository.ApplyMigrations(db.DB()); err != nil {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+		fmt.Println("Migrations applied successfully!")
+
+	case "down":
+		if len(args) < 2 {
+			log.Fatal("usage: migrate down N")
+		}
+		var n int
+		if _, err := fmt.Sscanf(args[1], "%d", &n); err != nil || n <= 0 {
+			log.Fatalf("invalid migration count %q", args[1])
+		}
+		reverted, err := repository.Down(db.DB(), n)
+		if err != nil {
+			log.Fatalf("Failed to revert migrations: %v", err)
+		}
+		for _, s := range reverted {
+			fmt.Printf("unmarked %d  %s (schema itself was not reverted)\n", s.Version, s.Name)
+		}
+
+	default:
+		log.Fatalf("unknown migrate subcommand %q (want status|up|down N)", args[0])
+	}
+}
+
 // loadConfig loads configuration from environment variables
 // Requirements: 8.3 - Load configuration on application start
 func loadConfig() *Config {
 	config := &Config{
-		TMDBAPIKey:       getEnv("TMDB_API_KEY", ""),
-		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
-		TelegramChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
-		DBPath:           getEnv("DB_PATH", "tv_tracker.db"),
-		Port:             getEnv("PORT", "8080"),
+		TMDBAPIKey:        getEnv("TMDB_API_KEY", ""),
+		TMDBLanguage:      getEnv("TMDB_LANGUAGE", tmdb.DefaultLanguage),
+		TMDBFallbackLangs: splitNonEmpty(getEnv("TMDB_FALLBACK_LANGUAGES", "en-US")),
+		TelegramBotToken:  getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:    getEnv("TELEGRAM_CHAT_ID", ""),
+		TelegramChannelID: getEnv("TELEGRAM_CHANNEL_ID", ""),
+		// NotifyURLs is a comma-separated list of scheme URLs such as
+		// "tgram://token/chatid,gotify://host/token,ntfy://host/topic,
+		// webhook+https://host/hook", in the form notify.ParseNotifyURLs
+		// accepts; main does not parse it or build notifiers from it.
+		NotifyURLs: getEnv("NOTIFY_URLS", ""),
+		// SyncCron governs the scheduled TMDB refresh + SyncAll job (see
+		// service.NewSyncScheduler) in standard 5-field cron syntax.
+		// ReportCron is read here, but main registers no daily report job
+		// with service.Scheduler, so it is unused.
+		SyncCron:   getEnv("SYNC_CRON", "0 */6 * * *"),
+		ReportCron: getEnv("REPORT_CRON", ""),
+		// BackupCron is the schedule service.NewBackupScheduler takes, but
+		// main starts no BackupScheduler, so no snapshot runs on a schedule;
+		// the BackupS3*/BackupWebDAV* fields
+		// are optional push targets registered with BackupService in
+		// addition to the default local backupDir (see
+		// BackupService.AddTarget). Leaving the S3/WebDAV fields empty
+		// keeps backups local-only.
+		BackupCron:        getEnv("BACKUP_CRON", "0 3 * * *"),
+		BackupS3Endpoint:  getEnv("BACKUP_S3_ENDPOINT", ""),
+		BackupS3Bucket:    getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Region:    getEnv("BACKUP_S3_REGION", ""),
+		BackupS3AccessKey: getEnv("BACKUP_S3_ACCESS_KEY", ""),
+		BackupS3SecretKey: getEnv("BACKUP_S3_SECRET_KEY", ""),
+		BackupWebDAVURL:   getEnv("BACKUP_WEBDAV_URL", ""),
+		BackupWebDAVUser:  getEnv("BACKUP_WEBDAV_USER", ""),
+		BackupWebDAVPass:  getEnv("BACKUP_WEBDAV_PASS", ""),
+		// SonarrURL/QBitURL: read here, but main builds no downloader.Client
+		// from either (see the Config field comments).
+		SonarrURL:        getEnv("SONARR_URL", ""),
+		SonarrAPIKey:     getEnv("SONARR_API_KEY", ""),
+		SonarrProfileID:  getEnvInt("SONARR_PROFILE_ID", 1),
+		SonarrRootFolder: getEnv("SONARR_ROOT_FOLDER", ""),
+		QBitURL:          getEnv("QBIT_URL", ""),
+		QBitUser:         getEnv("QBIT_USER", ""),
+		QBitPass:         getEnv("QBIT_PASS", ""),
+		QBitIndexerURL:   getEnv("QBIT_INDEXER_URL", ""),
+		// ScheduleRunOnInit: read here, but main builds no TaskScheduler to
+		// pass it to.
+		ScheduleRunOnInit: getEnvBool("SCHEDULE_RUN_ON_INIT", false),
+		// LogFormat selects log/slog's output encoding ("json" or the
+		// default human-readable text); MetricsToken optionally gates
+		// GET /metrics the same way WEB_API_TOKEN gates the rest of the API.
+		LogFormat:    getEnv("LOG_FORMAT", ""),
+		MetricsToken: getEnv("METRICS_TOKEN", ""),
+		APIToken:     getEnv("WEB_API_TOKEN", ""),
+		ICSEventDays: getEnvInt("ICS_EVENT_DAYS", 1),
+		APIKeyRPM:    getEnvInt("API_KEY_RPM", 60),
+		BackupDir:    getEnv("BACKUP_DIR", "backups"),
+		DBPath:       getEnv("DB_PATH", "tv_tracker.db"),
+		Port:         getEnv("PORT", "8080"),
 	}
 
 	// Validate required configuration
@@ -143,3 +413,43 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt parses an integer env var, falling back to defaultValue if
+// unset or unparseable.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvBool parses a boolean env var, falling back to defaultValue if unset
+// or unparseable.
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// splitNonEmpty splits a comma-separated env var value, dropping blank entries.
+func splitNonEmpty(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
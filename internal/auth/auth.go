@@ -0,0 +1,205 @@
+// Package auth issues and verifies per-key API credentials for the HTTP
+// API: argon2id-hashed secrets persisted via repository.APIKeyRepository, a
+// Gin middleware that authenticates a bearer token and checks its scope,
+// and a token-bucket rate limiter applied per key.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/time/rate"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+)
+
+const (
+	saltSize   = 16
+	secretSize = 32
+
+	// argon2id parameters per the library's recommended defaults for
+	// interactive login (single-digit millisecond verify time).
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+
+	defaultRequestsPerMinute = 60
+)
+
+// Manager issues, verifies, and rate-limits API keys.
+type Manager struct {
+	repo *repository.APIKeyRepository
+	rpm  int
+
+	mu       sync.Mutex
+	limiters map[int64]*rate.Limiter
+}
+
+// NewManager creates a Manager. rpm configures the default per-key
+// requests-per-minute budget; values <= 0 fall back to 60.
+func NewManager(repo *repository.APIKeyRepository, rpm int) *Manager {
+	if rpm <= 0 {
+		rpm = defaultRequestsPerMinute
+	}
+	return &Manager{
+		repo:     repo,
+		rpm:      rpm,
+		limiters: make(map[int64]*rate.Limiter),
+	}
+}
+
+// IssueKey generates a new random secret, hashes it with argon2id, and
+// persists the key with the given owner label, scopes, and optional expiry.
+// It returns the stored record and the one-time bearer token the caller
+// must save now ("<id>.<secret>") — the plaintext secret is never stored
+// and cannot be recovered later.
+func (m *Manager) IssueKey(ownerLabel string, scopes models.APIKeyScope, expiresAt *time.Time) (*models.APIKey, string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	hash := argon2.IDKey(secret, salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	key := &models.APIKey{
+		OwnerLabel: ownerLabel,
+		SecretHash: base64.RawStdEncoding.EncodeToString(hash),
+		Salt:       base64.RawStdEncoding.EncodeToString(salt),
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+	}
+	if err := m.repo.Create(key); err != nil {
+		return nil, "", fmt.Errorf("failed to persist API key: %w", err)
+	}
+
+	token := fmt.Sprintf("%d.%s", key.ID, base64.RawURLEncoding.EncodeToString(secret))
+	return key, token, nil
+}
+
+// List returns every issued key, including revoked and expired ones.
+func (m *Manager) List() ([]models.APIKey, error) {
+	return m.repo.GetAll()
+}
+
+// Revoke marks a key as revoked; Authenticate rejects it from then on.
+func (m *Manager) Revoke(id int64) error {
+	return m.repo.Revoke(id)
+}
+
+// Authenticate parses a bearer token of the form "<id>.<secret>", verifies
+// secret against the key's stored argon2id hash with a constant-time
+// comparison, rejects revoked or expired keys, and records last_used_at on
+// success.
+func (m *Manager) Authenticate(token string) (*models.APIKey, error) {
+	idPart, secretPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("malformed API key")
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed API key")
+	}
+
+	key, err := m.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("API key has been revoked")
+	}
+	if key.ExpiresAt != nil && timeutil.Now().After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("API key has expired")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(key.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt API key record")
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(secretPart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	storedHash, err := base64.RawStdEncoding.DecodeString(key.SecretHash)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt API key record")
+	}
+
+	computed := argon2.IDKey(secret, salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	if subtle.ConstantTimeCompare(computed, storedHash) != 1 {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	if err := m.repo.TouchLastUsed(key.ID); err != nil {
+		return nil, fmt.Errorf("failed to record key usage: %w", err)
+	}
+
+	return key, nil
+}
+
+// Allow admits one request against keyID's token bucket, creating the
+// bucket lazily on first use sized to the manager's configured RPM.
+func (m *Manager) Allow(keyID int64) bool {
+	m.mu.Lock()
+	limiter, ok := m.limiters[keyID]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(float64(m.rpm)/60.0), m.rpm)
+		m.limiters[keyID] = limiter
+	}
+	m.mu.Unlock()
+	return limiter.Allow()
+}
+
+// RequireScope returns Gin middleware that authenticates the request's
+// "Authorization: Bearer <id>.<secret>" header, rejects it unless the key
+// holds want, and enforces the key's per-key rate limit.
+func (m *Manager) RequireScope(want models.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid Authorization header"})
+			c.Abort()
+			return
+		}
+
+		key, err := m.Authenticate(strings.TrimSpace(parts[1]))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !key.Scopes.Has(want) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the required scope"})
+			c.Abort()
+			return
+		}
+
+		if !m.Allow(key.ID) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
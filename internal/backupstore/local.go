@@ -0,0 +1,81 @@
+package backupstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LocalTarget stores snapshots as files in a directory on the local
+// filesystem. This is the default target so backups keep working with no
+// configuration.
+type LocalTarget struct {
+	dir string
+}
+
+// NewLocalTarget creates a LocalTarget rooted at dir, creating it if it
+// doesn't already exist.
+func NewLocalTarget(dir string) (*LocalTarget, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create local backup target directory: %w", err)
+	}
+	return &LocalTarget{dir: dir}, nil
+}
+
+func (t *LocalTarget) Name() string { return "local" }
+
+func (t *LocalTarget) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	dest, err := os.Create(filepath.Join(t.dir, key))
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, r); err != nil {
+		return err
+	}
+	return dest.Sync()
+}
+
+func (t *LocalTarget) List(ctx context.Context) ([]Snapshot, error) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{
+			Key:     entry.Name(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ModTime.Before(snapshots[j].ModTime) })
+	return snapshots, nil
+}
+
+func (t *LocalTarget) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(t.dir, key))
+}
+
+func (t *LocalTarget) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(t.dir, key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package backupstore
+
+import (
+	"strconv"
+	"time"
+)
+
+// RetentionPolicy controls how many snapshots ApplyRetention keeps on a
+// target. KeepLast keeps the N most recent snapshots unconditionally; the
+// keep-daily/weekly/monthly buckets additionally keep the newest snapshot
+// out of each of the last N calendar days/ISO weeks/months, so a schedule
+// that backs up hourly doesn't collapse straight down to KeepLast. A zero
+// field disables that bucket.
+type RetentionPolicy struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+// ApplyRetention splits snapshots (assumed sorted oldest-first, as every
+// Target.List implementation returns them) into the set to keep and the set
+// to remove under policy.
+func ApplyRetention(snapshots []Snapshot, policy RetentionPolicy) (keep, remove []Snapshot) {
+	kept := make(map[string]bool, len(snapshots))
+
+	keepLast := policy.KeepLast
+	if keepLast > len(snapshots) {
+		keepLast = len(snapshots)
+	}
+	for _, s := range snapshots[len(snapshots)-keepLast:] {
+		kept[s.Key] = true
+	}
+
+	keepNewestPerBucket(snapshots, policy.KeepDaily, kept, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPerBucket(snapshots, policy.KeepWeekly, kept, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return strconv.Itoa(year) + "-W" + strconv.Itoa(week)
+	})
+	keepNewestPerBucket(snapshots, policy.KeepMonthly, kept, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	for _, s := range snapshots {
+		if kept[s.Key] {
+			keep = append(keep, s)
+		} else {
+			remove = append(remove, s)
+		}
+	}
+	return keep, remove
+}
+
+// keepNewestPerBucket marks the newest snapshot in each of the last
+// maxBuckets distinct bucketOf() values as kept. Buckets are visited newest
+// snapshot first so "the last N buckets" means the N most recent calendar
+// days/weeks/months that actually have a snapshot, not the last N entries.
+func keepNewestPerBucket(snapshots []Snapshot, maxBuckets int, kept map[string]bool, bucketOf func(time.Time) string) {
+	if maxBuckets <= 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	for i := len(snapshots) - 1; i >= 0 && len(seen) < maxBuckets; i-- {
+		s := snapshots[i]
+		bucket := bucketOf(s.ModTime)
+		if seen[bucket] {
+			continue
+		}
+		seen[bucket] = true
+		kept[s.Key] = true
+	}
+}
@@ -0,0 +1,228 @@
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Target stores snapshots as objects in an S3-compatible bucket
+// (AWS S3, MinIO, Backblaze B2, Cloudflare R2). Requests are signed with AWS
+// SigV4 by hand rather than through the official SDK, since put/get/list on
+// a single bucket is a small enough surface that pulling in the full SDK
+// (service clients, retries, pagination, credential chains) isn't worth the
+// dependency weight.
+type S3Target struct {
+	endpoint  string // e.g. "https://s3.us-west-000.backblazeb2.com"
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3Target creates an S3Target. region may be "" for providers (MinIO,
+// R2) that don't enforce it; "us-east-1" is used in the signature in that
+// case, which every S3-compatible implementation accepts.
+func NewS3Target(endpoint, bucket, region, accessKey, secretKey string) *S3Target {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Target{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (t *S3Target) Name() string { return "s3:" + t.bucket }
+
+func (t *S3Target) objectURL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", t.endpoint, t.bucket, key)
+}
+
+func (t *S3Target) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	t.sign(req, body)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 PUT %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string `xml:"Key"`
+		Size         int64  `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+func (t *S3Target) List(ctx context.Context) ([]Snapshot, error) {
+	url := fmt.Sprintf("%s/%s?list-type=2", t.endpoint, t.bucket)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.sign(req, nil)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("s3 ListObjectsV2: unexpected status %s", resp.Status)
+	}
+
+	var result s3ListResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse ListObjectsV2 response: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(result.Contents))
+	for _, c := range result.Contents {
+		modTime, _ := time.Parse(time.RFC3339, c.LastModified)
+		snapshots = append(snapshots, Snapshot{Key: c.Key, Size: c.Size, ModTime: modTime})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ModTime.Before(snapshots[j].ModTime) })
+	return snapshots, nil
+}
+
+func (t *S3Target) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	t.sign(req, nil)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 GET %s: unexpected status %s", key, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (t *S3Target) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	t.sign(req, nil)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 DELETE %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
+
+// sign attaches AWS SigV4 Authorization, x-amz-date and x-amz-content-sha256
+// headers to req for the "s3" service, following the canonical-request
+// algorithm described in AWS's SigV4 reference.
+func (t *S3Target) sign(req *http.Request, body []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := hashHex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+
+	headerNames := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(strings.TrimSpace(headerValue(req, name)))
+		canonicalHeaders.WriteByte('\n')
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, t.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	dateKey := hmacSHA256([]byte("AWS4"+t.secretKey), dateStamp)
+	regionKey := hmacSHA256(dateKey, t.region)
+	serviceKey := hmacSHA256(regionKey, "s3")
+	signingKey := hmacSHA256(serviceKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	authHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.accessKey, credentialScope, signedHeaders, signature)
+	req.Header.Set("Authorization", authHeader)
+}
+
+func headerValue(req *http.Request, name string) string {
+	if name == "host" {
+		return req.Host
+	}
+	return req.Header.Get(name)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,45 @@
+// Package backupstore defines the pluggable destinations a database backup
+// snapshot can be pushed to (local disk, S3-compatible object storage,
+// WebDAV), plus the retention policy used to prune old snapshots on each
+// target independently.
+package backupstore
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Snapshot describes one stored backup tarball, as reported by a Target's
+// List method.
+type Snapshot struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Target is a destination a backup snapshot tarball can be pushed to and
+// later listed/fetched/deleted from. Implementations are local disk,
+// S3-compatible object storage, and WebDAV; all three are exercised through
+// this interface so BackupService doesn't need to know which one it's
+// talking to.
+type Target interface {
+	// Name identifies the target in API responses and log output, e.g.
+	// "local", "s3:my-bucket", "webdav".
+	Name() string
+
+	// Put uploads a snapshot under key. size is the exact byte length of r,
+	// required up front by the S3 and WebDAV implementations.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// List returns every snapshot currently stored on the target, oldest
+	// first.
+	List(ctx context.Context) ([]Snapshot, error)
+
+	// Get opens a snapshot for reading. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes a snapshot. Deleting a key that doesn't exist is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+}
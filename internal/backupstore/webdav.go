@@ -0,0 +1,155 @@
+package backupstore
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebDAVTarget stores snapshots as files at baseURL+key on a WebDAV server
+// (e.g. Nextcloud, a NAS), using plain PUT/GET/DELETE and a PROPFIND for
+// listing rather than a dedicated client library.
+type WebDAVTarget struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAVTarget creates a WebDAVTarget rooted at baseURL (e.g.
+// "https://dav.example.com/tv-tracker-backups/"). username/password may be
+// empty if the server doesn't require basic auth.
+func NewWebDAVTarget(baseURL, username, password string) *WebDAVTarget {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &WebDAVTarget{
+		baseURL:  baseURL,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (t *WebDAVTarget) Name() string { return "webdav" }
+
+func (t *WebDAVTarget) do(req *http.Request) (*http.Response, error) {
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	return t.client.Do(req)
+}
+
+func (t *WebDAVTarget) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.baseURL+key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webdav PUT %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
+
+// davMultistatus and davResponse are the subset of a WebDAV PROPFIND
+// response body this target needs: the resource href, its size, and its
+// last-modified time.
+type davMultistatus struct {
+	Responses []davResponse `xml:"response"`
+}
+
+type davResponse struct {
+	Href string `xml:"href"`
+	Prop struct {
+		ContentLength string `xml:"prop>getcontentlength"`
+		LastModified  string `xml:"prop>getlastmodified"`
+	} `xml:"propstat"`
+}
+
+func (t *WebDAVTarget) List(ctx context.Context) ([]Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", t.baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+
+	resp, err := t.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("webdav PROPFIND: unexpected status %s", resp.Status)
+	}
+
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("failed to parse PROPFIND response: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, r := range ms.Responses {
+		key := strings.TrimSuffix(r.Href, "/")
+		key = key[strings.LastIndex(key, "/")+1:]
+		if key == "" {
+			continue // the collection itself
+		}
+
+		size, _ := strconv.ParseInt(r.Prop.ContentLength, 10, 64)
+		modTime, _ := time.Parse(time.RFC1123, r.Prop.LastModified)
+
+		snapshots = append(snapshots, Snapshot{Key: key, Size: size, ModTime: modTime})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ModTime.Before(snapshots[j].ModTime) })
+	return snapshots, nil
+}
+
+func (t *WebDAVTarget) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL+key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("webdav GET %s: unexpected status %s", key, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (t *WebDAVTarget) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.baseURL+key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("webdav DELETE %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
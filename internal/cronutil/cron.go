@@ -0,0 +1,166 @@
+// Package cronutil implements the minute/hour/day-of-month/month/day-of-week
+// subset of cron expression syntax used to schedule the TMDB refresh and
+// daily report jobs. It intentionally covers only the standard 5-field
+// syntax (*, lists, ranges, and */step) and not the extended forms (named
+// months/weekdays, @every, seconds) since nothing in this repo needs them.
+package cronutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed 5-field cron expression: minute hour dom month dow.
+// Each field is stored as a bitset of the values it matches.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domRestricted and dowRestricted track whether the dom/dow fields were
+	// anything other than "*", since cron treats "either field matches" as
+	// an OR when both are restricted, but falls back to a plain AND (i.e.
+	// the unrestricted field matches everything) otherwise.
+	domRestricted bool
+	dowRestricted bool
+}
+
+type fieldRange struct {
+	min, max int
+}
+
+var fieldRanges = [5]fieldRange{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week (0 = Sunday)
+}
+
+// Parse parses a standard 5-field cron expression ("minute hour dom month
+// dow"), e.g. "0 */6 * * *" for every six hours on the hour.
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{}
+	bits := [5]*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, field := range fields {
+		mask, err := parseField(field, fieldRanges[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron field %d (%q): %w", i, field, err)
+		}
+		*bits[i] = mask
+	}
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+
+	return s, nil
+}
+
+// parseField turns a single cron field (possibly a comma-separated list of
+// "*", "N", "N-M", or "*/step"/"N-M/step") into a bitset over [r.min, r.max].
+func parseField(field string, r fieldRange) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step, err := parsePart(part, r)
+		if err != nil {
+			return 0, err
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	if mask == 0 {
+		return 0, fmt.Errorf("no values matched")
+	}
+	return mask, nil
+}
+
+func parsePart(part string, r fieldRange) (lo, hi, step int, err error) {
+	step = 1
+	base := part
+	if idx := strings.IndexByte(part, '/'); idx >= 0 {
+		base = part[:idx]
+		step, err = strconv.Atoi(part[idx+1:])
+		if err != nil || step <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid step in %q", part)
+		}
+	}
+
+	switch {
+	case base == "*":
+		lo, hi = r.min, r.max
+	case strings.Contains(base, "-"):
+		bounds := strings.SplitN(base, "-", 2)
+		lo, err = strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid range start in %q", part)
+		}
+		hi, err = strconv.Atoi(bounds[1])
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid range end in %q", part)
+		}
+	default:
+		lo, err = strconv.Atoi(base)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid value %q", part)
+		}
+		hi = lo
+	}
+
+	if lo < r.min || hi > r.max || lo > hi {
+		return 0, 0, 0, fmt.Errorf("value %q out of range [%d, %d]", part, r.min, r.max)
+	}
+	return lo, hi, step, nil
+}
+
+// Next returns the next minute-aligned time strictly after from that
+// satisfies the schedule. It searches forward minute by minute, which is
+// simple and fast enough for jobs that fire no more than a few times an
+// hour; a search is capped at 4 years out, so a schedule that never matches
+// (e.g. "0 0 31 2 *") returns that 4-year deadline instead of looping
+// forever.
+func (s *Schedule) Next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	deadline := from.AddDate(4, 0, 0)
+
+	for t.Before(deadline) {
+		if s.matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return deadline
+}
+
+func (s *Schedule) matches(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	if s.hour&(1<<uint(t.Hour())) == 0 {
+		return false
+	}
+	if s.minute&(1<<uint(t.Minute())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domRestricted && s.dowRestricted:
+		return domMatch || dowMatch
+	case s.domRestricted:
+		return domMatch
+	case s.dowRestricted:
+		return dowMatch
+	default:
+		return true
+	}
+}
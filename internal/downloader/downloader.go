@@ -0,0 +1,18 @@
+// Package downloader defines the pluggable media-manager destinations a
+// subscribed show can be pushed to so its episodes start downloading
+// automatically, instead of the user finding a torrent by hand. Sonarr and
+// qBittorrent are the two implementations, mirroring how internal/backupstore
+// hides its S3/WebDAV/local targets behind one Target interface.
+package downloader
+
+// Client pushes a TMDB-identified show into a download manager's queue.
+type Client interface {
+	// Name identifies the downloader in bot responses and logs, e.g.
+	// "Sonarr" or "qBittorrent".
+	Name() string
+
+	// PushSeries adds tmdbID to the downloader's queue. title is passed
+	// alongside it for downloaders (qBittorrent) that have no TMDB lookup
+	// of their own and must search an indexer by name instead.
+	PushSeries(tmdbID int, title string) error
+}
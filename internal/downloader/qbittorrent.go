@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// QBitClient pushes a show to qBittorrent's queue by deriving a torrent URL
+// from a configurable indexer template and handing it to qBittorrent's
+// add-torrent API, since qBittorrent itself has no notion of TMDB IDs.
+type QBitClient struct {
+	baseURL string
+	user    string
+	pass    string
+	// indexerURLTemplate is an RSS/indexer search URL with "%s" in place of
+	// the URL-escaped show title, e.g.
+	// "https://example-indexer/rss?q=%s&cat=tv".
+	indexerURLTemplate string
+	httpClient         *http.Client
+}
+
+// NewQBitClient creates a QBitClient. indexerURLTemplate must contain
+// exactly one "%s", substituted with the URL-escaped show title to build the
+// torrent/magnet URL handed to qBittorrent's add-torrent endpoint.
+func NewQBitClient(baseURL, user, pass, indexerURLTemplate string) *QBitClient {
+	jar, _ := cookiejar.New(nil)
+	return &QBitClient{
+		baseURL:            strings.TrimSuffix(baseURL, "/"),
+		user:               user,
+		pass:               pass,
+		indexerURLTemplate: indexerURLTemplate,
+		httpClient:         &http.Client{Timeout: 30 * time.Second, Jar: jar},
+	}
+}
+
+func (q *QBitClient) Name() string { return "qBittorrent" }
+
+// PushSeries resolves title against q.indexerURLTemplate and hands the
+// resulting torrent/magnet URL to qBittorrent's add-torrent API. tmdbID is
+// unused - qBittorrent has no TMDB lookup of its own.
+func (q *QBitClient) PushSeries(tmdbID int, title string) error {
+	if q.indexerURLTemplate == "" {
+		return fmt.Errorf("qbittorrent: no indexer URL template configured")
+	}
+	if err := q.login(); err != nil {
+		return fmt.Errorf("qbittorrent login failed: %w", err)
+	}
+
+	torrentURL := fmt.Sprintf(q.indexerURLTemplate, url.QueryEscape(title))
+
+	form := url.Values{"urls": {torrentURL}}
+	resp, err := q.httpClient.PostForm(q.baseURL+"/api/v2/torrents/add", form)
+	if err != nil {
+		return fmt.Errorf("failed to add torrent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("qbittorrent rejected add request: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// login authenticates against qBittorrent's WebUI API, storing the session
+// cookie in q.httpClient's jar for the subsequent add-torrent call.
+func (q *QBitClient) login() error {
+	form := url.Values{"username": {q.user}, "password": {q.pass}}
+	resp, err := q.httpClient.PostForm(q.baseURL+"/api/v2/auth/login", form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
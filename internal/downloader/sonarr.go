@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SonarrClient adds a series to a Sonarr instance via its v3 API: looking it
+// up by TMDB ID, then posting the lookup result back with a quality profile
+// and root folder attached.
+type SonarrClient struct {
+	baseURL    string
+	apiKey     string
+	profileID  int
+	rootFolder string
+	httpClient *http.Client
+}
+
+// NewSonarrClient creates a SonarrClient. baseURL is Sonarr's own address
+// (e.g. "http://sonarr:8989"), not the TMDB API.
+func NewSonarrClient(baseURL, apiKey string, profileID int, rootFolder string) *SonarrClient {
+	return &SonarrClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		profileID:  profileID,
+		rootFolder: rootFolder,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *SonarrClient) Name() string { return "Sonarr" }
+
+// seriesLookupResult is the subset of Sonarr's /series/lookup response
+// PushSeries needs; the rest of the object is passed through unmodified on
+// the follow-up POST /series call, which is how Sonarr's own UI adds a show.
+type seriesLookupResult map[string]any
+
+// PushSeries looks up tmdbID via Sonarr's /series/lookup, then adds the
+// first match with s.profileID and s.rootFolder. title is unused - Sonarr's
+// lookup is keyed entirely on tmdbID.
+func (s *SonarrClient) PushSeries(tmdbID int, title string) error {
+	results, err := s.lookup(tmdbID)
+	if err != nil {
+		return fmt.Errorf("sonarr lookup failed: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("sonarr lookup for tmdb:%d returned no results", tmdbID)
+	}
+
+	series := results[0]
+	series["qualityProfileId"] = s.profileID
+	series["rootFolderPath"] = s.rootFolder
+	series["monitored"] = true
+	series["addOptions"] = map[string]any{"searchForMissingEpisodes": true}
+
+	body, err := json.Marshal(series)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sonarr add payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.baseURL+"/api/v3/series", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to add series to sonarr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sonarr rejected add request: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *SonarrClient) lookup(tmdbID int) ([]seriesLookupResult, error) {
+	endpoint := fmt.Sprintf("%s/api/v3/series/lookup?term=%s", s.baseURL, url.QueryEscape(fmt.Sprintf("tmdb:%d", tmdbID)))
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Api-Key", s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var results []seriesLookupResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode lookup response: %w", err)
+	}
+	return results, nil
+}
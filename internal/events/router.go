@@ -0,0 +1,134 @@
+// Package events defines Notification/Router, the generalized event
+// delivery types shared between internal/notify's Channel implementations
+// and the internal/service callers that fire events through them. It lives
+// as its own leaf package, with no dependency on either, so neither side of
+// that relationship has to import the other.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Kind identifies what kind of event a Notification describes, so a Router
+// can decide which channels it's delivered to independently of the daily
+// report's per-name channel selection.
+type Kind string
+
+const (
+	// KindDailyReport is the existing 08:00 digest, migrated to flow through
+	// Router alongside the event kinds below rather than only Registry.
+	KindDailyReport Kind = "daily_report"
+	// KindEpisodeAired mirrors models.SeverityNewEpisode for callers routing
+	// through Router instead of Registry's severity-keyed SendEventTo.
+	KindEpisodeAired Kind = "episode_aired"
+	// KindShowCompleted mirrors models.SeverityShowEnded for callers routing
+	// through Router instead of Registry's severity-keyed SendEventTo.
+	KindShowCompleted Kind = "show_completed"
+	// KindBackupFailed fires when BackupService.PushSnapshot fails to reach
+	// a configured target.
+	KindBackupFailed Kind = "backup_failed"
+	// KindTMDBRateLimited fires when TMDB starts responding 429/5xx, so an
+	// operator notices a degraded provider instead of only seeing it in
+	// metrics.TMDBRefreshErrorsTotal.
+	KindTMDBRateLimited Kind = "tmdb_rate_limited"
+)
+
+// Notification is a single event to deliver, generalized beyond the daily
+// report's []models.Task so non-report events (a backup failure, a TMDB
+// outage) can flow through the same channels. Data carries event-specific
+// detail a channel may want to include (e.g. the TMDB status code), without
+// every Channel implementation needing to know every Kind's fields.
+type Notification struct {
+	Kind  Kind
+	Title string
+	Body  string
+	Data  map[string]any
+}
+
+// Channel delivers a Notification. internal/notify.AsChannel adapts its
+// existing Notifier implementations (email/Telegram/webhook/etc.) to this
+// interface.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+type routedChannel struct {
+	channel Channel
+	kinds   map[Kind]bool
+}
+
+// Router fans out a Notification to every channel subscribed to its Kind,
+// the generalization of notify.Registry's severity-based SendEvent for
+// arbitrary event kinds instead of just new_episode/show_ended.
+type Router struct {
+	mu       sync.Mutex
+	channels map[string]*routedChannel
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{channels: make(map[string]*routedChannel)}
+}
+
+// Register subscribes ch to the given kinds. Calling Register again for the
+// same channel name replaces its subscription.
+func (r *Router) Register(ch Channel, kinds ...Kind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := make(map[Kind]bool, len(kinds))
+	for _, k := range kinds {
+		wanted[k] = true
+	}
+	r.channels[ch.Name()] = &routedChannel{channel: ch, kinds: wanted}
+}
+
+// Route delivers n to every channel subscribed to n.Kind, concurrently,
+// returning any per-channel errors keyed by channel name.
+func (r *Router) Route(ctx context.Context, n Notification) map[string]error {
+	return r.RouteTo(ctx, n, nil)
+}
+
+// RouteTo is Route narrowed to the given channel names, used to honor a
+// per-user/per-show channel override the same way Registry.SendEventTo does.
+// An empty only delivers to every channel subscribed to n.Kind.
+func (r *Router) RouteTo(ctx context.Context, n Notification, only []string) map[string]error {
+	wanted := make(map[string]bool, len(only))
+	for _, name := range only {
+		wanted[name] = true
+	}
+
+	r.mu.Lock()
+	var matching []*routedChannel
+	for name, rc := range r.channels {
+		if !rc.kinds[n.Kind] {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		matching = append(matching, rc)
+	}
+	r.mu.Unlock()
+
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, rc := range matching {
+		wg.Add(1)
+		go func(rc *routedChannel) {
+			defer wg.Done()
+			if err := rc.channel.Send(ctx, n); err != nil {
+				mu.Lock()
+				errs[rc.channel.Name()] = err
+				mu.Unlock()
+			}
+		}(rc)
+	}
+
+	wg.Wait()
+	return errs
+}
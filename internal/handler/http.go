@@ -3,107 +3,240 @@ package handler
 import (
 	"crypto/subtle"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
 
+	"tv-tracker/internal/auth"
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/events"
+	"tv-tracker/internal/jobs"
+	"tv-tracker/internal/metrics"
 	"tv-tracker/internal/models"
+	"tv-tracker/internal/notify"
+	"tv-tracker/internal/release"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
 	"tv-tracker/internal/timeutil"
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/webhook"
 )
 
 // HTTPHandler handles HTTP requests for the web interface
 type HTTPHandler struct {
-	tmdbClient  *tmdb.Client
-	subMgr      *service.SubscriptionManager
-	taskBoard   *service.TaskBoardService
-	episodeRepo *repository.EpisodeRepository
-	showRepo    *repository.TVShowRepository
-	backupSvc   *service.BackupService
-	apiToken    string
+	tmdbClient     *tmdb.Client
+	subMgr         *service.SubscriptionManager
+	taskBoard      *service.TaskBoardService
+	episodeRepo    *repository.EpisodeRepository
+	showRepo       *repository.TVShowRepository
+	taskRepo       *repository.TaskRepository
+	backupSvc      *service.BackupService
+	webhookMgr     *webhook.Manager
+	notifyRegistry *notify.Registry
+	notifyRouter   *events.Router
+	authMgr        *auth.Manager
+	apiToken       string
+	icsEventDays   int
+	syncScheduler  *service.SyncScheduler
+	metricsToken   string
+	scheduleRules  *repository.ScheduleRuleRepository
+	jobsClient     *jobs.Client
+	taskInspector  *asynq.Inspector
 }
 
-// NewHTTPHandler creates a new HTTPHandler
+// NewHTTPHandler creates a new HTTPHandler. icsEventDays is the VEVENT
+// duration used by the ICS calendar feed (see CalendarFeed); values <= 0
+// fall back to one day. syncScheduler may be nil, in which case the
+// /api/sync/* endpoints report that scheduled sync isn't configured.
+// metricsToken, if non-empty, gates GET /metrics the same way apiToken
+// gates the rest of the API; left empty, /metrics is unauthenticated,
+// matching how most Prometheus scrape setups expect it. scheduleRules backs
+// the /api/schedule-rules CRUD endpoints only; the TaskScheduler that
+// actually runs them is started separately and picks up rule changes on its
+// next restart.
 func NewHTTPHandler(
 	tmdbClient *tmdb.Client,
 	subMgr *service.SubscriptionManager,
 	taskBoard *service.TaskBoardService,
 	episodeRepo *repository.EpisodeRepository,
 	showRepo *repository.TVShowRepository,
+	taskRepo *repository.TaskRepository,
 	backupSvc *service.BackupService,
+	webhookMgr *webhook.Manager,
+	notifyRegistry *notify.Registry,
+	authMgr *auth.Manager,
 	apiToken string,
+	icsEventDays int,
+	syncScheduler *service.SyncScheduler,
+	metricsToken string,
+	scheduleRules *repository.ScheduleRuleRepository,
 ) *HTTPHandler {
 	return &HTTPHandler{
-		tmdbClient:  tmdbClient,
-		subMgr:      subMgr,
-		taskBoard:   taskBoard,
-		episodeRepo: episodeRepo,
-		showRepo:    showRepo,
-		backupSvc:   backupSvc,
-		apiToken:    strings.TrimSpace(apiToken),
+		tmdbClient:     tmdbClient,
+		subMgr:         subMgr,
+		taskBoard:      taskBoard,
+		episodeRepo:    episodeRepo,
+		showRepo:       showRepo,
+		taskRepo:       taskRepo,
+		backupSvc:      backupSvc,
+		webhookMgr:     webhookMgr,
+		notifyRegistry: notifyRegistry,
+		authMgr:        authMgr,
+		apiToken:       strings.TrimSpace(apiToken),
+		icsEventDays:   icsEventDays,
+		syncScheduler:  syncScheduler,
+		metricsToken:   strings.TrimSpace(metricsToken),
+		scheduleRules:  scheduleRules,
 	}
 }
 
 // RegisterRoutes registers all HTTP routes
 func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
+	r.Use(metrics.GinMiddleware())
+
 	// Serve simple web UI
 	r.GET("/", func(c *gin.Context) {
 		c.File("./web/simple/index.html")
 	})
 
 	api := r.Group("/api")
-	api.Use(h.authMiddleware)
 
 	// Health check must allow unauthenticated ping for probes
 	r.GET("/api/health", h.Health)
 
+	// Prometheus scrape target; gated by metricsToken only if one's set.
+	r.GET("/metrics", h.Metrics)
+
+	// Calendar clients (Google/Apple/Outlook) can't send an Authorization
+	// header when subscribing to a feed URL, so this route checks ?token=
+	// itself instead of going through authMiddleware.
+	api.GET("/calendar.ics", h.CalendarFeed)
+
+	// Key management mints the per-key credentials used below, so it stays
+	// behind the single bootstrap admin token rather than a scope of its own.
+	keys := api.Group("/keys")
+	keys.Use(h.authMiddleware)
+	keys.POST("", h.CreateAPIKey)
+	keys.GET("", h.ListAPIKeys)
+	keys.DELETE("/:id", h.RevokeAPIKey)
+
 	// Dashboard
-	api.GET("/dashboard", h.GetDashboard)
+	api.GET("/dashboard", h.authMgr.RequireScope(models.ScopeLibraryRead), h.GetDashboard)
 
 	// Today's episodes
-	api.GET("/today", h.GetTodayEpisodes)
+	api.GET("/today", h.authMiddleware, h.GetTodayEpisodes)
 
 	// Week calendar
-	api.GET("/week", h.GetWeekEpisodes)
+	api.GET("/week", h.authMiddleware, h.GetWeekEpisodes)
 
 	// Search
-	api.GET("/search", h.SearchTV)
+	api.GET("/search", h.authMgr.RequireScope(models.ScopeSearchRead), h.SearchTV)
 
 	// Subscription
-	api.POST("/subscribe", h.Subscribe)
-	api.DELETE("/subscribe/:id", h.Unsubscribe)
-	api.GET("/library", h.GetLibrary)
+	api.POST("/subscribe", h.authMgr.RequireScope(models.ScopeLibraryWrite), h.Subscribe)
+	api.DELETE("/subscribe/:id", h.authMgr.RequireScope(models.ScopeLibraryWrite), h.Unsubscribe)
+	api.GET("/library", h.authMgr.RequireScope(models.ScopeLibraryRead), h.GetLibrary)
+	api.GET("/library/search", h.authMgr.RequireScope(models.ScopeLibraryRead), h.SearchLibrary)
+	api.GET("/library/queue", h.authMgr.RequireScope(models.ScopeLibraryRead), h.GetQueue)
 
 	// Tasks
-	api.POST("/tasks/:id/complete", h.CompleteTask)
-	api.POST("/tasks/:id/postpone", h.PostponeTask)
+	api.POST("/tasks/:id/complete", h.authMgr.RequireScope(models.ScopeTasksWrite), h.CompleteTask)
+	api.POST("/tasks/:id/postpone", h.authMiddleware, h.PostponeTask)
+	api.POST("/tasks/:id/snooze", h.authMiddleware, h.SnoozeTask)
+	api.PUT("/tasks/:id/priority", h.authMiddleware, h.SetTaskPriority)
+	api.PUT("/tasks/:id/due-date", h.authMiddleware, h.SetTaskDueDate)
+	api.POST("/tasks/complete-many", h.authMiddleware, h.CompleteManyTasks)
+	api.POST("/tasks/postpone-many", h.authMiddleware, h.PostponeManyTasks)
+	api.POST("/tasks/undo", h.authMiddleware, h.UndoTaskAction)
+	api.GET("/tasks/export", h.authMiddleware, h.ExportTasks)
+	api.POST("/tasks/import", h.authMiddleware, h.ImportTasks)
+	api.POST("/shows/:id/resource-candidate", h.authMiddleware, h.AttachResourceCandidate)
 
 	// Resource time
-	api.PUT("/shows/:id/resource-time", h.UpdateResourceTime)
+	api.PUT("/shows/:id/resource-time", h.authMiddleware, h.UpdateResourceTime)
+	api.PUT("/shows/:id/preferences", h.authMiddleware, h.UpdateShowPreferences)
+
+	// Specials (season 0)
+	api.POST("/shows/:id/skip-specials", h.authMiddleware, h.SkipSpecials)
+	api.POST("/shows/:id/unskip-specials", h.authMiddleware, h.UnskipSpecials)
+
+	// Bulk watch marking
+	api.POST("/shows/:id/watch-season", h.authMgr.RequireScope(models.ScopeLibraryWrite), h.MarkSeasonWatched)
+	api.POST("/shows/:id/watch-range", h.authMgr.RequireScope(models.ScopeLibraryWrite), h.MarkRangeWatched)
 
 	// Backups
-	api.POST("/backup", func(c *gin.Context) {
-		backupPath, err := h.backupSvc.Backup()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"backup_path": backupPath})
-	})
+	api.POST("/backup", h.authMiddleware, h.Backup)
+	api.POST("/backup/vacuum", h.authMiddleware, h.BackupVacuum)
+	api.POST("/backup/restore", h.authMiddleware, h.RestoreSnapshot)
+	api.GET("/backups", h.authMiddleware, h.ListSnapshots)
+	api.POST("/restore", h.authMiddleware, h.Restore)
+
+	// Webhook subscriptions
+	api.POST("/webhooks", h.authMiddleware, h.CreateWebhook)
+	api.GET("/webhooks", h.authMiddleware, h.ListWebhooks)
+	api.DELETE("/webhooks/:id", h.authMiddleware, h.DeleteWebhook)
 
+	// Notification channels
+	api.GET("/notifiers", h.authMiddleware, h.ListNotifiers)
+	api.POST("/notifiers/:name/test", h.authMiddleware, h.TestNotifier)
+	api.POST("/report", h.authMgr.RequireScope(models.ScopeReportSend), h.SendReport)
+
+	// Scheduled TMDB refresh + sync
+	api.GET("/sync/status", h.authMiddleware, h.SyncStatus)
+	api.POST("/sync/run", h.authMiddleware, h.RunSync)
+
+	api.POST("/schedule-rules", h.authMiddleware, h.CreateScheduleRule)
+	api.GET("/schedule-rules", h.authMiddleware, h.ListScheduleRules)
+	api.PUT("/schedule-rules/:id/enabled", h.authMiddleware, h.SetScheduleRuleEnabled)
+	api.DELETE("/schedule-rules/:id", h.authMiddleware, h.DeleteScheduleRule)
+
+	admin := r.Group("/admin")
+	admin.Use(h.authMiddleware)
+	{
+		admin.GET("/jobs", h.GetAdminJobs)
+		admin.GET("/tasks/dead", h.GetAdminDeadTasks)
+		admin.GET("/cache", h.GetAdminCache)
+		admin.DELETE("/cache/:key", h.DeleteAdminCacheKey)
+	}
 }
 
-// GetDashboard returns the dashboard data
+// GetDashboard returns one page of pending tasks, filtered and sorted per
+// the query params below. Supported query params:
+//
+//	cursor      opaque token from a previous page's next_cursor
+//	limit       page size, 1-200 (default 50)
+//	sort        name | added (default added)
+//	task_type   UPDATE | ORGANIZE | RESOURCE_FOUND
+//	q           case-insensitive substring match against description
 func (h *HTTPHandler) GetDashboard(c *gin.Context) {
-	data, err := h.taskBoard.GetDashboardData()
+	limit, err := h.getLimitParam(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incomplete := false
+	filter := repository.TaskFilter{
+		TaskType:  models.TaskType(c.Query("task_type")),
+		Completed: &incomplete,
+		Query:     c.Query("q"),
+	}
+
+	tasks, nextCursor, hasMore, err := h.taskRepo.ListPaged(filter, c.Query("sort"), c.Query("cursor"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": tasks, "next_cursor": nextCursor, "has_more": hasMore})
 }
 
 // GetTodayEpisodes returns today's episodes
@@ -171,6 +304,90 @@ func (h *HTTPHandler) GetWeekEpisodes(c *gin.Context) {
 	})
 }
 
+// icsHorizonDays bounds how far out CalendarFeed looks for upcoming
+// episodes; a show airing further out than this just won't have a VEVENT
+// yet, which is fine since the feed is refetched by calendar clients.
+const icsHorizonDays = 730
+
+// CalendarFeed streams an RFC 5545 ICS feed of every future episode across
+// subscribed shows, for Google/Apple/Outlook-style calendar subscriptions.
+// Those clients fetch the URL directly and can't send an Authorization
+// header, so the API token is accepted as ?token= here instead of going
+// through authMiddleware, still compared in constant time.
+func (h *HTTPHandler) CalendarFeed(c *gin.Context) {
+	expected := strings.TrimSpace(h.getAPIToken())
+	if expected == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "WEB_API_TOKEN not set"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(expected)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	eventDays := h.icsEventDays
+	if eventDays <= 0 {
+		eventDays = 1
+	}
+
+	today := timeutil.Now().Format("2006-01-02")
+	episodes, err := h.episodeRepo.GetUpcomingWithShowInfo(today, icsHorizonDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VCALENDAR\r\n")
+	sb.WriteString("VERSION:2.0\r\n")
+	sb.WriteString("PRODID:-//tv-tracker//calendar feed//EN\r\n")
+	sb.WriteString("CALSCALE:GREGORIAN\r\n")
+
+	now := timeutil.Now().UTC().Format("20060102T150405Z")
+	for _, ep := range episodes {
+		if ep.Episode.AirDate == "" {
+			continue
+		}
+		airDate, err := time.Parse("2006-01-02", ep.Episode.AirDate)
+		if err != nil {
+			continue
+		}
+
+		episodeID := fmt.Sprintf("S%02dE%02d", ep.Episode.Season, ep.Episode.Episode)
+		summary := ep.ShowName + " " + episodeID
+		if ep.Episode.Title != "" {
+			summary += " - " + ep.Episode.Title
+		}
+
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		sb.WriteString(fmt.Sprintf("UID:tvtracker-%d-%s@%s\r\n", ep.TMDBID, episodeID, c.Request.Host))
+		sb.WriteString(fmt.Sprintf("DTSTAMP:%s\r\n", now))
+		sb.WriteString(fmt.Sprintf("DTSTART;VALUE=DATE:%s\r\n", airDate.Format("20060102")))
+		sb.WriteString(fmt.Sprintf("DTEND;VALUE=DATE:%s\r\n", airDate.AddDate(0, 0, eventDays).Format("20060102")))
+		sb.WriteString(fmt.Sprintf("SUMMARY:%s\r\n", escapeICSText(summary)))
+		if ep.Episode.Overview != "" {
+			sb.WriteString(fmt.Sprintf("DESCRIPTION:%s\r\n", escapeICSText(ep.Episode.Overview)))
+		}
+		sb.WriteString("END:VEVENT\r\n")
+	}
+
+	sb.WriteString("END:VCALENDAR\r\n")
+
+	c.Header("Content-Disposition", `attachment; filename="tv-tracker.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(sb.String()))
+}
+
+// escapeICSText escapes the characters RFC 5545 reserves in a TEXT value.
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
 // SearchTV searches for TV shows
 func (h *HTTPHandler) SearchTV(c *gin.Context) {
 	query := c.Query("q")
@@ -228,18 +445,85 @@ func (h *HTTPHandler) Unsubscribe(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "unsubscribed"})
 }
 
-// GetLibrary returns all subscribed shows
+// GetLibrary returns one page of subscribed shows, filtered and sorted per
+// the query params below. Supported query params:
+//
+//	cursor          opaque token from a previous page's next_cursor
+//	limit           page size, 1-200 (default 50)
+//	sort            name | added | next_air_date (default added)
+//	status          exact TVShow.Status match
+//	origin_country  exact TVShow.OriginCountry match
+//	archived        true | false
+//	q               case-insensitive substring match against name
 func (h *HTTPHandler) GetLibrary(c *gin.Context) {
-	shows, err := h.subMgr.GetAllSubscriptions()
+	limit, err := h.getLimitParam(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repository.ShowFilter{
+		Status:        c.Query("status"),
+		OriginCountry: c.Query("origin_country"),
+		Query:         c.Query("q"),
+	}
+	if raw := c.Query("archived"); raw != "" {
+		archived, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid archived value"})
+			return
+		}
+		filter.Archived = &archived
+	}
+
+	shows, nextCursor, hasMore, err := h.showRepo.ListPaged(filter, c.Query("sort"), c.Query("cursor"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	if shows == nil {
 		shows = []models.TVShow{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"shows": shows})
+	c.JSON(http.StatusOK, gin.H{"items": shows, "next_cursor": nextCursor, "has_more": hasMore})
+}
+
+// GetQueue returns every subscribed show with its watch progress, sorted by
+// the air date of its earliest unwatched episode, for an "up next" view.
+func (h *HTTPHandler) GetQueue(c *gin.Context) {
+	shows, err := h.showRepo.GetActiveQueue()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if shows == nil {
+		shows = []repository.TVShowWithProgress{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": shows})
+}
+
+// SearchLibrary searches subscribed shows via the shows_fts virtual table.
+// Unlike SearchTV, which queries TMDB, this only searches what the user has
+// already subscribed to. ?lang= hints which tokenizer to search with (see
+// TVShowRepository.SearchShows).
+func (h *HTTPHandler) SearchLibrary(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	hits, err := h.showRepo.SearchShows(query, c.Query("lang"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hits == nil {
+		hits = []repository.ShowSearchHit{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": hits})
 }
 
 // CompleteTask marks a task as completed
@@ -274,87 +558,1024 @@ func (h *HTTPHandler) PostponeTask(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "task postponed to tomorrow"})
 }
 
-// UpdateResourceTime updates the resource time for a TV show
-func (h *HTTPHandler) UpdateResourceTime(c *gin.Context) {
-	id := h.getIntParam(c, "id")
-	if id == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+// SnoozeTask postpones a task by an arbitrary number of days, generalizing
+// PostponeTask's hardcoded "tomorrow".
+func (h *HTTPHandler) SnoozeTask(c *gin.Context) {
+	taskID := h.getIntParam(c, "id")
+	if taskID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
 		return
 	}
 
 	var req struct {
-		ResourceTime string `json:"resource_time" binding:"required"`
+		Days int `json:"days" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	show, err := h.showRepo.GetByID(id)
+	if err := h.taskBoard.Snooze(taskID, req.Days); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("task snoozed %d day(s)", req.Days)})
+}
+
+// CompleteManyTasks completes multiple tasks in one transaction. The
+// response's action_id can be passed to UndoTaskAction to reverse it.
+func (h *HTTPHandler) CompleteManyTasks(c *gin.Context) {
+	var req struct {
+		TaskIDs []int64 `json:"task_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actionID, results, err := h.taskBoard.CompleteMany(req.TaskIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if show == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "show not found"})
+
+	c.JSON(http.StatusOK, gin.H{"action_id": actionID, "results": results})
+}
+
+// PostponeManyTasks snoozes multiple tasks by the same number of days in
+// one transaction. The response's action_id can be passed to
+// UndoTaskAction to reverse it.
+func (h *HTTPHandler) PostponeManyTasks(c *gin.Context) {
+	var req struct {
+		TaskIDs []int64 `json:"task_ids" binding:"required"`
+		Days    int     `json:"days" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	show.ResourceTime = req.ResourceTime
-	if err := h.showRepo.Update(show); err != nil {
+	actionID, results, err := h.taskBoard.PostponeMany(req.TaskIDs, req.Days)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"show": show})
+	c.JSON(http.StatusOK, gin.H{"action_id": actionID, "results": results})
 }
 
-// Health returns health status
-func (h *HTTPHandler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+// UndoTaskAction reverses a prior CompleteManyTasks or PostponeManyTasks
+// call, identified by the action_id it returned.
+func (h *HTTPHandler) UndoTaskAction(c *gin.Context) {
+	var req struct {
+		ActionID string `json:"action_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.taskBoard.UndoLastAction(req.ActionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "action undone"})
 }
 
-// authMiddleware enforces Bearer token authentication against the configured API token.
-func (h *HTTPHandler) authMiddleware(c *gin.Context) {
-	expected := strings.TrimSpace(h.getAPIToken())
-	if expected == "" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "WEB_API_TOKEN not set"})
-		c.Abort()
+// ExportTasks downloads every pending task as a flat file, ?format=json
+// (default) or ?format=text, so a user's task list can be backed up or
+// version-controlled outside the SQLite file.
+func (h *HTTPHandler) ExportTasks(c *gin.Context) {
+	format := h.getParam(c, "format", "json")
+
+	var contentType, filename string
+	switch format {
+	case "json":
+		contentType, filename = "application/json", "tasks.json"
+	case "text":
+		contentType, filename = "text/plain; charset=utf-8", "tasks.txt"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be \"json\" or \"text\""})
 		return
 	}
 
-	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
-	parts := strings.SplitN(authHeader, " ", 2)
-	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid Authorization header"})
-		c.Abort()
+	var buf strings.Builder
+	if err := h.taskBoard.Export(&buf, format); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if subtle.ConstantTimeCompare([]byte(parts[1]), []byte(expected)) != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-		c.Abort()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename=%q`, filename))
+	c.Data(http.StatusOK, contentType, []byte(buf.String()))
+}
+
+// ImportTasks creates pending tasks from a previously exported file,
+// ?format=json (default) or ?format=text, uploaded as the raw request body.
+// A line whose show can't be resolved is reported in the response's
+// results rather than failing the whole import.
+func (h *HTTPHandler) ImportTasks(c *gin.Context) {
+	format := h.getParam(c, "format", "json")
+	if format != "json" && format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be \"json\" or \"text\""})
 		return
 	}
 
-	c.Next()
+	results, err := h.taskBoard.Import(c.Request.Body, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
 }
 
-// Helper functions
+// SetTaskPriority sets a task's dashboard sort priority.
+func (h *HTTPHandler) SetTaskPriority(c *gin.Context) {
+	taskID := h.getIntParam(c, "id")
+	if taskID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
 
-func (h *HTTPHandler) getParam(c *gin.Context, key, defaultValue string) string {
-	value := c.Param(key)
-	if value == "" {
-		value = c.Query(key)
+	var req struct {
+		Priority int `json:"priority" binding:"required"`
 	}
-	if value == "" {
-		return defaultValue
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	return value
+
+	if err := h.taskBoard.SetPriority(taskID, req.Priority); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "priority updated"})
 }
 
-func (h *HTTPHandler) getAPIToken() string {
-	return h.apiToken
+// SetTaskDueDate sets or clears (an empty due_date) a task's due date.
+func (h *HTTPHandler) SetTaskDueDate(c *gin.Context) {
+	taskID := h.getIntParam(c, "id")
+	if taskID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	var req struct {
+		DueDate string `json:"due_date"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var due *time.Time
+	if req.DueDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "due_date must be in YYYY-MM-DD format"})
+			return
+		}
+		due = &parsed
+	}
+
+	if err := h.taskBoard.SetDueDate(taskID, due); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "due date updated"})
+}
+
+// AttachResourceCandidate parses a pasted filename into quality metadata and
+// records it as a RESOURCE_FOUND task for the show.
+func (h *HTTPHandler) AttachResourceCandidate(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		Filename string `json:"filename" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	info, err := h.taskBoard.AttachResourceCandidate(id, req.Filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"release": info})
+}
+
+// UpdateResourceTime updates the resource time for a TV show
+func (h *HTTPHandler) UpdateResourceTime(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		ResourceTime string `json:"resource_time" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	show, err := h.subMgr.SetResourceTime(id, req.ResourceTime, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if show == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "show not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"show": show})
+}
+
+// UpdateShowPreferences sets a show's preferred quality/codec/release group
+// and whether cam/telesync rips should be flagged in generated task
+// descriptions. Preference strings are validated with release.ValidatePreference
+// so a show can't be configured to prefer the exact kind of rip RejectCam is
+// meant to filter out.
+func (h *HTTPHandler) UpdateShowPreferences(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		PreferredQuality string `json:"preferred_quality"`
+		PreferredCodec   string `json:"preferred_codec"`
+		PreferredGroup   string `json:"preferred_group"`
+		RejectCam        bool   `json:"reject_cam"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, v := range []string{req.PreferredQuality, req.PreferredCodec, req.PreferredGroup} {
+		if err := release.ValidatePreference(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	show, err := h.showRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if show == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "show not found"})
+		return
+	}
+
+	show.PreferredQuality = req.PreferredQuality
+	show.PreferredCodec = req.PreferredCodec
+	show.PreferredGroup = req.PreferredGroup
+	show.RejectCam = req.RejectCam
+	if err := h.showRepo.Update(show); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"show": show})
+}
+
+// SkipSpecials marks a show's specials (or any episode codes) as ignored, so
+// TaskGenerator stops creating UPDATE tasks for them.
+func (h *HTTPHandler) SkipSpecials(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		Codes []string `json:"codes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skipped, err := h.subMgr.SkipSpecials(id, req.Codes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skipped": skipped})
+}
+
+// UnskipSpecials removes the skipped mark from a show's episode codes.
+func (h *HTTPHandler) UnskipSpecials(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		Codes []string `json:"codes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	show, err := h.showRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if show == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "show not found"})
+		return
+	}
+
+	if err := h.episodeRepo.Unskip(show.TMDBID, req.Codes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unskipped": req.Codes})
+}
+
+// MarkSeasonWatched marks every known episode of a season watched, for
+// users binge-catching-up who don't want to click every episode.
+func (h *HTTPHandler) MarkSeasonWatched(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		Season int `json:"season" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	marked, err := h.subMgr.MarkSeasonWatched(id, req.Season)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// MarkRangeWatched marks every known episode between two "SxxExx" codes
+// (inclusive) watched.
+func (h *HTTPHandler) MarkRangeWatched(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid show id"})
+		return
+	}
+
+	var req struct {
+		From string `json:"from" binding:"required"`
+		To   string `json:"to" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	marked, err := h.subMgr.MarkRangeWatched(id, req.From, req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// Backup creates an online backup of the database.
+func (h *HTTPHandler) Backup(c *gin.Context) {
+	backupPath, err := h.backupSvc.Backup()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"backup_path": backupPath})
+}
+
+// BackupVacuum creates a compacted backup via VACUUM INTO.
+func (h *HTTPHandler) BackupVacuum(c *gin.Context) {
+	backupPath, err := h.backupSvc.VacuumInto()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"backup_path": backupPath})
+}
+
+// Restore restores the database from a previously created backup file,
+// verifying its integrity first.
+func (h *HTTPHandler) Restore(c *gin.Context) {
+	var req struct {
+		Path       string `json:"path" binding:"required"`
+		Compressed bool   `json:"compressed"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var err error
+	if req.Compressed {
+		err = h.backupSvc.RestoreFromCompressed(req.Path)
+	} else {
+		err = h.backupSvc.Restore(req.Path)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "database restored"})
+}
+
+// ListSnapshots lists the snapshots available on every target registered
+// with BackupService (see AddTarget), keyed by target name.
+func (h *HTTPHandler) ListSnapshots(c *gin.Context) {
+	snapshots, err := h.backupSvc.ListSnapshots(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"snapshots": snapshots})
+}
+
+// RestoreSnapshot restores the database from a snapshot tarball, either
+// uploaded directly as multipart/form-data (field "file") or pulled from a
+// registered target by name and key (JSON body {"target", "key"}).
+func (h *HTTPHandler) RestoreSnapshot(c *gin.Context) {
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+
+		if err := h.backupSvc.RestoreUploadedSnapshot(f); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "database restored from uploaded snapshot"})
+		return
+	}
+
+	var req struct {
+		Target string `json:"target" binding:"required"`
+		Key    string `json:"key" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected a multipart file upload or a JSON body with target and key"})
+		return
+	}
+
+	if err := h.backupSvc.RestoreSnapshot(c.Request.Context(), req.Target, req.Key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "database restored from snapshot"})
+}
+
+// CreateWebhook registers a webhook subscription. The callback must echo
+// back the WebSub verification challenge before the subscription is persisted.
+func (h *HTTPHandler) CreateWebhook(c *gin.Context) {
+	var req struct {
+		CallbackURL  string `json:"callback_url" binding:"required"`
+		Topic        string `json:"topic" binding:"required"`
+		Secret       string `json:"secret"`
+		LeaseSeconds int    `json:"lease_seconds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.LeaseSeconds <= 0 {
+		req.LeaseSeconds = 86400
+	}
+
+	sub, err := h.webhookMgr.Subscribe(req.CallbackURL, req.Topic, req.Secret, req.LeaseSeconds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub})
+}
+
+// ListWebhooks returns every registered webhook subscription.
+func (h *HTTPHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.webhookMgr.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+// DeleteWebhook removes a webhook subscription.
+func (h *HTTPHandler) DeleteWebhook(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		return
+	}
+
+	if err := h.webhookMgr.Unsubscribe(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "subscription removed"})
+}
+
+// CreateScheduleRule registers a new recurring task-generation rule.
+func (h *HTTPHandler) CreateScheduleRule(c *gin.Context) {
+	var req struct {
+		Name                string          `json:"name" binding:"required"`
+		CronExpr            string          `json:"cron_expr" binding:"required"`
+		TaskType            models.TaskType `json:"task_type" binding:"required"`
+		Selector            string          `json:"selector" binding:"required"`
+		ThresholdDays       int             `json:"threshold_days"`
+		DescriptionTemplate string          `json:"description_template" binding:"required"`
+		Enabled             bool            `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := cronutil.Parse(req.CronExpr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &repository.ScheduleRule{
+		Name:                req.Name,
+		CronExpr:            req.CronExpr,
+		TaskType:            req.TaskType,
+		Selector:            req.Selector,
+		ThresholdDays:       req.ThresholdDays,
+		DescriptionTemplate: req.DescriptionTemplate,
+		Enabled:             req.Enabled,
+	}
+	if err := h.scheduleRules.Create(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// ListScheduleRules returns every schedule rule, enabled or not. Rules take
+// effect on their next TaskScheduler restart; this endpoint only manages the
+// rules table, it does not reach into a running TaskScheduler.
+func (h *HTTPHandler) ListScheduleRules(c *gin.Context) {
+	rules, err := h.scheduleRules.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rules == nil {
+		rules = []repository.ScheduleRule{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// SetScheduleRuleEnabled enables or disables a schedule rule.
+func (h *HTTPHandler) SetScheduleRuleEnabled(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.scheduleRules.SetEnabled(id, req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule updated"})
+}
+
+// DeleteScheduleRule removes a schedule rule.
+func (h *HTTPHandler) DeleteScheduleRule(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.scheduleRules.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule removed"})
+}
+
+// apiKeyScopeNames maps the wire representation of a scope (as used in
+// CreateAPIKey's request body) to its bitmask value.
+var apiKeyScopeNames = map[string]models.APIKeyScope{
+	"search:read":   models.ScopeSearchRead,
+	"library:read":  models.ScopeLibraryRead,
+	"library:write": models.ScopeLibraryWrite,
+	"tasks:write":   models.ScopeTasksWrite,
+	"report:send":   models.ScopeReportSend,
+}
+
+// CreateAPIKey mints a new API key and returns its one-time bearer token.
+// The plaintext token is never stored and cannot be recovered later, so
+// callers must save it from this response.
+func (h *HTTPHandler) CreateAPIKey(c *gin.Context) {
+	var req struct {
+		OwnerLabel    string   `json:"owner_label" binding:"required"`
+		Scopes        []string `json:"scopes" binding:"required"`
+		ExpiresInDays int      `json:"expires_in_days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var scopes models.APIKeyScope
+	for _, name := range req.Scopes {
+		scope, ok := apiKeyScopeNames[name]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown scope %q", name)})
+			return
+		}
+		scopes |= scope
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := timeutil.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	key, token, err := h.authMgr.IssueKey(req.OwnerLabel, scopes, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"key": key, "token": token})
+}
+
+// ListAPIKeys returns every issued API key, including revoked and expired
+// ones. Secret material is never included (see models.APIKey's json tags).
+func (h *HTTPHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.authMgr.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// RevokeAPIKey revokes an API key so it fails authentication from now on.
+func (h *HTTPHandler) RevokeAPIKey(c *gin.Context) {
+	id := h.getIntParam(c, "id")
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key id"})
+		return
+	}
+
+	if err := h.authMgr.Revoke(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "key revoked"})
+}
+
+// SetNotifyRouter wires h to an events.Router, switching SendReport to build
+// an events.Notification and dispatch it through the router instead of
+// calling notifyRegistry directly. Left unset, SendReport falls back to
+// notifyRegistry as before.
+func (h *HTTPHandler) SetNotifyRouter(router *events.Router) {
+	h.notifyRouter = router
+}
+
+// SetJobsClient wires h to the background job queue, enabling the
+// /admin/jobs endpoint. Left unset, the endpoint reports 503.
+func (h *HTTPHandler) SetJobsClient(c *jobs.Client) {
+	h.jobsClient = c
+}
+
+// SetTaskInspector wires h to the asynq task queue, enabling the
+// /admin/tasks/dead endpoint. Left unset, the endpoint reports 503, the
+// same way GetAdminJobs does without a jobs client.
+func (h *HTTPHandler) SetTaskInspector(i *asynq.Inspector) {
+	h.taskInspector = i
+}
+
+// ListNotifiers returns the health and last-delivery status of every
+// registered notification channel.
+func (h *HTTPHandler) ListNotifiers(c *gin.Context) {
+	statuses := h.notifyRegistry.List()
+	if statuses == nil {
+		statuses = []notify.ChannelStatus{}
+	}
+	c.JSON(http.StatusOK, gin.H{"notifiers": statuses})
+}
+
+// TestNotifier sends a synthetic report to a single named channel.
+func (h *HTTPHandler) TestNotifier(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.notifyRegistry.Test(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "test notification sent"})
+}
+
+// SendReport delivers today's pending UPDATE tasks to the requested
+// notification channels, or every registered channel if none are given.
+func (h *HTTPHandler) SendReport(c *gin.Context) {
+	var req struct {
+		Channels []string `json:"channels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := h.taskBoard.GetDashboardData()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var errs map[string]error
+	if h.notifyRouter != nil {
+		notification := events.Notification{
+			Kind:  events.KindDailyReport,
+			Title: "TV Tracker 今日更新日报",
+			Body:  notify.FormatDailyReport(data.UpdateTasks),
+			Data:  map[string]any{"update_tasks": data.UpdateTasks},
+		}
+		errs = h.notifyRouter.RouteTo(c.Request.Context(), notification, req.Channels)
+	} else {
+		errs = h.notifyRegistry.SendDailyReport(data.UpdateTasks, req.Channels)
+	}
+
+	failures := make(map[string]string, len(errs))
+	for name, err := range errs {
+		failures[name] = err.Error()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sent": len(data.UpdateTasks), "failures": failures})
+}
+
+// SyncStatus reports the last scheduled TMDB refresh + sync run and when
+// the next one is due.
+func (h *HTTPHandler) SyncStatus(c *gin.Context) {
+	if h.syncScheduler == nil {
+		c.JSON(http.StatusOK, gin.H{"configured": false})
+		return
+	}
+
+	status, err := h.syncScheduler.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"configured":      true,
+		"running":         status.Running,
+		"next_run":        status.NextRun.Format(time.RFC3339),
+		"last_run":        status.LastRun,
+		"shows_refreshed": status.ShowsRefreshed,
+		"errors":          status.Errors,
+	})
+}
+
+// RunSync triggers an immediate TMDB refresh + sync cycle, outside the
+// regular schedule.
+func (h *HTTPHandler) RunSync(c *gin.Context) {
+	if h.syncScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduled sync is not configured"})
+		return
+	}
+
+	run, err := h.syncScheduler.RunNow("manual")
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+// GetAdminJobs lists background jobs by state, defaulting to the dead
+// letter queue so stuck jobs are visible without needing a direct DB query.
+// GET /admin/jobs?state=dead&limit=50
+func (h *HTTPHandler) GetAdminJobs(c *gin.Context) {
+	if h.jobsClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Job queue not configured",
+		})
+		return
+	}
+
+	state := jobs.State(c.DefaultQuery("state", string(jobs.StateDead)))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	results, err := h.jobsClient.ListByState(state, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list jobs: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": results,
+	})
+}
+
+// GetAdminDeadTasks lists asynq tasks that exhausted their retries on the
+// given queue, the asynq counterpart to GetAdminJobs' dead letter view.
+// GET /admin/tasks/dead?queue=default
+func (h *HTTPHandler) GetAdminDeadTasks(c *gin.Context) {
+	if h.taskInspector == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Task queue not configured",
+		})
+		return
+	}
+
+	queue := c.DefaultQuery("queue", "default")
+
+	results, err := h.taskInspector.ListArchivedTasks(queue)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list dead tasks: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tasks": results,
+	})
+}
+
+// GetAdminCache lists every key in the TMDB client's on-disk response
+// cache, so an operator can see what's cached (or confirm nothing is,
+// before going fully offline) without reaching into the filesystem.
+// GET /admin/cache
+func (h *HTTPHandler) GetAdminCache(c *gin.Context) {
+	keys, err := h.tmdbClient.CacheKeys()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys": keys,
+	})
+}
+
+// DeleteAdminCacheKey evicts a single key from the TMDB client's cache.
+// DELETE /admin/cache/:key
+func (h *HTTPHandler) DeleteAdminCacheKey(c *gin.Context) {
+	if err := h.tmdbClient.EvictCacheKey(c.Param("key")); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cache entry evicted",
+	})
+}
+
+// Health returns health status
+func (h *HTTPHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Metrics serves the Prometheus text exposition format. If metricsToken is
+// configured, it's required as a Bearer token (compared in constant time);
+// otherwise the endpoint is open, matching a typical scrape setup where the
+// scraper lives inside the same trusted network as the service.
+func (h *HTTPHandler) Metrics(c *gin.Context) {
+	if h.metricsToken != "" {
+		authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || subtle.ConstantTimeCompare([]byte(parts[1]), []byte(h.metricsToken)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing metrics token"})
+			return
+		}
+	}
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// authMiddleware enforces Bearer token authentication against the configured API token.
+func (h *HTTPHandler) authMiddleware(c *gin.Context) {
+	expected := strings.TrimSpace(h.getAPIToken())
+	if expected == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "WEB_API_TOKEN not set"})
+		c.Abort()
+		return
+	}
+
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid Authorization header"})
+		c.Abort()
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(parts[1]), []byte(expected)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// Helper functions
+
+func (h *HTTPHandler) getParam(c *gin.Context, key, defaultValue string) string {
+	value := c.Param(key)
+	if value == "" {
+		value = c.Query(key)
+	}
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func (h *HTTPHandler) getAPIToken() string {
+	return h.apiToken
+}
+
+// getLimitParam reads ?limit=, defaulting to 50 and rejecting anything
+// outside [1, 200].
+func (h *HTTPHandler) getLimitParam(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 50, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > 200 {
+		return 0, fmt.Errorf("limit must be between 1 and 200")
+	}
+	return limit, nil
 }
 
 func (h *HTTPHandler) getIntParam(c *gin.Context, key string) int64 {
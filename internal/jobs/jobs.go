@@ -0,0 +1,174 @@
+// Package jobs implements a durable background task queue backed by SQLite:
+// a Client enqueues work, a Server claims and runs it with a worker pool,
+// and failed jobs are retried with backoff before falling into a dead
+// letter state instead of being silently dropped, the way asynq's SQL
+// backends work.
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// State is a Job's position in its lifecycle.
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateActive    State = "active"
+	StateRetry     State = "retry"
+	StateCompleted State = "completed"
+	StateDead      State = "dead"
+)
+
+// defaultMaxRetries is how many times a job is retried before it's moved to
+// StateDead, unless overridden with WithRetry.
+const defaultMaxRetries = 5
+
+// Job is one unit of queued work.
+type Job struct {
+	ID          int64
+	Type        string
+	Payload     json.RawMessage
+	State       State
+	Attempts    int
+	MaxRetries  int
+	RunAt       time.Time
+	Deadline    *time.Time
+	LastError   string
+	CompletedAt *time.Time
+}
+
+// Handler runs a Job's payload. A non-nil error marks the job for retry (or
+// dead-lettering once MaxRetries is exhausted).
+type Handler func(payload json.RawMessage) error
+
+// enqueueOptions configures one Client.Enqueue call; set via the Option
+// functions below.
+type enqueueOptions struct {
+	maxRetries int
+	delay      time.Duration
+	uniqueKey  string
+	deadline   *time.Time
+}
+
+// Option configures a job passed to Client.Enqueue.
+type Option func(*enqueueOptions)
+
+// WithRetry overrides the default retry budget before a job is dead-lettered.
+func WithRetry(maxRetries int) Option {
+	return func(o *enqueueOptions) { o.maxRetries = maxRetries }
+}
+
+// WithDelay schedules the job to run after delay instead of immediately.
+func WithDelay(delay time.Duration) Option {
+	return func(o *enqueueOptions) { o.delay = delay }
+}
+
+// WithUniqueKey makes Enqueue return the existing job's ID instead of
+// inserting a new one if a job of the same type and key is already pending,
+// active, or retrying, so e.g. a cache-warm request for the same show
+// doesn't pile up duplicate work.
+func WithUniqueKey(key string) Option {
+	return func(o *enqueueOptions) { o.uniqueKey = key }
+}
+
+// WithDeadline dead-letters the job (without running or retrying it) if a
+// worker claims it after deadline, for work that's useless once too stale.
+// A handler that is already running is not interrupted at the deadline.
+func WithDeadline(deadline time.Time) Option {
+	return func(o *enqueueOptions) { o.deadline = &deadline }
+}
+
+// Client enqueues jobs and reports on their state.
+type Client struct {
+	db *sql.DB
+}
+
+// NewClient creates a new Client. db should be the same connection pool the
+// rest of the app uses, since Server claims jobs from the same table inside
+// its own transactions.
+func NewClient(db *sql.DB) *Client {
+	return &Client{db: db}
+}
+
+// Enqueue schedules jobType to run with payload marshaled to JSON, returning
+// the new job's ID. If WithUniqueKey is set and a non-terminal job with the
+// same type and key already exists, Enqueue returns that job's ID instead of
+// creating a duplicate.
+func (c *Client) Enqueue(jobType string, payload any, opts ...Option) (int64, error) {
+	o := enqueueOptions{maxRetries: defaultMaxRetries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	if o.uniqueKey != "" {
+		var existingID int64
+		err := c.db.QueryRow(`
+			SELECT id FROM jobs
+			WHERE type = ? AND unique_key = ? AND state IN ('pending', 'active', 'retry')
+		`, jobType, o.uniqueKey).Scan(&existingID)
+		if err == nil {
+			return existingID, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, fmt.Errorf("check unique key: %w", err)
+		}
+	}
+
+	runAt := time.Now().Add(o.delay)
+	var deadline *string
+	if o.deadline != nil {
+		s := o.deadline.UTC().Format(time.RFC3339)
+		deadline = &s
+	}
+
+	res, err := c.db.Exec(`
+		INSERT INTO jobs (type, payload_json, state, attempts, max_retries, run_at, deadline, unique_key)
+		VALUES (?, ?, 'pending', 0, ?, ?, ?, ?)
+	`, jobType, string(raw), o.maxRetries, runAt.UTC().Format(time.RFC3339), deadline, nullIfEmpty(o.uniqueKey))
+	if err != nil {
+		return 0, fmt.Errorf("enqueue job: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListByState returns up to limit jobs in state, most recently created
+// first, for admin visibility (e.g. the dead letter queue).
+func (c *Client) ListByState(state State, limit int) ([]Job, error) {
+	rows, err := c.db.Query(`
+		SELECT id, type, payload_json, state, attempts, max_retries, run_at, deadline, last_error, completed_at
+		FROM jobs
+		WHERE state = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, string(state), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows, so scanJob works
+// for both a single claimed job and a ListByState page.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanJob reads one jobs row in the column order used by claimOne and
+// Client.ListByState.
+func scanJob(row rowScanner) (Job, error) {
+	var j Job
+	var state string
+	var runAt string
+	var deadline, lastError sql.NullString
+	var completedAt sql.NullString
+
+	if err := row.Scan(&j.ID, &j.Type, &j.Payload, &state, &j.Attempts, &j.MaxRetries, &runAt, &deadline, &lastError, &completedAt); err != nil {
+		return Job{}, err
+	}
+
+	j.State = State(state)
+	if t, err := time.Parse(time.RFC3339, runAt); err == nil {
+		j.RunAt = t
+	}
+	if deadline.Valid {
+		if t, err := time.Parse(time.RFC3339, deadline.String); err == nil {
+			j.Deadline = &t
+		}
+	}
+	j.LastError = lastError.String
+	if completedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, completedAt.String); err == nil {
+			j.CompletedAt = &t
+		}
+	}
+	return j, nil
+}
+
+// Server claims due jobs from the jobs table and runs them against
+// per-type Handlers registered with RegisterHandler.
+type Server struct {
+	db          *sql.DB
+	concurrency int
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a new Server. db should be the same connection pool
+// passed to NewClient, since claiming a job relies on transactional
+// SELECT-then-UPDATE against that table.
+func NewServer(db *sql.DB, concurrency int) *Server {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Server{
+		db:          db,
+		concurrency: concurrency,
+		handlers:    make(map[string]Handler),
+	}
+}
+
+// RegisterHandler registers fn to run jobs of the given type. Registering an
+// already-registered type replaces its handler.
+func (s *Server) RegisterHandler(jobType string, fn Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = fn
+}
+
+// Start begins polling for due jobs with s.concurrency workers, until Stop
+// is called.
+func (s *Server) Start() {
+	s.stopChan = make(chan struct{})
+	for i := 0; i < s.concurrency; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	log.Printf("jobs: server started with %d worker(s)", s.concurrency)
+}
+
+// Stop signals every worker to finish its current job and exit, then waits
+// for them to do so.
+func (s *Server) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// worker repeatedly claims and runs one due job, backing off briefly
+// whenever the queue is empty.
+func (s *Server) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+
+		ran, err := s.claimAndRun()
+		if err != nil {
+			log.Printf("jobs: claim failed: %v", err)
+		}
+		if !ran {
+			select {
+			case <-time.After(time.Second):
+			case <-s.stopChan:
+				return
+			}
+		}
+	}
+}
+
+// claimAndRun claims one due job, if any, and runs it. It reports whether a
+// job was claimed.
+func (s *Server) claimAndRun() (bool, error) {
+	job, ok, err := s.claimOne()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	s.mu.Lock()
+	handler, known := s.handlers[job.Type]
+	s.mu.Unlock()
+	if !known {
+		s.fail(job, fmt.Errorf("no handler registered for job type %q", job.Type))
+		return true, nil
+	}
+
+	if job.Deadline != nil && time.Now().After(*job.Deadline) {
+		s.deadLetter(job, fmt.Errorf("deadline %s exceeded before run", job.Deadline.Format(time.RFC3339)))
+		return true, nil
+	}
+
+	if err := handler(job.Payload); err != nil {
+		s.fail(job, err)
+		return true, nil
+	}
+	s.complete(job)
+	return true, nil
+}
+
+// claimOne selects the oldest due pending or retrying job and flips it to
+// StateActive inside a transaction, so two workers (or two processes)
+// polling the same table never claim the same job twice.
+func (s *Server) claimOne() (Job, bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Job{}, false, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`
+		SELECT id, type, payload_json, state, attempts, max_retries, run_at, deadline, last_error, completed_at
+		FROM jobs
+		WHERE state IN ('pending', 'retry') AND run_at <= ?
+		ORDER BY run_at
+		LIMIT 1
+	`, time.Now().UTC().Format(time.RFC3339))
+
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return Job{}, false, nil
+	}
+	if err != nil {
+		return Job{}, false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE jobs SET state = 'active', attempts = attempts + 1 WHERE id = ?`, job.ID); err != nil {
+		return Job{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Job{}, false, err
+	}
+
+	job.State = StateActive
+	job.Attempts++
+	return job, true, nil
+}
+
+// fail records err against job and either schedules a retry with
+// exponential backoff or, once MaxRetries is exhausted, dead-letters it.
+func (s *Server) fail(job Job, err error) {
+	if job.Attempts > job.MaxRetries {
+		s.deadLetter(job, err)
+		return
+	}
+
+	backoff := time.Duration(1<<uint(job.Attempts)) * time.Second
+	const maxBackoff = 15 * time.Minute
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+
+	runAt := time.Now().Add(backoff)
+	if _, execErr := s.db.Exec(`
+		UPDATE jobs SET state = 'retry', run_at = ?, last_error = ? WHERE id = ?
+	`, runAt.UTC().Format(time.RFC3339), err.Error(), job.ID); execErr != nil {
+		log.Printf("jobs: failed to schedule retry for job %d: %v", job.ID, execErr)
+		return
+	}
+	log.Printf("jobs: job %d (%s) failed, retrying at %s: %v", job.ID, job.Type, runAt.Format("2006-01-02 15:04:05"), err)
+}
+
+// deadLetter moves job to StateDead, leaving it visible via
+// Client.ListByState for manual inspection instead of being retried or
+// dropped.
+func (s *Server) deadLetter(job Job, err error) {
+	if _, execErr := s.db.Exec(`
+		UPDATE jobs SET state = 'dead', last_error = ? WHERE id = ?
+	`, err.Error(), job.ID); execErr != nil {
+		log.Printf("jobs: failed to dead-letter job %d: %v", job.ID, execErr)
+		return
+	}
+	log.Printf("jobs: job %d (%s) dead-lettered after %d attempt(s): %v", job.ID, job.Type, job.Attempts, err)
+}
+
+// complete marks job as successfully finished.
+func (s *Server) complete(job Job) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.Exec(`UPDATE jobs SET state = 'completed', completed_at = ? WHERE id = ?`, now, job.ID); err != nil {
+		log.Printf("jobs: failed to mark job %d completed: %v", job.ID, err)
+	}
+}
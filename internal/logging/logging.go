@@ -0,0 +1,20 @@
+// Package logging sets up the process-wide slog default logger.
+package logging
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Setup installs a slog default logger. format "json" selects
+// slog.JSONHandler (for shipping logs to a collector); anything else
+// (including "") keeps the human-readable slog.TextHandler.
+func Setup(format string) {
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, nil)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, nil)
+	}
+	slog.SetDefault(slog.New(handler))
+}
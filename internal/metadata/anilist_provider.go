@@ -0,0 +1,38 @@
+package metadata
+
+import "fmt"
+
+// ProviderAniList is the source identifier for the (not yet implemented)
+// AniList provider, intended for anime-centric users whose shows are better
+// indexed there than on TMDB.
+const ProviderAniList = "anilist"
+
+// AniListProvider is a placeholder Provider adapter for AniList's GraphQL
+// API. Every method currently returns an error rather than making requests;
+// it exists as the scaffold a real client slots into.
+type AniListProvider struct{}
+
+// NewAniListProvider creates a new AniListProvider.
+func NewAniListProvider() *AniListProvider {
+	return &AniListProvider{}
+}
+
+// ID implements Provider.
+func (p *AniListProvider) ID() string {
+	return ProviderAniList
+}
+
+// Search implements Provider.
+func (p *AniListProvider) Search(query string) ([]SearchHit, error) {
+	return nil, fmt.Errorf("anilist provider: not yet implemented")
+}
+
+// GetShowDetails implements Provider.
+func (p *AniListProvider) GetShowDetails(externalID string) (*ShowDetails, error) {
+	return nil, fmt.Errorf("anilist provider: not yet implemented")
+}
+
+// GetSeasonEpisodes implements Provider.
+func (p *AniListProvider) GetSeasonEpisodes(externalID string, season int) ([]Episode, error) {
+	return nil, fmt.Errorf("anilist provider: not yet implemented")
+}
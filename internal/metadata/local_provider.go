@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProviderLocal is the source identifier for the local JSON directory provider.
+const ProviderLocal = "local"
+
+// localShowFile is the on-disk shape of a single show override, one file
+// per external ID under the provider's directory.
+type localShowFile struct {
+	Details ShowDetails          `json:"details"`
+	Seasons map[string][]Episode `json:"seasons"`
+}
+
+// LocalJSONProvider serves show metadata from a directory of JSON files
+// instead of calling out to a remote API. Useful for offline testing and
+// for shows that are missing or mis-tagged on TMDB.
+type LocalJSONProvider struct {
+	dir string
+}
+
+// NewLocalJSONProvider creates a LocalJSONProvider rooted at dir. Each show
+// is a file named "<externalID>.json" inside dir.
+func NewLocalJSONProvider(dir string) *LocalJSONProvider {
+	return &LocalJSONProvider{dir: dir}
+}
+
+// ID implements Provider.
+func (p *LocalJSONProvider) ID() string {
+	return ProviderLocal
+}
+
+// Search implements Provider by scanning every file in the directory and
+// matching on a case-insensitive substring of the show name.
+func (p *LocalJSONProvider) Search(query string) ([]SearchHit, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, fmt.Errorf("local provider: failed to read directory: %w", err)
+	}
+
+	query = strings.ToLower(query)
+	var hits []SearchHit
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		externalID := strings.TrimSuffix(entry.Name(), ".json")
+		show, err := p.load(externalID)
+		if err != nil {
+			continue
+		}
+
+		if query == "" || strings.Contains(strings.ToLower(show.Details.Name), query) {
+			hits = append(hits, SearchHit{
+				ExternalID: externalID,
+				Name:       show.Details.Name,
+			})
+		}
+	}
+	return hits, nil
+}
+
+// GetShowDetails implements Provider.
+func (p *LocalJSONProvider) GetShowDetails(externalID string) (*ShowDetails, error) {
+	show, err := p.load(externalID)
+	if err != nil {
+		return nil, err
+	}
+	details := show.Details
+	details.ExternalID = externalID
+	return &details, nil
+}
+
+// GetSeasonEpisodes implements Provider.
+func (p *LocalJSONProvider) GetSeasonEpisodes(externalID string, season int) ([]Episode, error) {
+	show, err := p.load(externalID)
+	if err != nil {
+		return nil, err
+	}
+	return show.Seasons[strconv.Itoa(season)], nil
+}
+
+func (p *LocalJSONProvider) load(externalID string) (*localShowFile, error) {
+	path := filepath.Join(p.dir, externalID+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("local provider: show %q not found: %w", externalID, err)
+	}
+
+	var show localShowFile
+	if err := json.Unmarshal(data, &show); err != nil {
+		return nil, fmt.Errorf("local provider: failed to parse %s: %w", path, err)
+	}
+	return &show, nil
+}
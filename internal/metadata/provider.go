@@ -0,0 +1,43 @@
+// Package metadata defines a provider-agnostic view of TV show metadata so
+// the rest of the app isn't coupled to TMDB specifically.
+package metadata
+
+// Episode is a provider-agnostic view of a single episode.
+type Episode struct {
+	Season   int    `json:"season"`
+	Episode  int    `json:"episode"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	AirDate  string `json:"air_date"`
+}
+
+// ShowDetails is a provider-agnostic view of a TV show's metadata.
+type ShowDetails struct {
+	ExternalID       string   `json:"external_id"`
+	Name             string   `json:"name"`
+	Status           string   `json:"status"`
+	OriginCountry    string   `json:"origin_country"`
+	NumberOfSeasons  int      `json:"number_of_seasons"`
+	NextEpisodeToAir *Episode `json:"next_episode_to_air"`
+	LastEpisodeToAir *Episode `json:"last_episode_to_air"`
+}
+
+// SearchHit is a single result from Provider.Search.
+type SearchHit struct {
+	ExternalID   string `json:"external_id"`
+	Name         string `json:"name"`
+	FirstAirDate string `json:"first_air_date"`
+}
+
+// Provider is implemented by each metadata backend (TMDB, a local JSON
+// override, TVDB, ...). ShowSyncService and TaskGenerator depend on this
+// interface rather than a concrete client so a show can be pinned to
+// whichever backend actually has its data.
+type Provider interface {
+	// ID identifies the provider, e.g. "tmdb" or "local". It is the value
+	// stored in TVShow.MetadataSource.
+	ID() string
+	Search(query string) ([]SearchHit, error)
+	GetShowDetails(externalID string) (*ShowDetails, error)
+	GetSeasonEpisodes(externalID string, season int) ([]Episode, error)
+}
@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+
+	"tv-tracker/internal/tmdb"
+)
+
+// ProviderTMDB is the source identifier for the TMDB-backed provider.
+const ProviderTMDB = "tmdb"
+
+// TMDBProvider adapts tmdb.Client to the provider-agnostic Provider interface.
+type TMDBProvider struct {
+	client *tmdb.Client
+}
+
+// NewTMDBProvider creates a new TMDBProvider.
+func NewTMDBProvider(client *tmdb.Client) *TMDBProvider {
+	return &TMDBProvider{client: client}
+}
+
+// ID implements Provider.
+func (p *TMDBProvider) ID() string {
+	return ProviderTMDB
+}
+
+// Search implements Provider.
+func (p *TMDBProvider) Search(query string) ([]SearchHit, error) {
+	results, err := p.client.SearchTV(query)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchHit, 0, len(results))
+	for _, r := range results {
+		hits = append(hits, SearchHit{
+			ExternalID:   strconv.Itoa(r.ID),
+			Name:         r.Name,
+			FirstAirDate: r.FirstAirDate,
+		})
+	}
+	return hits, nil
+}
+
+// GetShowDetails implements Provider.
+func (p *TMDBProvider) GetShowDetails(externalID string) (*ShowDetails, error) {
+	tmdbID, err := strconv.Atoi(externalID)
+	if err != nil {
+		return nil, fmt.Errorf("tmdb provider: invalid external id %q: %w", externalID, err)
+	}
+
+	details, err := p.client.GetTVDetails(tmdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	originCountry := ""
+	if len(details.OriginCountry) > 0 {
+		originCountry = details.OriginCountry[0]
+	}
+
+	return &ShowDetails{
+		ExternalID:       externalID,
+		Name:             details.Name,
+		Status:           details.Status,
+		OriginCountry:    originCountry,
+		NumberOfSeasons:  details.NumberOfSeasons,
+		NextEpisodeToAir: toMetadataEpisode(details.NextEpisodeToAir),
+		LastEpisodeToAir: toMetadataEpisode(details.LastEpisodeToAir),
+	}, nil
+}
+
+// GetSeasonEpisodes implements Provider.
+func (p *TMDBProvider) GetSeasonEpisodes(externalID string, season int) ([]Episode, error) {
+	tmdbID, err := strconv.Atoi(externalID)
+	if err != nil {
+		return nil, fmt.Errorf("tmdb provider: invalid external id %q: %w", externalID, err)
+	}
+
+	episodes, err := p.client.GetSeasonEpisodes(tmdbID, season)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Episode, 0, len(episodes))
+	for _, ep := range episodes {
+		out = append(out, Episode{
+			Season:   ep.SeasonNumber,
+			Episode:  ep.EpisodeNumber,
+			Name:     ep.Name,
+			Overview: ep.Overview,
+			AirDate:  ep.AirDate,
+		})
+	}
+	return out, nil
+}
+
+func toMetadataEpisode(ep *tmdb.EpisodeInfo) *Episode {
+	if ep == nil {
+		return nil
+	}
+	return &Episode{
+		Season:   ep.SeasonNumber,
+		Episode:  ep.EpisodeNumber,
+		Name:     ep.Name,
+		Overview: ep.Overview,
+		AirDate:  ep.AirDate,
+	}
+}
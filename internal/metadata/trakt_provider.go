@@ -0,0 +1,38 @@
+package metadata
+
+import "fmt"
+
+// ProviderTrakt is the source identifier for the (not yet implemented)
+// Trakt provider, intended for release-tracking users who already keep
+// their watch history there.
+const ProviderTrakt = "trakt"
+
+// TraktProvider is a placeholder Provider adapter for Trakt. Every method
+// currently returns an error rather than making requests; it exists as the
+// scaffold a real client slots into.
+type TraktProvider struct{}
+
+// NewTraktProvider creates a new TraktProvider.
+func NewTraktProvider() *TraktProvider {
+	return &TraktProvider{}
+}
+
+// ID implements Provider.
+func (p *TraktProvider) ID() string {
+	return ProviderTrakt
+}
+
+// Search implements Provider.
+func (p *TraktProvider) Search(query string) ([]SearchHit, error) {
+	return nil, fmt.Errorf("trakt provider: not yet implemented")
+}
+
+// GetShowDetails implements Provider.
+func (p *TraktProvider) GetShowDetails(externalID string) (*ShowDetails, error) {
+	return nil, fmt.Errorf("trakt provider: not yet implemented")
+}
+
+// GetSeasonEpisodes implements Provider.
+func (p *TraktProvider) GetSeasonEpisodes(externalID string, season int) ([]Episode, error) {
+	return nil, fmt.Errorf("trakt provider: not yet implemented")
+}
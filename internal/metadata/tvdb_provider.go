@@ -0,0 +1,37 @@
+package metadata
+
+import "fmt"
+
+// ProviderTVDB is the source identifier for the (not yet implemented) TVDB provider.
+const ProviderTVDB = "tvdb"
+
+// TVDBProvider is a placeholder Provider adapter for TheTVDB. It exists so a
+// show can already be pinned to TVDB via TVShow.MetadataSource and so
+// callers have a concrete type to wire up once a real client is built;
+// every method currently returns an error rather than making requests.
+type TVDBProvider struct{}
+
+// NewTVDBProvider creates a new TVDBProvider.
+func NewTVDBProvider() *TVDBProvider {
+	return &TVDBProvider{}
+}
+
+// ID implements Provider.
+func (p *TVDBProvider) ID() string {
+	return ProviderTVDB
+}
+
+// Search implements Provider.
+func (p *TVDBProvider) Search(query string) ([]SearchHit, error) {
+	return nil, fmt.Errorf("tvdb provider: not yet implemented")
+}
+
+// GetShowDetails implements Provider.
+func (p *TVDBProvider) GetShowDetails(externalID string) (*ShowDetails, error) {
+	return nil, fmt.Errorf("tvdb provider: not yet implemented")
+}
+
+// GetSeasonEpisodes implements Provider.
+func (p *TVDBProvider) GetSeasonEpisodes(externalID string, season int) ([]Episode, error) {
+	return nil, fmt.Errorf("tvdb provider: not yet implemented")
+}
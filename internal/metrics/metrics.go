@@ -0,0 +1,99 @@
+// Package metrics holds this service's Prometheus collectors. Handlers and
+// services import this package directly and call the package-level
+// collectors rather than threading a registry through constructors, since
+// the default prometheus.Registerer is already a process-wide singleton.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// SyncRunsTotal counts scheduled/manual TMDB refresh + sync cycles by
+	// outcome ("ok" or "error").
+	SyncRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tvtracker_sync_runs_total",
+		Help: "Total number of TMDB refresh + sync runs, by result.",
+	}, []string{"result"})
+
+	// SyncDuration measures how long a full refresh + sync cycle takes.
+	SyncDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "tvtracker_sync_duration_seconds",
+		Help:    "Duration of a TMDB refresh + sync run.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// TasksCreatedTotal counts tasks created by TaskGenerator, by task type
+	// (e.g. "UPDATE", "ORGANIZE").
+	TasksCreatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tvtracker_tasks_created_total",
+		Help: "Total number of tasks created, by task type.",
+	}, []string{"type"})
+
+	// ActiveShows reports the number of non-archived subscriptions as of the
+	// last sync.
+	ActiveShows = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tvtracker_active_shows",
+		Help: "Number of non-archived TV show subscriptions.",
+	})
+
+	// TMDBCacheHitsTotal and TMDBCacheMissesTotal count TMDBCacheService
+	// lookups served from the local cache versus requiring a refresh.
+	TMDBCacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tvtracker_tmdb_cache_hits_total",
+		Help: "Total number of TMDB cache lookups served from cache.",
+	})
+	TMDBCacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tvtracker_tmdb_cache_misses_total",
+		Help: "Total number of TMDB cache lookups that missed the cache.",
+	})
+
+	// TMDBCacheConditional304Total counts conditional TMDB requests (sending
+	// If-None-Match and/or If-Modified-Since) that came back 304 Not
+	// Modified, meaning the cached payload was still current.
+	TMDBCacheConditional304Total = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tvtracker_tmdb_cache_conditional_304_total",
+		Help: "Total number of conditional TMDB requests answered 304 Not Modified.",
+	})
+
+	// TMDBRefreshErrorsTotal counts failed TMDB cache refreshes, including
+	// ones kicked off in the background by GetStaleWhileRevalidate.
+	TMDBRefreshErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tvtracker_tmdb_refresh_errors_total",
+		Help: "Total number of TMDB cache refresh attempts that failed.",
+	})
+
+	// ScheduleRulesFiredTotal counts how many times each TaskScheduler rule
+	// has run, by rule name, regardless of whether it matched any shows.
+	ScheduleRulesFiredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tvtracker_schedule_rules_fired_total",
+		Help: "Total number of times a scheduled task-generation rule has run, by rule name.",
+	}, []string{"rule"})
+
+	// ScheduleTasksCreatedTotal counts tasks created by TaskScheduler rules,
+	// by rule name. Lower than ScheduleRulesFiredTotal's implied match count
+	// whenever a rule's selector matches shows that already have a pending
+	// task of that type (deduplicated by TaskRepository.Create).
+	ScheduleTasksCreatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tvtracker_schedule_tasks_created_total",
+		Help: "Total number of tasks created by scheduled task-generation rules, by rule name.",
+	}, []string{"rule"})
+
+	// HTTPRequestDuration measures handler latency, by route and status
+	// code, populated by the Gin middleware in middleware.go.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tvtracker_http_request_duration_seconds",
+		Help:    "HTTP request duration, by route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+)
+
+// Handler returns the HTTP handler that serves the Prometheus text
+// exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
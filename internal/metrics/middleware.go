@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinMiddleware records HTTPRequestDuration for every request. It uses the
+// matched route template (c.FullPath()) rather than the raw URL so that
+// e.g. "/api/shows/:id" isn't split into one series per show ID.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+
+		HTTPRequestDuration.WithLabelValues(
+			c.Request.Method,
+			path,
+			strconv.Itoa(c.Writer.Status()),
+		).Observe(time.Since(start).Seconds())
+	}
+}
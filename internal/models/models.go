@@ -6,8 +6,9 @@ import "time"
 type TaskType string
 
 const (
-	TaskTypeUpdate   TaskType = "UPDATE"
-	TaskTypeOrganize TaskType = "ORGANIZE"
+	TaskTypeUpdate        TaskType = "UPDATE"
+	TaskTypeOrganize      TaskType = "ORGANIZE"
+	TaskTypeResourceFound TaskType = "RESOURCE_FOUND"
 )
 
 // TVShow represents a subscribed TV show
@@ -15,16 +16,56 @@ type TVShow struct {
 	ID                   int64     `json:"id"`
 	TMDBID               int       `json:"tmdb_id"`
 	Name                 string    `json:"name"`
+	OriginalName         string    `json:"original_name"`
+	Overview             string    `json:"overview"`
+	Genres               string    `json:"genres"` // comma-separated genre names, for display and FTS indexing
 	TotalSeasons         int       `json:"total_seasons"`
 	Status               string    `json:"status"`         // Returning Series, Ended, Canceled
 	OriginCountry        string    `json:"origin_country"` // Country code (US, CN, JP, etc.)
 	ResourceTime         string    `json:"resource_time"`  // Expected resource availability time
 	ResourceTimeIsManual bool      `json:"resource_time_is_manual"`
+	MetadataSource       string    `json:"metadata_source"` // Provider ID, e.g. "tmdb" or "local"
 	IsArchived           bool      `json:"is_archived"`
+	QualityFilter        string    `json:"quality_filter"`       // "" (none) or QualityFilterExcludeCam
+	PreferredResolution  string    `json:"preferred_resolution"` // e.g. "1080p"; "" means no preference
+	NotifyChannel        string    `json:"notify_channel"`       // notify.Registry channel name override; "" means every registered channel
+	AutoSkipSpecials     bool      `json:"auto_skip_specials"`   // skip syncing/tasking season 0 for this show
+	PreferredQuality     string    `json:"preferred_quality"`    // e.g. "1080p", "2160p", "WEB-DL", "BluRay"; "" means no preference
+	PreferredCodec       string    `json:"preferred_codec"`      // e.g. "x264", "x265", "AV1"; "" means no preference
+	PreferredGroup       string    `json:"preferred_group"`      // preferred release group tag; "" means no preference
+	RejectCam            bool      `json:"reject_cam"`           // default true; embeds "-CAM" hint in UPDATE task descriptions when true
+	MinQuality           string    `json:"min_quality"`          // e.g. "1080p"; a candidate below this resolution rank is rejected, "" means no floor
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 }
 
+// QualityFilterExcludeCam is the TVShow.QualityFilter value that rejects
+// cam/telesync/workprint ("枪版") releases, per release.ReleaseInfo.Qiangban.
+const QualityFilterExcludeCam = "exclude_cam"
+
+// ShowPolicy groups the per-show download/notify overrides consulted by
+// service.Dispatcher when a candidate release shows up or an UPDATE/ORGANIZE
+// task is about to be announced. A zero-value field means "no override,
+// fall back to global/default behavior".
+type ShowPolicy struct {
+	QualityFilter       string
+	PreferredResolution string
+	NotifyChannel       string
+	AutoSkipSpecials    bool
+	MinQuality          string
+}
+
+// Policy extracts show's per-show policy overrides.
+func (s TVShow) Policy() ShowPolicy {
+	return ShowPolicy{
+		QualityFilter:       s.QualityFilter,
+		PreferredResolution: s.PreferredResolution,
+		NotifyChannel:       s.NotifyChannel,
+		AutoSkipSpecials:    s.AutoSkipSpecials,
+		MinQuality:          s.MinQuality,
+	}
+}
+
 // Episode represents a cached episode from TMDB
 type Episode struct {
 	ID       int64  `json:"id"`
@@ -34,16 +75,104 @@ type Episode struct {
 	Title    string `json:"title"`
 	Overview string `json:"overview"`
 	AirDate  string `json:"air_date"` // YYYY-MM-DD format
+	Skipped  bool   `json:"skipped"`  // true if marked ignored via skipped_episodes (usually a special)
+	Watched  bool   `json:"watched"`  // true if marked watched via watched_episodes
 }
 
-// Task represents a pending task (update reminder or organize task)
+// EpisodeResource is a candidate download link attached to a specific
+// episode, parsed into structured quality metadata so Dispatcher can reject
+// cam/telesync rips or releases below a show's MinQuality floor before the
+// link is surfaced. Unlike Task (which records a RESOURCE_FOUND task keyed
+// by show+filename for the dashboard), EpisodeResource is keyed by the
+// actual episode row and keeps the URL, for episode-level tracking.
+type EpisodeResource struct {
+	ID         int64     `json:"id"`
+	EpisodeID  int64     `json:"episode_id"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Resolution string    `json:"resolution"`
+	Source     string    `json:"source"`
+	Codec      string    `json:"codec"`
+	Group      string    `json:"group"`
+	Qiangban   bool      `json:"qiangban"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Task represents a pending task (update reminder or organize task).
+// EpisodeID (SxxExx, empty for show-level tasks like ORGANIZE) is the
+// structured field idempotence now keys on; Description is a rendered,
+// human-readable string kept only for existing HTTP/notification consumers
+// and must not be parsed back into structured data (see proto.TaskPayload).
 type Task struct {
-	ID           int64     `json:"id"`
-	TVShowID     int64     `json:"tv_show_id"`
-	TVShowName   string    `json:"tv_show_name"`  // For display purposes
-	ResourceTime string    `json:"resource_time"` // Expected resource availability time
-	TaskType     TaskType  `json:"task_type"`
-	Description  string    `json:"description"`
-	IsCompleted  bool      `json:"is_completed"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int64      `json:"id"`
+	TVShowID     int64      `json:"tv_show_id"`
+	TVShowName   string     `json:"tv_show_name"`  // For display purposes
+	ResourceTime string     `json:"resource_time"` // Expected resource availability time
+	TaskType     TaskType   `json:"task_type"`
+	EpisodeID    string     `json:"episode_id,omitempty"`
+	Description  string     `json:"description"`
+	IsCompleted  bool       `json:"is_completed"`
+	Priority     int        `json:"priority"`           // 0-100, higher sorts first on the dashboard; 0 means unset
+	DueDate      *time.Time `json:"due_date,omitempty"` // explicit deadline, distinct from CreatedAt; nil means none set
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Severity categorizes a per-show notification event so a notify.Registry
+// channel can opt into a subset (e.g. route new episodes to Telegram but
+// show-ended to email). Declared here, rather than in internal/notify,
+// so internal/service can reference it without an import cycle (notify
+// already depends on service for TelegramBot's dependencies).
+type Severity string
+
+const (
+	SeverityNewEpisode Severity = "new_episode"
+	SeverityShowEnded  Severity = "show_ended"
+)
+
+// WebhookSubscription is an external callback registered to receive push
+// notifications, WebSub-style. Topic is either "*" (everything) or a
+// specific TMDB ID as a string, so a subscriber can follow one show.
+type WebhookSubscription struct {
+	ID           int64      `json:"id"`
+	CallbackURL  string     `json:"callback_url"`
+	Topic        string     `json:"topic"`
+	Secret       string     `json:"-"` // never serialized back to API callers
+	LeaseSeconds int        `json:"lease_seconds"`
+	VerifiedAt   *time.Time `json:"verified_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// APIKeyScope is a single capability grantable to an API key, combined into
+// APIKey.Scopes as a bitmask so one key can hold several. Declared here,
+// rather than in internal/auth, so internal/repository can reference it
+// without depending on the auth package.
+type APIKeyScope uint32
+
+const (
+	ScopeSearchRead APIKeyScope = 1 << iota
+	ScopeLibraryRead
+	ScopeLibraryWrite
+	ScopeTasksWrite
+	ScopeReportSend
+)
+
+// Has reports whether s includes every bit set in want.
+func (s APIKeyScope) Has(want APIKeyScope) bool {
+	return s&want == want
+}
+
+// APIKey is a caller credential for the HTTP API. The raw secret is never
+// stored, only an argon2id hash salted per key; RevokedAt distinguishes a
+// manually revoked key from one that has merely expired.
+type APIKey struct {
+	ID         int64       `json:"id"`
+	OwnerLabel string      `json:"owner_label"`
+	SecretHash string      `json:"-"`
+	Salt       string      `json:"-"`
+	Scopes     APIKeyScope `json:"scopes"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
 }
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// AppriseNotifier delivers the daily report through an Apprise API server
+// (https://github.com/caronc/apprise-api), which fans the message out to
+// whatever services that server's config key has registered.
+type AppriseNotifier struct {
+	baseURL    string
+	key        string
+	httpClient *http.Client
+}
+
+// NewAppriseNotifier creates a new AppriseNotifier targeting the given
+// Apprise API server and config key.
+func NewAppriseNotifier(baseURL, key string) *AppriseNotifier {
+	return &AppriseNotifier{
+		baseURL:    baseURL,
+		key:        key,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (a *AppriseNotifier) Name() string {
+	return "apprise"
+}
+
+// SendDailyReport posts tasks to Apprise's /notify/<key> endpoint.
+func (a *AppriseNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := a.HealthCheck(); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	if len(tasks) == 0 {
+		body.WriteString("今日暂无剧集更新")
+	} else {
+		for i, task := range tasks {
+			fmt.Fprintf(&body, "%d. %s\n", i+1, task.Description)
+		}
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"title": "TV Tracker 今日更新",
+		"body":  body.String(),
+		"type":  "info",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode apprise payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/notify/%s", a.baseURL, a.key)
+	resp, err := a.httpClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("apprise returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a server and config key are configured.
+func (a *AppriseNotifier) HealthCheck() error {
+	if a.baseURL == "" || a.key == "" {
+		return fmt.Errorf("apprise notifier not configured")
+	}
+	return nil
+}
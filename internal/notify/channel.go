@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+
+	"tv-tracker/internal/events"
+	"tv-tracker/internal/models"
+)
+
+// notifierChannel adapts an existing Notifier into an events.Channel by
+// formatting the Notification as a single synthetic task, so email/Telegram/
+// webhook/etc. can all serve new event kinds without each needing its own
+// Notification-aware delivery code.
+type notifierChannel struct {
+	Notifier
+}
+
+// AsChannel wraps n so it can be registered with an events.Router alongside
+// Notification-native channels.
+func AsChannel(n Notifier) events.Channel {
+	return notifierChannel{Notifier: n}
+}
+
+func (c notifierChannel) Send(ctx context.Context, n events.Notification) error {
+	description := n.Title
+	if n.Body != "" {
+		description += "\n" + n.Body
+	}
+	return c.SendDailyReport([]models.Task{{Description: description}})
+}
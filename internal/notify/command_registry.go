@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"sync"
+
+	tele "gopkg.in/telebot.v3"
+
+	"tv-tracker/internal/repository"
+)
+
+// CommandHandler is a single slash command registered with CommandRegistry.
+type CommandHandler struct {
+	Name        string // without the leading slash, e.g. "subscribe"
+	Description string // shown in /help's auto-generated command list
+	// MinRole is the least-privileged BotRole allowed to run this command,
+	// enforced the same way as the inline button handlers (see requireRole).
+	MinRole repository.BotRole
+	Handler func(c tele.Context, args []string) error
+}
+
+// CommandRegistry holds the bot's typed slash commands, replacing a
+// hard-coded switch with one registration call per command so new
+// interactive flows don't need another case added to HandleText.
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	commands map[string]CommandHandler
+	order    []string // registration order, for a stable /help listing
+}
+
+// NewCommandRegistry creates an empty CommandRegistry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{commands: make(map[string]CommandHandler)}
+}
+
+// Register adds a command, overwriting any previous registration under the
+// same name.
+func (r *CommandRegistry) Register(cmd CommandHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.commands[cmd.Name]; !exists {
+		r.order = append(r.order, cmd.Name)
+	}
+	r.commands[cmd.Name] = cmd
+}
+
+// All returns every registered command in registration order.
+func (r *CommandRegistry) All() []CommandHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmds := make([]CommandHandler, 0, len(r.order))
+	for _, name := range r.order {
+		cmds = append(cmds, r.commands[name])
+	}
+	return cmds
+}
+
+// conversationState tracks each chat's place in a multi-step interactive
+// flow (e.g. "waiting for a TMDB ID" after tapping the subscribe button),
+// keyed by chat ID instead of a single bot-wide field so concurrent chats
+// can't stomp on each other's state.
+type conversationState struct {
+	mu    sync.RWMutex
+	steps map[int64]BotState
+}
+
+// newConversationState creates an empty conversationState.
+func newConversationState() *conversationState {
+	return &conversationState{steps: make(map[int64]BotState)}
+}
+
+// set records chatID's step, or clears it entirely when state is StateIdle.
+func (cs *conversationState) set(chatID int64, state BotState) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if state == StateIdle {
+		delete(cs.steps, chatID)
+		return
+	}
+	cs.steps[chatID] = state
+}
+
+// get returns chatID's current step, or StateIdle if it has none.
+func (cs *conversationState) get(chatID int64) BotState {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	if state, ok := cs.steps[chatID]; ok {
+		return state
+	}
+	return StateIdle
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// DiscordNotifier posts the daily report to a Discord incoming webhook URL
+// (https://discord.com/developers/docs/resources/webhook#execute-webhook).
+type DiscordNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewDiscordNotifier creates a new DiscordNotifier targeting webhookURL.
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	return &DiscordNotifier{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (d *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+// SendDailyReport posts tasks as a single Discord message.
+func (d *DiscordNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := d.HealthCheck(); err != nil {
+		return err
+	}
+
+	content := FormatDailyReport(tasks)
+	// Discord caps message content at 2000 characters.
+	if len(content) > 2000 {
+		content = content[:1997] + "..."
+	}
+
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return fmt.Errorf("failed to encode discord payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a webhook URL is configured.
+func (d *DiscordNotifier) HealthCheck() error {
+	if d.webhookURL == "" {
+		return fmt.Errorf("discord notifier not configured")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"tv-tracker/internal/models"
+)
+
+// EmailNotifier delivers the daily report over SMTP.
+type EmailNotifier struct {
+	smtpHost string
+	smtpPort string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewEmailNotifier creates a new EmailNotifier.
+func NewEmailNotifier(smtpHost, smtpPort, username, password, from string, to []string) *EmailNotifier {
+	return &EmailNotifier{
+		smtpHost: smtpHost,
+		smtpPort: smtpPort,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+// Name identifies this channel.
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+// SendDailyReport emails tasks as a plain-text list.
+func (e *EmailNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := e.HealthCheck(); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	body.WriteString("Subject: TV Tracker 今日更新日报\r\n")
+	body.WriteString("\r\n")
+	if len(tasks) == 0 {
+		body.WriteString("今日暂无剧集更新\r\n")
+	} else {
+		for i, task := range tasks {
+			body.WriteString(fmt.Sprintf("%d. %s\r\n", i+1, task.Description))
+		}
+	}
+
+	auth := smtp.PlainAuth("", e.username, e.password, e.smtpHost)
+	addr := e.smtpHost + ":" + e.smtpPort
+	return smtp.SendMail(addr, auth, e.from, e.to, []byte(body.String()))
+}
+
+// HealthCheck reports whether SMTP delivery is fully configured.
+func (e *EmailNotifier) HealthCheck() error {
+	if e.smtpHost == "" || e.from == "" || len(e.to) == 0 {
+		return fmt.Errorf("email notifier not configured")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// GotifyNotifier delivers the daily report to a self-hosted Gotify server's
+// message API (https://gotify.net/api-docs#/message).
+type GotifyNotifier struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGotifyNotifier creates a new GotifyNotifier targeting baseURL (e.g.
+// "https://gotify.example.com") with the given application token.
+func NewGotifyNotifier(baseURL, token string) *GotifyNotifier {
+	return &GotifyNotifier{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (g *GotifyNotifier) Name() string {
+	return "gotify"
+}
+
+// SendDailyReport posts tasks as a single Gotify message.
+func (g *GotifyNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := g.HealthCheck(); err != nil {
+		return err
+	}
+
+	message := "今日暂无剧集更新"
+	if len(tasks) > 0 {
+		var sb bytes.Buffer
+		for i, task := range tasks {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, task.Description)
+		}
+		message = sb.String()
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"title":    "TV Tracker 今日更新",
+		"message":  message,
+		"priority": 5,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode gotify message: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/message?token=%s", g.baseURL, g.token)
+	resp, err := g.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("gotify returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a server and token are configured.
+func (g *GotifyNotifier) HealthCheck() error {
+	if g.baseURL == "" || g.token == "" {
+		return fmt.Errorf("gotify notifier not configured")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package notify
+
+import "tv-tracker/internal/models"
+
+// NoopNotifier discards every report, for tests and local development
+// without any channel configured.
+type NoopNotifier struct {
+	name string
+}
+
+// NewNoopNotifier creates a new NoopNotifier identified by name.
+func NewNoopNotifier(name string) *NoopNotifier {
+	if name == "" {
+		name = "noop"
+	}
+	return &NoopNotifier{name: name}
+}
+
+// Name identifies this channel.
+func (n *NoopNotifier) Name() string {
+	return n.name
+}
+
+// SendDailyReport does nothing and never fails.
+func (n *NoopNotifier) SendDailyReport(tasks []models.Task) error {
+	return nil
+}
+
+// HealthCheck always succeeds.
+func (n *NoopNotifier) HealthCheck() error {
+	return nil
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/timeutil"
+)
+
+// Notifier is a single delivery channel for reports and per-show events.
+type Notifier interface {
+	// Name identifies the channel, e.g. "telegram", used to target it from
+	// POST /api/report's channels list and POST /api/notifiers/:name/test.
+	Name() string
+	// SendDailyReport delivers the given pending UPDATE tasks.
+	SendDailyReport(tasks []models.Task) error
+	// HealthCheck reports whether the channel is configured and reachable.
+	HealthCheck() error
+}
+
+// ChannelStatus reports a channel's configuration and last delivery outcome.
+type ChannelStatus struct {
+	Name       string     `json:"name"`
+	Healthy    bool       `json:"healthy"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+type channelEntry struct {
+	notifier   Notifier
+	severities map[models.Severity]bool
+}
+
+// Registry fans out daily reports and per-show events to every registered
+// Notifier concurrently, aggregating errors per-channel.
+type Registry struct {
+	mu       sync.Mutex
+	channels map[string]*channelEntry
+	status   map[string]*ChannelStatus
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		channels: make(map[string]*channelEntry),
+		status:   make(map[string]*ChannelStatus),
+	}
+}
+
+// Register adds a channel to the registry. severities controls which
+// per-show events (via SendEvent) this channel receives; an empty list
+// means the channel only receives daily reports, not individual events.
+func (r *Registry) Register(n Notifier, severities ...models.Severity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	allowed := make(map[models.Severity]bool, len(severities))
+	for _, s := range severities {
+		allowed[s] = true
+	}
+
+	r.channels[n.Name()] = &channelEntry{notifier: n, severities: allowed}
+	r.status[n.Name()] = &ChannelStatus{Name: n.Name()}
+}
+
+// List returns the current health and last-delivery status of every
+// registered channel.
+func (r *Registry) List() []ChannelStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]ChannelStatus, 0, len(r.channels))
+	for name, entry := range r.channels {
+		status := *r.status[name]
+		status.Healthy = entry.notifier.HealthCheck() == nil
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// SendDailyReport delivers tasks to the named channels, or every registered
+// channel if names is empty. It returns any per-channel errors keyed by
+// channel name; a channel not found in the registry is reported as an error
+// under its requested name.
+func (r *Registry) SendDailyReport(tasks []models.Task, names []string) map[string]error {
+	entries := r.resolveChannels(names)
+
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, entry := range entries {
+		if entry == nil {
+			mu.Lock()
+			errs[name] = fmt.Errorf("no such notifier channel: %s", name)
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, entry *channelEntry) {
+			defer wg.Done()
+			err := entry.notifier.SendDailyReport(tasks)
+			r.recordDelivery(name, err)
+			if err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name, entry)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// SendEvent delivers a synthetic report to every channel subscribed to the
+// given severity, used for per-show events like new episodes or show-ended.
+func (r *Registry) SendEvent(severity models.Severity, tasks []models.Task) map[string]error {
+	return r.SendEventTo(severity, tasks, nil)
+}
+
+// SendEventTo is SendEvent narrowed to the given channel names, used to
+// honor a show's NotifyChannel policy override. An empty only behaves like
+// SendEvent, delivering to every channel subscribed to severity; a
+// non-empty only still requires the channel to be subscribed to severity,
+// it just can't broaden which channels receive it.
+func (r *Registry) SendEventTo(severity models.Severity, tasks []models.Task, only []string) map[string]error {
+	wanted := make(map[string]bool, len(only))
+	for _, name := range only {
+		wanted[name] = true
+	}
+
+	r.mu.Lock()
+	var matching []string
+	for name, entry := range r.channels {
+		if !entry.severities[severity] {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		matching = append(matching, name)
+	}
+	r.mu.Unlock()
+
+	if len(matching) == 0 {
+		return nil
+	}
+	return r.SendDailyReport(tasks, matching)
+}
+
+// Test sends a synthetic daily report to a single named channel, for
+// POST /api/notifiers/:name/test.
+func (r *Registry) Test(name string) error {
+	errs := r.SendDailyReport([]models.Task{{Description: "测试通知|这是一条测试通知"}}, []string{name})
+	return errs[name]
+}
+
+// resolveChannels maps channel names to their registered entry, returning
+// every registered channel when names is empty. A requested name with no
+// matching channel maps to a nil entry so the caller can report it as an error.
+func (r *Registry) resolveChannels(names []string) map[string]*channelEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(names) == 0 {
+		entries := make(map[string]*channelEntry, len(r.channels))
+		for name, entry := range r.channels {
+			entries[name] = entry
+		}
+		return entries
+	}
+
+	entries := make(map[string]*channelEntry, len(names))
+	for _, name := range names {
+		entries[name] = r.channels[name]
+	}
+	return entries
+}
+
+func (r *Registry) recordDelivery(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status, ok := r.status[name]
+	if !ok {
+		return
+	}
+	now := timeutil.Now()
+	status.LastSentAt = &now
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		status.LastError = ""
+	}
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseNotifyURLs parses a comma-separated NOTIFY_URLS value into concrete
+// Notifier channels, one per recognized scheme:
+//
+//	tgram://token/chatid          TelegramPushNotifier
+//	gotify://host/token           GotifyNotifier
+//	ntfy://host/topic             NtfyNotifier
+//	webhook+https://host/hook     WebhookPoster
+//	webhook+http://host/hook      WebhookPoster
+//	apprise://host/key            AppriseNotifier
+//
+// Each entry's Name() collides with the next entry of the same scheme if
+// NOTIFY_URLS lists more than one of the same kind; callers registering
+// into a Registry should dedupe or rename as needed. An empty raw returns
+// no notifiers and no error.
+func ParseNotifyURLs(raw string) ([]Notifier, error) {
+	var notifiers []Notifier
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		n, err := parseNotifyURL(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NOTIFY_URLS entry %q: %w", entry, err)
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	return notifiers, nil
+}
+
+func parseNotifyURL(entry string) (Notifier, error) {
+	if strings.HasPrefix(entry, "webhook+") {
+		target := strings.TrimPrefix(entry, "webhook+")
+		if _, err := url.Parse(target); err != nil {
+			return nil, err
+		}
+		return NewWebhookPoster(target, ""), nil
+	}
+
+	u, err := url.Parse(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "tgram":
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("expected tgram://token/chatid")
+		}
+		return NewTelegramPushNotifier(u.Host, topic), nil
+	case "gotify":
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("expected gotify://host/token")
+		}
+		return NewGotifyNotifier("https://"+u.Host, topic), nil
+	case "ntfy":
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("expected ntfy://host/topic")
+		}
+		return NewNtfyNotifier("https://"+u.Host, topic), nil
+	case "apprise":
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("expected apprise://host/key")
+		}
+		return NewAppriseNotifier("https://"+u.Host, topic), nil
+	default:
+		return nil, fmt.Errorf("unrecognized scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// NtfyNotifier delivers the daily report to an ntfy (https://ntfy.sh)
+// topic, which accepts the message as a plain-text POST body.
+type NtfyNotifier struct {
+	baseURL    string
+	topic      string
+	httpClient *http.Client
+}
+
+// NewNtfyNotifier creates a new NtfyNotifier publishing to topic on baseURL
+// (e.g. "https://ntfy.sh").
+func NewNtfyNotifier(baseURL, topic string) *NtfyNotifier {
+	return &NtfyNotifier{
+		baseURL:    baseURL,
+		topic:      topic,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (n *NtfyNotifier) Name() string {
+	return "ntfy"
+}
+
+// SendDailyReport publishes tasks as a single ntfy message.
+func (n *NtfyNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := n.HealthCheck(); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	if len(tasks) == 0 {
+		body.WriteString("今日暂无剧集更新")
+	} else {
+		for i, task := range tasks {
+			fmt.Fprintf(&body, "%d. %s\n", i+1, task.Description)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.baseURL+"/"+n.topic, strings.NewReader(body.String()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", "TV Tracker 今日更新")
+	req.Header.Set("Priority", strconv.Itoa(3))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a server and topic are configured.
+func (n *NtfyNotifier) HealthCheck() error {
+	if n.baseURL == "" || n.topic == "" {
+		return fmt.Errorf("ntfy notifier not configured")
+	}
+	return nil
+}
@@ -1,16 +1,17 @@
 package notify
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	tele "gopkg.in/telebot.v3"
 
+	"tv-tracker/internal/downloader"
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
@@ -21,24 +22,39 @@ import (
 type BotState string
 
 const (
-	StateIdle          BotState = "idle"
-	StateWaitingTMDBID BotState = "waiting_tmdb_id"
-	StateWaitingAPIKey BotState = "waiting_api_key"
+	StateIdle               BotState = "idle"
+	StateWaitingTMDBID      BotState = "waiting_tmdb_id"
+	StateWaitingAPIKey      BotState = "waiting_api_key"
+	StateWaitingSearchQuery BotState = "waiting_search_query"
 )
 
+// roleRank orders BotRoles from least to most privileged, so requireRole
+// can check "at least this role" instead of an exact match.
+var roleRank = map[repository.BotRole]int{
+	repository.BotRolePending:    0,
+	repository.BotRoleReadOnly:   1,
+	repository.BotRoleSubscriber: 2,
+	repository.BotRoleOwner:      3,
+}
+
 // TelegramBot handles Telegram bot interactions
 type TelegramBot struct {
-	bot         *tele.Bot
-	chatID      int64  // 管理员 Chat ID
-	channelID   int64  // 频道 ID，用于发送日报
-	state       BotState
-	stateMu     sync.RWMutex
-	tmdb        *tmdb.Client
-	subMgr      *service.SubscriptionManager
-	taskGen     *service.TaskGenerator
-	taskBoard   *service.TaskBoardService
-	episodeRepo *repository.EpisodeRepository
-	backupSvc   *service.BackupService
+	bot          *tele.Bot
+	chatID       int64 // 管理员 Chat ID, auto-registered as BotRoleOwner on startup
+	channelID    int64 // 频道 ID，用于发送日报
+	convState    *conversationState
+	commands     *CommandRegistry
+	tmdb         *tmdb.Client
+	subMgr       *service.SubscriptionManager
+	taskGen      *service.TaskGenerator
+	taskBoard    *service.TaskBoardService
+	episodeRepo  *repository.EpisodeRepository
+	backupSvc    *service.BackupService
+	userRepo     *repository.BotUserRepository
+	prefRepo     *repository.ShowPreferencesRepository
+	settingsRepo *repository.SettingsRepository
+	downloader   downloader.Client
+	importExport *service.ImportExportService
 }
 
 // Dependencies holds all dependencies for TelegramBot
@@ -49,6 +65,23 @@ type Dependencies struct {
 	TaskBoard   *service.TaskBoardService
 	EpisodeRepo *repository.EpisodeRepository
 	BackupSvc   *service.BackupService
+	// UserRepo backs per-chat authorization (see resolveRole); nil keeps the
+	// bot's old single-admin behavior, where only chatID is ever authorized.
+	UserRepo *repository.BotUserRepository
+	// PrefRepo backs the per-show settings submenu (see ShowSettingsKeyboard);
+	// nil disables the "我的订阅" entry point entirely.
+	PrefRepo *repository.ShowPreferencesRepository
+	// SettingsRepo persists a live-rotated TMDB API key (see
+	// handleAPIKeyInput); nil still hot-swaps a validated key but doesn't
+	// persist it, so it's lost on restart.
+	SettingsRepo *repository.SettingsRepository
+	// Downloader pushes a subscribed show into a media manager's queue (see
+	// HandlePushToDownloaderCallback); nil hides the "📥 推送到下载器" button.
+	Downloader downloader.Client
+	// ImportExport backs bulk subscribe/export via document upload (see
+	// HandleDocumentUpload); nil makes the "📤 导出订阅" button reply that
+	// import/export isn't configured, and ignores uploaded documents.
+	ImportExport *service.ImportExportService
 }
 
 // NewTelegramBot creates a new TelegramBot
@@ -64,19 +97,37 @@ func NewTelegramBot(token string, chatID int64, channelID int64, deps Dependenci
 	}
 
 	tb := &TelegramBot{
-		bot:         bot,
-		chatID:      chatID,
-		channelID:   channelID,
-		state:       StateIdle,
-		tmdb:        deps.TMDB,
-		subMgr:      deps.SubMgr,
-		taskGen:     deps.TaskGen,
-		taskBoard:   deps.TaskBoard,
-		episodeRepo: deps.EpisodeRepo,
-		backupSvc:   deps.BackupSvc,
+		bot:          bot,
+		chatID:       chatID,
+		channelID:    channelID,
+		convState:    newConversationState(),
+		commands:     NewCommandRegistry(),
+		tmdb:         deps.TMDB,
+		subMgr:       deps.SubMgr,
+		taskGen:      deps.TaskGen,
+		taskBoard:    deps.TaskBoard,
+		episodeRepo:  deps.EpisodeRepo,
+		backupSvc:    deps.BackupSvc,
+		userRepo:     deps.UserRepo,
+		prefRepo:     deps.PrefRepo,
+		settingsRepo: deps.SettingsRepo,
+		downloader:   deps.Downloader,
+		importExport: deps.ImportExport,
+	}
+
+	// The configured admin chat ID keeps owner access even on a fresh
+	// allow-list, so deployments that don't care about multi-user roles
+	// don't need to do anything extra.
+	if tb.userRepo != nil && chatID != 0 {
+		if existing, err := tb.userRepo.Get(chatID); err == nil && existing == nil {
+			if err := tb.userRepo.Upsert(chatID, "", repository.BotRoleOwner); err != nil {
+				log.Printf("failed to bootstrap owner chat %d: %v", chatID, err)
+			}
+		}
 	}
 
 	// Register handlers
+	tb.registerBuiltinCommands()
 	tb.registerHandlers()
 
 	return tb, nil
@@ -87,51 +138,125 @@ func (t *TelegramBot) registerHandlers() {
 	// Command handlers
 	t.bot.Handle("/start", t.authMiddleware(t.HandleStart))
 	t.bot.Handle("/help", t.authMiddleware(t.HandleHelp))
+	// /register is reachable by anyone, unauthorized chats included -
+	// that's the whole point of the request-access flow.
+	t.bot.Handle("/register", t.HandleRegister)
+
+	// Built-in commands registered via registerBuiltinCommands, so adding a
+	// new one never touches this loop.
+	for _, cmd := range t.commands.All() {
+		cmd := cmd
+		t.bot.Handle("/"+cmd.Name, t.requireRole(cmd.MinRole, func(c tele.Context) error {
+			return cmd.Handler(c, c.Args())
+		}))
+	}
 
 	// Text handler for state-based input
 	t.bot.Handle(tele.OnText, t.authMiddleware(t.HandleText))
+	// Document handler for OPML/JSON bulk-subscribe uploads
+	t.bot.Handle(tele.OnDocument, t.requireRole(repository.BotRoleSubscriber, t.HandleDocumentUpload))
 
 	// Callback handlers
 	t.bot.Handle(&tele.InlineButton{Unique: "tasks"}, t.authMiddleware(t.HandleTasksCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "subscribe"}, t.authMiddleware(t.HandleSubscribeCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "subscribe"}, t.requireRole(repository.BotRoleSubscriber, t.HandleSubscribeCallback))
 	t.bot.Handle(&tele.InlineButton{Unique: "organize"}, t.authMiddleware(t.HandleOrganizeCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "sync"}, t.authMiddleware(t.HandleSyncCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "admin"}, t.authMiddleware(t.HandleAdminCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "apikey"}, t.authMiddleware(t.HandleAPIKeyCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "backup"}, t.authMiddleware(t.HandleBackupCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "sync"}, t.requireRole(repository.BotRoleSubscriber, t.requireChannelAdmin(t.HandleSyncCallback)))
+	t.bot.Handle(&tele.InlineButton{Unique: "admin"}, t.requireRole(repository.BotRoleOwner, t.HandleAdminCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "apikey"}, t.requireRole(repository.BotRoleOwner, t.HandleAPIKeyCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "backup"}, t.requireRole(repository.BotRoleOwner, t.HandleBackupCallback))
 	t.bot.Handle(&tele.InlineButton{Unique: "back"}, t.authMiddleware(t.HandleBackCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "complete"}, t.authMiddleware(t.HandleCompleteTaskCallback))
-	t.bot.Handle(&tele.InlineButton{Unique: "archive"}, t.authMiddleware(t.HandleArchiveCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "complete"}, t.requireRole(repository.BotRoleSubscriber, t.requireChannelAdmin(t.HandleCompleteTaskCallback)))
+	t.bot.Handle(&tele.InlineButton{Unique: "archive"}, t.requireRole(repository.BotRoleSubscriber, t.requireChannelAdmin(t.HandleArchiveCallback)))
+	t.bot.Handle(&tele.InlineButton{Unique: "approve"}, t.requireRole(repository.BotRoleOwner, t.HandleApproveRegistration))
+	t.bot.Handle(&tele.InlineButton{Unique: "deny"}, t.requireRole(repository.BotRoleOwner, t.HandleDenyRegistration))
+	t.bot.Handle(&tele.InlineButton{Unique: "searchsub"}, t.requireRole(repository.BotRoleSubscriber, t.HandleSearchSubscribeCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "tmdbsearch"}, t.requireRole(repository.BotRoleSubscriber, t.HandleTMDBSearchPageCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "tmdbsub"}, t.requireRole(repository.BotRoleSubscriber, t.HandleTMDBSubscribeCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "mysubs"}, t.requireRole(repository.BotRoleSubscriber, t.HandleMySubscriptionsCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "showsettings"}, t.requireRole(repository.BotRoleSubscriber, t.HandleShowSettingsCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "showpref"}, t.requireRole(repository.BotRoleSubscriber, t.HandleShowPrefCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "pushdl"}, t.requireRole(repository.BotRoleSubscriber, t.HandlePushToDownloaderCallback))
+	t.bot.Handle(&tele.InlineButton{Unique: "export"}, t.requireRole(repository.BotRoleOwner, t.HandleExportCallback))
 }
 
+// resolveRole returns chatID's authorization level. With no userRepo
+// configured, it falls back to the old single-admin behavior: the
+// configured chat ID is BotRoleOwner and every other chat is unauthorized
+// (the zero BotRole, which roleRank ranks 0, the same as BotRolePending).
+func (t *TelegramBot) resolveRole(chatID int64) repository.BotRole {
+	if t.userRepo == nil {
+		if chatID == t.chatID {
+			return repository.BotRoleOwner
+		}
+		return ""
+	}
+
+	user, err := t.userRepo.Get(chatID)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.Role
+}
 
-// authMiddleware checks if the user is authorized
+// authMiddleware checks if the user is authorized at all (any registered
+// role above BotRolePending). Use requireRole instead for handlers that
+// need at least a specific role.
 func (t *TelegramBot) authMiddleware(next tele.HandlerFunc) tele.HandlerFunc {
+	return t.requireRole(repository.BotRoleReadOnly, next)
+}
+
+// requireRole rejects the update unless the calling chat's resolved role
+// ranks at or above min.
+func (t *TelegramBot) requireRole(min repository.BotRole, next tele.HandlerFunc) tele.HandlerFunc {
 	return func(c tele.Context) error {
-		if !t.IsOwner(c.Chat().ID) {
-			return c.Send("⛔ 未授权访问")
+		role := t.resolveRole(c.Chat().ID)
+		if roleRank[role] < roleRank[min] {
+			return c.Send("⛔ 未授权访问，使用 /register 申请")
+		}
+		return next(c)
+	}
+}
+
+// requireChannelAdmin additionally guards a destructive callback
+// (complete/archive/sync) when the effective chat is a Telegram channel:
+// the bot's own allow-list isn't enough there, since anyone who can see the
+// channel's inline buttons could tap them, so the caller must also be a
+// channel administrator. Non-channel chats (private chats, groups) are
+// unaffected and fall through to whatever requireRole already checked.
+func (t *TelegramBot) requireChannelAdmin(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		chat := c.Chat()
+		if chat.Type != tele.ChatChannel && chat.Type != tele.ChatChannelPrivate {
+			return next(c)
+		}
+
+		fresh, err := t.bot.ChatByID(chat.ID)
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "无法验证频道身份"})
+		}
+
+		member, err := t.bot.ChatMemberOf(fresh, c.Sender())
+		if err != nil || (member.Role != tele.Administrator && member.Role != tele.Creator) {
+			return c.Respond(&tele.CallbackResponse{Text: "⛔ 仅频道管理员可执行此操作"})
 		}
+
 		return next(c)
 	}
 }
 
-// IsOwner checks if the chat ID matches the configured owner
+// IsOwner checks if the chat ID is authorized as BotRoleOwner.
 func (t *TelegramBot) IsOwner(chatID int64) bool {
-	return chatID == t.chatID
+	return t.resolveRole(chatID) == repository.BotRoleOwner
 }
 
-// setState sets the current bot state
-func (t *TelegramBot) setState(state BotState) {
-	t.stateMu.Lock()
-	defer t.stateMu.Unlock()
-	t.state = state
+// setState sets chatID's conversation step.
+func (t *TelegramBot) setState(chatID int64, state BotState) {
+	t.convState.set(chatID, state)
 }
 
-// getState gets the current bot state
-func (t *TelegramBot) getState() BotState {
-	t.stateMu.RLock()
-	defer t.stateMu.RUnlock()
-	return t.state
+// getState gets chatID's conversation step.
+func (t *TelegramBot) getState(chatID int64) BotState {
+	return t.convState.get(chatID)
 }
 
 // Start starts the bot
@@ -147,39 +272,54 @@ func (t *TelegramBot) Stop() {
 
 // HandleStart handles the /start command
 func (t *TelegramBot) HandleStart(c tele.Context) error {
-	t.setState(StateIdle)
+	t.setState(c.Chat().ID, StateIdle)
 	return c.Send(t.FormatMainMenu(), t.MainMenuKeyboard())
 }
 
-// HandleHelp handles the /help command
+// HandleHelp handles the /help command. The command list is generated from
+// t.commands so a newly registered command shows up here automatically.
 func (t *TelegramBot) HandleHelp(c tele.Context) error {
-	help := `📺 <b>TV Tracker 帮助</b>
+	var sb strings.Builder
+	sb.WriteString(`📺 <b>TV Tracker 帮助</b>
 
 <b>功能说明：</b>
 • 📺 今日更新 - 查看今日需要更新的剧集
 • ➕ 订阅剧集 - 通过 TMDB ID 订阅新剧集
+• 🔍 搜索订阅 - 按剧名搜索 TMDB 并订阅
+• 📚 我的订阅 - 查看订阅并调整单剧设置
 • 📦 待整理 - 查看已完结待归档的剧集
 • 🔄 同步更新 - 同步所有订阅数据
 • ⚙️ 管理 - 系统管理和设置
+`)
+
+	if cmds := t.commands.All(); len(cmds) > 0 {
+		sb.WriteString("\n<b>命令列表：</b>\n")
+		for _, cmd := range cmds {
+			sb.WriteString(fmt.Sprintf("/%s - %s\n", cmd.Name, cmd.Description))
+		}
+	}
 
+	sb.WriteString(`
 <b>如何获取 TMDB ID：</b>
 1. 访问 themoviedb.org
 2. 搜索剧集
 3. URL 中的数字即为 TMDB ID
-   例如: /tv/1399 中的 1399`
+   例如: /tv/1399 中的 1399`)
 
-	return c.Send(help, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
+	return c.Send(sb.String(), &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
 }
 
-// HandleText handles text input based on current state
+// HandleText handles text input based on the calling chat's conversation state
 func (t *TelegramBot) HandleText(c tele.Context) error {
-	state := t.getState()
+	state := t.getState(c.Chat().ID)
 
 	switch state {
 	case StateWaitingTMDBID:
 		return t.handleTMDBIDInput(c)
 	case StateWaitingAPIKey:
 		return t.handleAPIKeyInput(c)
+	case StateWaitingSearchQuery:
+		return t.handleSearchQueryInput(c)
 	default:
 		return c.Send("请使用 /start 打开主菜单")
 	}
@@ -187,15 +327,16 @@ func (t *TelegramBot) HandleText(c tele.Context) error {
 
 // handleTMDBIDInput handles TMDB ID input
 func (t *TelegramBot) handleTMDBIDInput(c tele.Context) error {
-	t.setState(StateIdle)
+	t.setState(c.Chat().ID, StateIdle)
 
 	tmdbID, err := strconv.Atoi(strings.TrimSpace(c.Text()))
 	if err != nil {
 		return c.Send("❌ 无效的 TMDB ID，请输入数字", t.BackButtonKeyboard())
 	}
 
-	// Subscribe to the show
-	show, alreadyExists, err := t.subMgr.Subscribe(tmdbID)
+	// Subscribe to the show on behalf of the calling chat, so
+	// GetSubscriptionsFor later scopes the per-user daily report correctly.
+	show, alreadyExists, err := t.subMgr.SubscribeFor(c.Chat().ID, tmdbID)
 	if err != nil {
 		return c.Send(fmt.Sprintf("❌ 订阅失败: %v", err), t.BackButtonKeyboard())
 	}
@@ -215,23 +356,66 @@ func (t *TelegramBot) handleTMDBIDInput(c tele.Context) error {
 状态: %s
 资源时间: %s`, show.Name, show.Status, show.ResourceTime)
 
+	if t.downloader != nil {
+		return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.PushToDownloaderKeyboard(show.ID))
+	}
 	return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
 }
 
-// handleAPIKeyInput handles API key input
-func (t *TelegramBot) handleAPIKeyInput(c tele.Context) error {
-	t.setState(StateIdle)
-	// Note: In a real implementation, you would update the TMDB client's API key
-	// For now, we just acknowledge the input
-	return c.Send("✅ TMDB API Key 已更新\n\n⚠️ 注意：需要重启服务才能生效", t.BackButtonKeyboard())
+// handleSearchQueryInput handles the title typed after "🔍 搜索订阅" or
+// bare "/search" prompts for one, rendering the first page of TMDB results.
+func (t *TelegramBot) handleSearchQueryInput(c tele.Context) error {
+	t.setState(c.Chat().ID, StateIdle)
+
+	query := strings.TrimSpace(c.Text())
+	if query == "" {
+		return c.Send("❌ 请输入剧名", t.BackButtonKeyboard())
+	}
+
+	return t.sendTMDBSearchResults(c, query, 1)
 }
 
+// sendTMDBSearchResults runs a TMDB search and sends its results as a new
+// message, used for text-input-driven searches (no prior message to edit).
+func (t *TelegramBot) sendTMDBSearchResults(c tele.Context, query string, page int) error {
+	results, totalPages, err := t.tmdb.SearchTVPage(query, page)
+	if err != nil {
+		return c.Send(fmt.Sprintf("❌ 搜索失败: %v", err), t.BackButtonKeyboard())
+	}
+	if len(results) == 0 {
+		return c.Send("未找到匹配的剧集", t.BackButtonKeyboard())
+	}
+
+	msg := FormatSearchResults(query, results)
+	return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.SearchResultsKeyboard(query, page, totalPages, results))
+}
+
+// handleAPIKeyInput validates the submitted key against TMDB and, on
+// success, hot-swaps it into t.tmdb and persists it via settingsRepo so it
+// survives a restart - no service restart needed either way.
+func (t *TelegramBot) handleAPIKeyInput(c tele.Context) error {
+	t.setState(c.Chat().ID, StateIdle)
+
+	key := strings.TrimSpace(c.Text())
+	if err := t.tmdb.SetAPIKey(key); err != nil {
+		return c.Send(fmt.Sprintf("❌ API Key 校验失败：%v", err), t.BackButtonKeyboard())
+	}
+
+	if t.settingsRepo != nil {
+		if err := t.settingsRepo.Set(repository.SettingKeyTMDBAPIKey, key); err != nil {
+			log.Printf("failed to persist TMDB API key: %v", err)
+			return c.Send("✅ TMDB API Key 已校验并生效\n\n⚠️ 保存失败，重启后需要重新设置", t.BackButtonKeyboard())
+		}
+	}
+
+	return c.Send("✅ TMDB API Key 已校验并生效，无需重启", t.BackButtonKeyboard())
+}
 
 // HandleTasksCallback handles the "今日更新" button
 func (t *TelegramBot) HandleTasksCallback(c tele.Context) error {
 	// 获取今天的日期
 	today := time.Now().Format("2006-01-02")
-	
+
 	// 查询今天播出的剧集
 	episodes, err := t.episodeRepo.GetTodayEpisodesWithShowInfo(today)
 	if err != nil {
@@ -248,7 +432,7 @@ func (t *TelegramBot) HandleTasksCallback(c tele.Context) error {
 
 // HandleSubscribeCallback handles the "订阅剧集" button
 func (t *TelegramBot) HandleSubscribeCallback(c tele.Context) error {
-	t.setState(StateWaitingTMDBID)
+	t.setState(c.Chat().ID, StateWaitingTMDBID)
 	return c.Edit("➕ <b>订阅剧集</b>\n\n请输入 TMDB ID（可在 themoviedb.org 查询）:", &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
 }
 
@@ -307,7 +491,7 @@ func (t *TelegramBot) HandleAdminCallback(c tele.Context) error {
 
 // HandleAPIKeyCallback handles the "更换TMDB API" button
 func (t *TelegramBot) HandleAPIKeyCallback(c tele.Context) error {
-	t.setState(StateWaitingAPIKey)
+	t.setState(c.Chat().ID, StateWaitingAPIKey)
 	return c.Edit("🔑 <b>更换 TMDB API Key</b>\n\n请输入新的 API Key:", &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
 }
 
@@ -327,13 +511,64 @@ func (t *TelegramBot) HandleBackupCallback(c tele.Context) error {
 	return c.Edit(fmt.Sprintf("✅ <b>备份成功</b>\n\n文件: %s", backupPath), &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
 }
 
+// HandleExportCallback handles the "📤 导出订阅" button, sending every
+// subscribed show back as an OPML document re-importable via
+// HandleDocumentUpload.
+func (t *TelegramBot) HandleExportCallback(c tele.Context) error {
+	if t.importExport == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "导入导出未配置"})
+	}
+
+	var buf bytes.Buffer
+	if err := t.importExport.ExportOPML(&buf); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("导出失败: %v", err)})
+	}
+
+	c.Respond(&tele.CallbackResponse{})
+	return c.Send(&tele.Document{File: tele.FromReader(&buf), FileName: "subscriptions.opml"})
+}
+
+// HandleDocumentUpload handles an uploaded OPML/JSON document (see
+// registerHandlers' tele.OnDocument handler), bulk-subscribing to every
+// TMDB ID it lists and replying with a summary via FormatImportReport.
+func (t *TelegramBot) HandleDocumentUpload(c tele.Context) error {
+	if t.importExport == nil {
+		return nil
+	}
+
+	doc := c.Message().Document
+	if doc == nil {
+		return nil
+	}
+
+	progress, err := c.Bot().Send(c.Chat(), "⏳ 正在导入订阅...")
+	if err != nil {
+		return err
+	}
+
+	reader, err := c.Bot().File(&doc.File)
+	if err != nil {
+		_, err := c.Bot().Edit(progress, fmt.Sprintf("❌ 下载文件失败: %v", err))
+		return err
+	}
+	defer reader.Close()
+
+	report, err := t.importExport.ImportOPML(reader)
+	if err != nil {
+		_, err := c.Bot().Edit(progress, fmt.Sprintf("❌ 导入失败: %v", err))
+		return err
+	}
+
+	_, err = c.Bot().Edit(progress, FormatImportReport(report), &tele.SendOptions{ParseMode: tele.ModeHTML})
+	return err
+}
+
 // HandleBackCallback handles the "返回主菜单" button
 func (t *TelegramBot) HandleBackCallback(c tele.Context) error {
-	t.setState(StateIdle)
+	t.setState(c.Chat().ID, StateIdle)
 	return c.Edit(t.FormatMainMenu(), &tele.SendOptions{ParseMode: tele.ModeHTML}, t.MainMenuKeyboard())
 }
 
-
 // HandleCompleteTaskCallback handles the "已完成" button for UPDATE tasks
 func (t *TelegramBot) HandleCompleteTaskCallback(c tele.Context) error {
 	// Parse task ID from callback data
@@ -354,6 +589,32 @@ func (t *TelegramBot) HandleCompleteTaskCallback(c tele.Context) error {
 	return t.HandleTasksCallback(c)
 }
 
+// HandlePushToDownloaderCallback handles the "📥 推送到下载器" button shown
+// beside each task in TaskListKeyboard and after a fresh subscribe in
+// handleTMDBIDInput, pushing the show to whichever media manager is
+// configured as t.downloader. HandleCompleteTaskCallback does not offer it.
+func (t *TelegramBot) HandlePushToDownloaderCallback(c tele.Context) error {
+	if t.downloader == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "下载器未配置"})
+	}
+
+	showID, err := strconv.ParseInt(c.Callback().Data, 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的剧集ID"})
+	}
+
+	show, err := t.subMgr.GetShow(showID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "未找到该剧集"})
+	}
+
+	if err := t.downloader.PushSeries(show.TMDBID, show.Name); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("推送到 %s 失败: %v", t.downloader.Name(), err)})
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("✅ 已推送到 %s", t.downloader.Name())})
+}
+
 // HandleArchiveCallback handles the "已归档" button for ORGANIZE tasks
 func (t *TelegramBot) HandleArchiveCallback(c tele.Context) error {
 	// Parse task ID from callback data
@@ -374,6 +635,397 @@ func (t *TelegramBot) HandleArchiveCallback(c tele.Context) error {
 	return t.HandleOrganizeCallback(c)
 }
 
+// HandleMySubscriptionsCallback handles the "我的订阅" button, listing the
+// calling chat's own subscriptions with a settings button per show.
+func (t *TelegramBot) HandleMySubscriptionsCallback(c tele.Context) error {
+	if t.prefRepo == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "设置功能未启用"})
+	}
+
+	chatID := c.Chat().ID
+	shows, err := t.subMgr.GetSubscriptionsFor(chatID)
+	if err != nil {
+		// Per-chat scoping isn't configured: fall back to the whole library,
+		// same as FormatSubscriptionList's other callers.
+		shows, err = t.subMgr.GetAllSubscriptions()
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "获取订阅列表失败"})
+		}
+	}
+
+	msg := t.FormatSubscriptionList(shows)
+	return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.SubscriptionListKeyboard(shows))
+}
+
+// HandleShowSettingsCallback handles tapping a show in the subscription
+// list, opening its settings submenu.
+func (t *TelegramBot) HandleShowSettingsCallback(c tele.Context) error {
+	showID, err := strconv.ParseInt(strings.TrimSpace(c.Callback().Data), 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的剧集ID"})
+	}
+	return t.renderShowSettings(c, showID)
+}
+
+// HandleShowPrefCallback handles a tap on the settings submenu: it decodes
+// the "<showID>:<action>" composite callback data, mutates the matching
+// ShowPreferences field, then rebuilds the same message in place so the
+// toggled button's label flips.
+func (t *TelegramBot) HandleShowPrefCallback(c tele.Context) error {
+	if t.prefRepo == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "设置功能未启用"})
+	}
+
+	showID, action, err := parseShowPrefData(c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的操作"})
+	}
+
+	chatID := c.Chat().ID
+	switch action {
+	case "notify":
+		if _, err := t.prefRepo.ToggleNotify(chatID, showID); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "操作失败"})
+		}
+	case "report":
+		if _, err := t.prefRepo.ToggleDailyReport(chatID, showID); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "操作失败"})
+		}
+	case "resourcetime":
+		if _, err := t.prefRepo.CycleResourceTimeOverride(chatID, showID); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "操作失败"})
+		}
+	case "language":
+		if _, err := t.prefRepo.CycleTitleLanguage(chatID, showID); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "操作失败"})
+		}
+	case "unsubscribe":
+		if err := t.subMgr.UnsubscribeFor(chatID, showID); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("取消订阅失败: %v", err)})
+		}
+		if err := t.prefRepo.Delete(chatID, showID); err != nil {
+			log.Printf("failed to clear preferences for chat %d show %d: %v", chatID, showID, err)
+		}
+		c.Respond(&tele.CallbackResponse{Text: "已取消订阅"})
+		return t.HandleMySubscriptionsCallback(c)
+	default:
+		return c.Respond(&tele.CallbackResponse{Text: "未知操作"})
+	}
+
+	return t.renderShowSettings(c, showID)
+}
+
+// renderShowSettings edits the current message in place to show showID's
+// settings submenu.
+func (t *TelegramBot) renderShowSettings(c tele.Context, showID int64) error {
+	show, err := t.subMgr.GetShow(showID)
+	if err != nil || show == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "剧集不存在"})
+	}
+
+	prefs, err := t.prefRepo.Get(c.Chat().ID, showID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "获取设置失败"})
+	}
+
+	msg := t.FormatShowSettings(*show, prefs)
+	return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.ShowSettingsKeyboard(showID, prefs))
+}
+
+// HandleRegister handles the /register command: an unauthorized chat
+// requests access, and the owner chat is notified with inline
+// approve/deny buttons carrying the requester's chat ID.
+func (t *TelegramBot) HandleRegister(c tele.Context) error {
+	if t.userRepo == nil {
+		return c.Send("⚠️ 当前未启用多用户授权，仅限管理员使用")
+	}
+
+	chatID := c.Chat().ID
+	existing, err := t.userRepo.Get(chatID)
+	if err != nil {
+		return c.Send("❌ 查询失败，请稍后重试")
+	}
+	if existing != nil {
+		if existing.Role == repository.BotRolePending {
+			return c.Send("⏳ 您的申请正在等待管理员审批")
+		}
+		return c.Send("✅ 您已拥有访问权限，使用 /start 打开菜单")
+	}
+
+	username := ""
+	if c.Sender() != nil {
+		username = c.Sender().Username
+	}
+	if err := t.userRepo.Upsert(chatID, username, repository.BotRolePending); err != nil {
+		return c.Send("❌ 申请失败，请稍后重试")
+	}
+
+	menu := &tele.ReplyMarkup{}
+	btnApprove := menu.Data("✅ 批准", "approve", strconv.FormatInt(chatID, 10))
+	btnDeny := menu.Data("❌ 拒绝", "deny", strconv.FormatInt(chatID, 10))
+	menu.Inline(menu.Row(btnApprove, btnDeny))
+
+	notice := fmt.Sprintf("📩 <b>新用户申请</b>\n\n用户: %s\nChat ID: %d", username, chatID)
+	if _, err := t.bot.Send(&tele.Chat{ID: t.chatID}, notice, &tele.SendOptions{ParseMode: tele.ModeHTML}, menu); err != nil {
+		log.Printf("failed to notify owner of registration request from %d: %v", chatID, err)
+	}
+
+	return c.Send("📨 已提交申请，请等待管理员审批")
+}
+
+// HandleApproveRegistration handles the owner's "批准" button, promoting
+// the requester encoded in the callback data to BotRoleSubscriber.
+func (t *TelegramBot) HandleApproveRegistration(c tele.Context) error {
+	chatID, err := strconv.ParseInt(strings.TrimSpace(c.Callback().Data), 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的请求"})
+	}
+
+	if err := t.userRepo.Upsert(chatID, "", repository.BotRoleSubscriber); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "批准失败"})
+	}
+
+	if _, err := t.bot.Send(&tele.Chat{ID: chatID}, "✅ 您的申请已通过，使用 /start 打开菜单"); err != nil {
+		log.Printf("failed to notify approved chat %d: %v", chatID, err)
+	}
+
+	return c.Edit(fmt.Sprintf("✅ 已批准 Chat ID %d", chatID))
+}
+
+// HandleDenyRegistration handles the owner's "拒绝" button, dropping the
+// pending request entirely rather than leaving it authorized at any role.
+func (t *TelegramBot) HandleDenyRegistration(c tele.Context) error {
+	chatID, err := strconv.ParseInt(strings.TrimSpace(c.Callback().Data), 10, 64)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的请求"})
+	}
+
+	if err := t.userRepo.Delete(chatID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "操作失败"})
+	}
+
+	return c.Edit(fmt.Sprintf("❌ 已拒绝 Chat ID %d", chatID))
+}
+
+// registerBuiltinCommands registers the bot's typed slash commands with
+// t.commands; registerHandlers installs them and HandleHelp lists them.
+func (t *TelegramBot) registerBuiltinCommands() {
+	t.commands.Register(CommandHandler{
+		Name:        "subscribe",
+		Description: "订阅剧集: /subscribe <TMDB ID>",
+		MinRole:     repository.BotRoleSubscriber,
+		Handler:     t.HandleSubscribeCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "unsubscribe",
+		Description: "取消订阅: /unsubscribe <TMDB ID>",
+		MinRole:     repository.BotRoleSubscriber,
+		Handler:     t.HandleUnsubscribeCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "searchsubs",
+		Description: "搜索已订阅剧集: /searchsubs <关键词>",
+		MinRole:     repository.BotRoleReadOnly,
+		Handler:     t.HandleSearchCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "search",
+		Description: "按 TMDB 搜索并订阅新剧集: /search <剧名>",
+		MinRole:     repository.BotRoleSubscriber,
+		Handler:     t.HandleTMDBSearchCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "list",
+		Description: "查看我的订阅",
+		MinRole:     repository.BotRoleSubscriber,
+		Handler:     t.HandleListCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "today",
+		Description: "查看今日更新",
+		MinRole:     repository.BotRoleReadOnly,
+		Handler:     t.HandleTodayCommand,
+	})
+	t.commands.Register(CommandHandler{
+		Name:        "backup",
+		Description: "手动备份数据库",
+		MinRole:     repository.BotRoleOwner,
+		Handler:     t.HandleBackupCommand,
+	})
+}
+
+// HandleSubscribeCommand implements "/subscribe <TMDB ID>".
+func (t *TelegramBot) HandleSubscribeCommand(c tele.Context, args []string) error {
+	if len(args) == 0 {
+		return c.Send("用法: /subscribe <TMDB ID>")
+	}
+	tmdbID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Send("❌ 无效的 TMDB ID，请输入数字")
+	}
+
+	show, alreadyExists, err := t.subMgr.SubscribeFor(c.Chat().ID, tmdbID)
+	if err != nil {
+		return c.Send(fmt.Sprintf("❌ 订阅失败: %v", err))
+	}
+
+	if alreadyExists {
+		msg := fmt.Sprintf("⚠️ <b>该剧集已订阅</b>\n\n📺 %s\n状态: %s\n资源时间: %s", show.Name, show.Status, show.ResourceTime)
+		return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+	}
+
+	msg := fmt.Sprintf("✅ <b>已订阅</b>\n\n📺 %s\n状态: %s\n资源时间: %s", show.Name, show.Status, show.ResourceTime)
+	return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+// HandleUnsubscribeCommand implements "/unsubscribe <TMDB ID>".
+func (t *TelegramBot) HandleUnsubscribeCommand(c tele.Context, args []string) error {
+	if len(args) == 0 {
+		return c.Send("用法: /unsubscribe <TMDB ID>")
+	}
+	tmdbID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Send("❌ 无效的 TMDB ID，请输入数字")
+	}
+
+	if err := t.subMgr.UnsubscribeByTMDBID(c.Chat().ID, tmdbID); err != nil {
+		return c.Send(fmt.Sprintf("❌ 取消订阅失败: %v", err))
+	}
+	return c.Send("✅ 已取消订阅")
+}
+
+// HandleSearchCommand implements "/searchsubs <关键词>".
+func (t *TelegramBot) HandleSearchCommand(c tele.Context, args []string) error {
+	if len(args) == 0 {
+		return c.Send("用法: /searchsubs <关键词>")
+	}
+
+	query := strings.Join(args, " ")
+	shows, err := t.subMgr.Search(query)
+	if err != nil {
+		return c.Send(fmt.Sprintf("❌ 搜索失败: %v", err))
+	}
+	if len(shows) == 0 {
+		return c.Send("未找到匹配的剧集")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<b>🔍 搜索结果: %s</b>\n\n", query))
+	for i, show := range shows {
+		sb.WriteString(fmt.Sprintf("%d. %s (TMDB: %d)\n", i+1, show.Name, show.TMDBID))
+	}
+	return c.Send(sb.String(), &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+// HandleListCommand implements "/list", the typed-command equivalent of the
+// "我的订阅" button.
+func (t *TelegramBot) HandleListCommand(c tele.Context, args []string) error {
+	chatID := c.Chat().ID
+	shows, err := t.subMgr.GetSubscriptionsFor(chatID)
+	if err != nil {
+		// Per-chat scoping isn't configured: fall back to the whole library,
+		// same as FormatSubscriptionList's other callers.
+		shows, err = t.subMgr.GetAllSubscriptions()
+		if err != nil {
+			return c.Send("❌ 获取订阅列表失败")
+		}
+	}
+
+	msg := t.FormatSubscriptionList(shows)
+	if t.prefRepo != nil {
+		return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.SubscriptionListKeyboard(shows))
+	}
+	return c.Send(msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+// HandleTodayCommand implements "/today", the typed-command equivalent of
+// the "今日更新" button.
+func (t *TelegramBot) HandleTodayCommand(c tele.Context, args []string) error {
+	today := time.Now().Format("2006-01-02")
+	episodes, err := t.episodeRepo.GetTodayEpisodesWithShowInfo(today)
+	if err != nil {
+		return c.Send("❌ 获取数据失败")
+	}
+	if len(episodes) == 0 {
+		return c.Send("📺 <b>今日更新</b>\n\n今日暂无剧集更新 🎬", &tele.SendOptions{ParseMode: tele.ModeHTML})
+	}
+	return c.Send(t.FormatTodayEpisodes(episodes), &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+// HandleBackupCommand implements "/backup", the typed-command equivalent of
+// the "手动备份" button.
+func (t *TelegramBot) HandleBackupCommand(c tele.Context, args []string) error {
+	if t.backupSvc == nil {
+		return c.Send("❌ 备份服务未配置")
+	}
+
+	backupPath, err := t.backupSvc.Backup()
+	if err != nil {
+		return c.Send(fmt.Sprintf("❌ 备份失败: %v", err))
+	}
+	return c.Send(fmt.Sprintf("✅ <b>备份成功</b>\n\n文件: %s", backupPath), &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+// HandleTMDBSearchCommand implements "/search <剧名>": with no args it
+// prompts for a title the same way the "🔍 搜索订阅" button does, otherwise
+// it searches TMDB directly.
+func (t *TelegramBot) HandleTMDBSearchCommand(c tele.Context, args []string) error {
+	if len(args) == 0 {
+		t.setState(c.Chat().ID, StateWaitingSearchQuery)
+		return c.Send("🔍 <b>搜索订阅</b>\n\n请输入剧名:", &tele.SendOptions{ParseMode: tele.ModeHTML})
+	}
+	return t.sendTMDBSearchResults(c, strings.Join(args, " "), 1)
+}
+
+// HandleSearchSubscribeCallback handles the "🔍 搜索订阅" main-menu button.
+func (t *TelegramBot) HandleSearchSubscribeCallback(c tele.Context) error {
+	t.setState(c.Chat().ID, StateWaitingSearchQuery)
+	return c.Edit("🔍 <b>搜索订阅</b>\n\n请输入剧名:", &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
+}
+
+// HandleTMDBSearchPageCallback handles the "◀️ 上一页 / 下一页 ▶️" buttons,
+// decoding the "<query>:<page>" composite callback data and re-editing the
+// same message with the requested page.
+func (t *TelegramBot) HandleTMDBSearchPageCallback(c tele.Context) error {
+	query, page, err := parseTMDBSearchData(c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的分页请求"})
+	}
+
+	results, totalPages, err := t.tmdb.SearchTVPage(query, page)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "搜索失败"})
+	}
+	if len(results) == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "没有更多结果"})
+	}
+
+	msg := FormatSearchResults(query, results)
+	return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.SearchResultsKeyboard(query, page, totalPages, results))
+}
+
+// HandleTMDBSubscribeCallback handles tapping a search result, subscribing
+// the calling chat to the tapped show and editing the message to confirm.
+func (t *TelegramBot) HandleTMDBSubscribeCallback(c tele.Context) error {
+	tmdbID, err := strconv.Atoi(strings.TrimSpace(c.Callback().Data))
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "无效的剧集ID"})
+	}
+
+	show, alreadyExists, err := t.subMgr.SubscribeFor(c.Chat().ID, tmdbID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("订阅失败: %v", err)})
+	}
+
+	if alreadyExists {
+		msg := fmt.Sprintf("⚠️ <b>该剧集已订阅</b>\n\n📺 %s\n状态: %s\n资源时间: %s", show.Name, show.Status, show.ResourceTime)
+		return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
+	}
+
+	msg := fmt.Sprintf("✅ <b>已订阅</b>\n\n📺 %s\n状态: %s\n资源时间: %s", show.Name, show.Status, show.ResourceTime)
+	return c.Edit(msg, &tele.SendOptions{ParseMode: tele.ModeHTML}, t.BackButtonKeyboard())
+}
+
 // FormatMainMenu formats the main menu message
 func (t *TelegramBot) FormatMainMenu() string {
 	return "📺 <b>TV Tracker</b>\n\n选择一个功能:"
@@ -453,6 +1105,76 @@ func (t *TelegramBot) FormatSubscriptionList(shows []models.TVShow) string {
 	return sb.String()
 }
 
+// FormatSearchResults formats a page of TMDB search results for the
+// "🔍 搜索订阅" flow: one row per show with its name and first-air-year.
+func FormatSearchResults(query string, results []tmdb.SearchResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<b>🔍 搜索结果: %s</b>\n\n", query))
+
+	for i, result := range results {
+		year := "未知"
+		if len(result.FirstAirDate) >= 4 {
+			year = result.FirstAirDate[:4]
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, result.Name, year))
+	}
+
+	return sb.String()
+}
+
+// FormatImportReport formats the outcome of an OPML/JSON bulk-subscribe
+// upload (see HandleDocumentUpload) into a summary message, listing each
+// failed TMDB ID so the uploader can fix and retry just those.
+func FormatImportReport(report service.ImportReport) string {
+	var sb strings.Builder
+	sb.WriteString("📥 <b>导入完成</b>\n\n")
+	sb.WriteString(fmt.Sprintf("✅ 成功: %d\n", report.Succeeded))
+	sb.WriteString(fmt.Sprintf("❌ 失败: %d\n", report.Failed))
+
+	if report.Failed > 0 {
+		sb.WriteString("\n失败详情:\n")
+		for _, result := range report.Results {
+			if result.Error != "" {
+				sb.WriteString(fmt.Sprintf("· tmdb:%d - %s\n", result.TMDBID, result.Error))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// FormatShowSettings formats a single show's settings submenu message.
+func (t *TelegramBot) FormatShowSettings(show models.TVShow, prefs repository.ShowPreferences) string {
+	resourceTime := prefs.ResourceTimeOverride
+	if resourceTime == "" {
+		resourceTime = show.ResourceTime + " (默认)"
+	}
+	titleLanguage := prefs.TitleLanguage
+	if titleLanguage == "" {
+		titleLanguage = "跟随剧集原始语言"
+	}
+
+	return fmt.Sprintf(`⚙️ <b>%s</b> 设置
+
+通知: %s
+日报: %s
+资源时间: %s
+标题语言: %s`,
+		show.Name,
+		boolLabel(prefs.NotifyEnabled, "🔔 已开启", "🔕 已关闭"),
+		boolLabel(prefs.IncludeInDailyReport, "✅ 已加入", "➖ 未加入"),
+		resourceTime,
+		titleLanguage,
+	)
+}
+
+func boolLabel(v bool, onLabel, offLabel string) string {
+	if v {
+		return onLabel
+	}
+	return offLabel
+}
+
 // FormatAdminMenu formats the admin menu message
 func (t *TelegramBot) FormatAdminMenu() string {
 	var sb strings.Builder
@@ -480,7 +1202,6 @@ func (t *TelegramBot) FormatAdminMenu() string {
 	return sb.String()
 }
 
-
 // FormatDailyReport formats the daily report message
 func (t *TelegramBot) FormatDailyReport(tasks []models.Task) string {
 	return FormatDailyReport(tasks)
@@ -540,12 +1261,15 @@ func (t *TelegramBot) MainMenuKeyboard() *tele.ReplyMarkup {
 
 	btnTasks := menu.Data("📺 今日更新", "tasks")
 	btnSubscribe := menu.Data("➕ 订阅剧集", "subscribe")
+	btnSearchSub := menu.Data("🔍 搜索订阅", "searchsub")
+	btnMySubs := menu.Data("📚 我的订阅", "mysubs")
 	btnOrganize := menu.Data("📦 待整理", "organize")
 	btnSync := menu.Data("🔄 同步更新", "sync")
 	btnAdmin := menu.Data("⚙️ 管理", "admin")
 
 	menu.Inline(
 		menu.Row(btnTasks, btnSubscribe),
+		menu.Row(btnSearchSub, btnMySubs),
 		menu.Row(btnOrganize, btnSync),
 		menu.Row(btnAdmin),
 	)
@@ -559,10 +1283,12 @@ func (t *TelegramBot) AdminMenuKeyboard() *tele.ReplyMarkup {
 
 	btnAPIKey := menu.Data("🔑 更换TMDB API", "apikey")
 	btnBackup := menu.Data("💾 手动备份", "backup")
+	btnExport := menu.Data("📤 导出订阅", "export")
 	btnBack := menu.Data("🔙 返回主菜单", "back")
 
 	menu.Inline(
 		menu.Row(btnAPIKey, btnBackup),
+		menu.Row(btnExport),
 		menu.Row(btnBack),
 	)
 
@@ -577,6 +1303,17 @@ func (t *TelegramBot) BackButtonKeyboard() *tele.ReplyMarkup {
 	return menu
 }
 
+// PushToDownloaderKeyboard is BackButtonKeyboard plus a "📥 推送到下载器"
+// button for showID, shown after a fresh subscribe when t.downloader is
+// configured.
+func (t *TelegramBot) PushToDownloaderKeyboard(showID int64) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+	btnPush := menu.Data("📥 推送到下载器", "pushdl", strconv.FormatInt(showID, 10))
+	btnBack := menu.Data("🔙 返回主菜单", "back")
+	menu.Inline(menu.Row(btnPush), menu.Row(btnBack))
+	return menu
+}
+
 // TaskListKeyboard returns a keyboard for task list with complete/archive buttons
 func (t *TelegramBot) TaskListKeyboard(tasks []models.Task, action string) *tele.ReplyMarkup {
 	menu := &tele.ReplyMarkup{}
@@ -589,6 +1326,11 @@ func (t *TelegramBot) TaskListKeyboard(tasks []models.Task, action string) *tele
 		} else {
 			btn = menu.Data(fmt.Sprintf("✅ 归档 %s", task.TVShowName), action, strconv.FormatInt(task.ID, 10))
 		}
+		if t.downloader != nil {
+			btnPush := menu.Data("📥 推送到下载器", "pushdl", strconv.FormatInt(task.TVShowID, 10))
+			rows = append(rows, menu.Row(btn), menu.Row(btnPush))
+			continue
+		}
 		rows = append(rows, menu.Row(btn))
 	}
 
@@ -600,21 +1342,202 @@ func (t *TelegramBot) TaskListKeyboard(tasks []models.Task, action string) *tele
 	return menu
 }
 
-// SendDailyReport sends the daily report to the channel
+// SubscriptionListKeyboard returns a keyboard with one button per
+// subscribed show opening its settings submenu, used alongside
+// FormatSubscriptionList.
+func (t *TelegramBot) SubscriptionListKeyboard(shows []models.TVShow) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+	for _, show := range shows {
+		btn := menu.Data(show.Name, "showsettings", strconv.FormatInt(show.ID, 10))
+		rows = append(rows, menu.Row(btn))
+	}
+
+	btnBack := menu.Data("🔙 返回主菜单", "back")
+	rows = append(rows, menu.Row(btnBack))
+
+	menu.Inline(rows...)
+	return menu
+}
+
+// SearchResultsKeyboard returns a keyboard for one page of TMDB search
+// results: one row per show (tapping subscribes via "tmdbsub"), plus a
+// pagination row whose buttons carry "<query>:<page>" composite callback
+// data via "tmdbsearch", analogous to TaskListKeyboard.
+func (t *TelegramBot) SearchResultsKeyboard(query string, page, totalPages int, results []tmdb.SearchResult) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+	for _, result := range results {
+		year := "未知"
+		if len(result.FirstAirDate) >= 4 {
+			year = result.FirstAirDate[:4]
+		}
+		label := fmt.Sprintf("%s (%s)", result.Name, year)
+		btn := menu.Data(label, "tmdbsub", strconv.Itoa(result.ID))
+		rows = append(rows, menu.Row(btn))
+	}
+
+	var pager []tele.Btn
+	if page > 1 {
+		pager = append(pager, menu.Data("◀️ 上一页", "tmdbsearch", tmdbSearchData(query, page-1)))
+	}
+	if totalPages > page {
+		pager = append(pager, menu.Data("下一页 ▶️", "tmdbsearch", tmdbSearchData(query, page+1)))
+	}
+	if len(pager) > 0 {
+		rows = append(rows, menu.Row(pager...))
+	}
+
+	btnBack := menu.Data("🔙 返回主菜单", "back")
+	rows = append(rows, menu.Row(btnBack))
+
+	menu.Inline(rows...)
+	return menu
+}
+
+// tmdbSearchData encodes the "<query>:<page>" composite callback data
+// SearchResultsKeyboard's pagination buttons carry.
+func tmdbSearchData(query string, page int) string {
+	return fmt.Sprintf("%s:%d", query, page)
+}
+
+// parseTMDBSearchData decodes a "<query>:<page>" composite callback data
+// string back into its parts, splitting on the last colon so a colon inside
+// the query itself doesn't break the split.
+func parseTMDBSearchData(data string) (string, int, error) {
+	idx := strings.LastIndex(data, ":")
+	if idx < 0 {
+		return "", 0, fmt.Errorf("malformed search data %q", data)
+	}
+	query := data[:idx]
+	page, err := strconv.Atoi(data[idx+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid page in %q: %w", data, err)
+	}
+	return query, page, nil
+}
+
+// ShowSettingsKeyboard returns the per-show settings submenu: toggles for
+// notifications, daily-report inclusion, resource time override and title
+// language, plus unsubscribe. Each button's Data encodes "<showID>:<action>",
+// the flowerss-style toggleCtrlButtons composite callback data.
+func (t *TelegramBot) ShowSettingsKeyboard(showID int64, prefs repository.ShowPreferences) *tele.ReplyMarkup {
+	menu := &tele.ReplyMarkup{}
+
+	notifyLabel := "🔕 关闭通知"
+	if !prefs.NotifyEnabled {
+		notifyLabel = "🔔 开启通知"
+	}
+	reportLabel := "➖ 移出日报"
+	if !prefs.IncludeInDailyReport {
+		reportLabel = "✅ 加入日报"
+	}
+
+	btnNotify := menu.Data(notifyLabel, "showpref", showPrefData(showID, "notify"))
+	btnReport := menu.Data(reportLabel, "showpref", showPrefData(showID, "report"))
+	btnResourceTime := menu.Data("⏰ 切换资源时间", "showpref", showPrefData(showID, "resourcetime"))
+	btnLanguage := menu.Data("🌐 切换标题语言", "showpref", showPrefData(showID, "language"))
+	btnUnsubscribe := menu.Data("🗑 取消订阅", "showpref", showPrefData(showID, "unsubscribe"))
+	btnBack := menu.Data("🔙 返回我的订阅", "mysubs")
+
+	menu.Inline(
+		menu.Row(btnNotify, btnReport),
+		menu.Row(btnResourceTime, btnLanguage),
+		menu.Row(btnUnsubscribe),
+		menu.Row(btnBack),
+	)
+	return menu
+}
+
+// showPrefData encodes the "<showID>:<action>" composite callback data
+// ShowSettingsKeyboard's buttons carry.
+func showPrefData(showID int64, action string) string {
+	return fmt.Sprintf("%d:%s", showID, action)
+}
+
+// parseShowPrefData decodes a "<showID>:<action>" composite callback data
+// string back into its parts.
+func parseShowPrefData(data string) (int64, string, error) {
+	showIDStr, action, ok := strings.Cut(strings.TrimSpace(data), ":")
+	if !ok {
+		return 0, "", fmt.Errorf("malformed show preference data %q", data)
+	}
+	showID, err := strconv.ParseInt(showIDStr, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid show id in %q: %w", data, err)
+	}
+	return showID, action, nil
+}
+
+// SendDailyReport sends the daily report. The legacy unfiltered report
+// still goes to channelID if one's configured, and on top of that, every
+// registered bot user (see BotUserRepository) gets their own report
+// filtered down to GetSubscriptionsFor's per-chat scope, so a multi-user
+// deployment doesn't spam everyone with shows they never subscribed to.
 func (t *TelegramBot) SendDailyReport() error {
 	// 获取今天的日期
 	today := time.Now().Format("2006-01-02")
-	
+
 	// 查询今天播出的剧集
 	episodes, err := t.episodeRepo.GetTodayEpisodesWithShowInfo(today)
 	if err != nil {
 		return fmt.Errorf("failed to get today's episodes: %w", err)
 	}
 
-	msg := t.FormatDailyReportFromEpisodes(episodes)
-	// 发送到频道
-	_, err = t.bot.Send(&tele.Chat{ID: t.channelID}, msg, &tele.SendOptions{ParseMode: tele.ModeHTML})
-	return err
+	if t.channelID != 0 {
+		msg := t.FormatDailyReportFromEpisodes(episodes)
+		if _, err := t.bot.Send(&tele.Chat{ID: t.channelID}, msg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
+			return fmt.Errorf("failed to send channel report: %w", err)
+		}
+	}
+
+	if t.userRepo == nil {
+		return nil
+	}
+
+	users, err := t.userRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to list bot users: %w", err)
+	}
+
+	for _, user := range users {
+		if user.Role == repository.BotRolePending {
+			continue
+		}
+
+		scoped, err := t.subMgr.GetSubscriptionsFor(user.ChatID)
+		if err != nil {
+			log.Printf("failed to load subscriptions for chat %d: %v", user.ChatID, err)
+			continue
+		}
+		if len(scoped) == 0 {
+			continue
+		}
+
+		subscribedTMDBIDs := make(map[int]bool, len(scoped))
+		for _, show := range scoped {
+			subscribedTMDBIDs[show.TMDBID] = true
+		}
+
+		var userEpisodes []repository.TodayEpisodeInfo
+		for _, ep := range episodes {
+			if subscribedTMDBIDs[ep.TMDBID] {
+				userEpisodes = append(userEpisodes, ep)
+			}
+		}
+		if len(userEpisodes) == 0 {
+			continue
+		}
+
+		msg := t.FormatDailyReportFromEpisodes(userEpisodes)
+		if _, err := t.bot.Send(&tele.Chat{ID: user.ChatID}, msg, &tele.SendOptions{ParseMode: tele.ModeHTML}); err != nil {
+			log.Printf("failed to send daily report to chat %d: %v", user.ChatID, err)
+		}
+	}
+
+	return nil
 }
 
 // FormatDailyReportFromEpisodes formats today's episodes into a daily report
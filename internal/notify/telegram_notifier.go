@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"fmt"
+
+	"tv-tracker/internal/models"
+)
+
+// TelegramNotifier adapts TelegramBot to the Notifier interface.
+type TelegramNotifier struct {
+	bot *TelegramBot
+}
+
+// NewTelegramNotifier creates a new TelegramNotifier.
+func NewTelegramNotifier(bot *TelegramBot) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot}
+}
+
+// Name identifies this channel.
+func (n *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// SendDailyReport sends tasks to the Telegram channel. TelegramBot composes
+// its own report from today's episodes, so tasks is accepted for interface
+// compatibility but not otherwise used here.
+func (n *TelegramNotifier) SendDailyReport(tasks []models.Task) error {
+	if n.bot == nil {
+		return fmt.Errorf("telegram bot not configured")
+	}
+	return n.bot.SendDailyReport()
+}
+
+// HealthCheck reports whether the Telegram bot is configured.
+func (n *TelegramNotifier) HealthCheck() error {
+	if n.bot == nil {
+		return fmt.Errorf("telegram bot not configured")
+	}
+	return nil
+}
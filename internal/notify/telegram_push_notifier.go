@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// TelegramPushNotifier sends the daily report via a single Telegram Bot API
+// sendMessage call. Unlike TelegramNotifier/TelegramBot, it doesn't poll for
+// commands or need the rest of the app wired in, so it's what a bare
+// "tgram://token/chatid" NOTIFY_URLS entry builds.
+type TelegramPushNotifier struct {
+	token      string
+	chatID     string
+	httpClient *http.Client
+}
+
+// NewTelegramPushNotifier creates a new TelegramPushNotifier.
+func NewTelegramPushNotifier(token, chatID string) *TelegramPushNotifier {
+	return &TelegramPushNotifier{
+		token:      token,
+		chatID:     chatID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (t *TelegramPushNotifier) Name() string {
+	return "telegram"
+}
+
+// SendDailyReport sends tasks as a single Telegram message.
+func (t *TelegramPushNotifier) SendDailyReport(tasks []models.Task) error {
+	if err := t.HealthCheck(); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📺 今日更新日报\n\n")
+	if len(tasks) == 0 {
+		sb.WriteString("今日暂无剧集更新")
+	} else {
+		for i, task := range tasks {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, task.Description)
+		}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"chat_id": t.chatID,
+		"text":    sb.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode telegram message: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.token)
+	resp, err := t.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a token and chat ID are configured.
+func (t *TelegramPushNotifier) HealthCheck() error {
+	if t.token == "" || t.chatID == "" {
+		return fmt.Errorf("telegram push notifier not configured")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"fmt"
+
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+	"tv-tracker/internal/webhook"
+)
+
+// WebhookNotifier sends the daily report to every wildcard-topic webhook
+// subscriber, alongside TelegramBot's channel-based delivery.
+type WebhookNotifier struct {
+	mgr         *webhook.Manager
+	episodeRepo *repository.EpisodeRepository
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier.
+func NewWebhookNotifier(mgr *webhook.Manager, episodeRepo *repository.EpisodeRepository) *WebhookNotifier {
+	return &WebhookNotifier{mgr: mgr, episodeRepo: episodeRepo}
+}
+
+// SendDailyReport broadcasts today's episodes to every wildcard-topic
+// webhook subscriber.
+func (w *WebhookNotifier) SendDailyReport() error {
+	today := timeutil.Now().Format("2006-01-02")
+
+	episodes, err := w.episodeRepo.GetTodayEpisodesWithShowInfo(today)
+	if err != nil {
+		return fmt.Errorf("failed to get today's episodes: %w", err)
+	}
+
+	return w.mgr.Broadcast(map[string]any{
+		"date":     today,
+		"episodes": episodes,
+	})
+}
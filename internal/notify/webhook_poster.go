@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tv-tracker/internal/models"
+)
+
+// WebhookPoster is a generic Notifier that POSTs the daily report as JSON to
+// a single fixed URL, optionally signed with HMAC like the webhook
+// subscription deliveries in internal/webhook.
+type WebhookPoster struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewWebhookPoster creates a new WebhookPoster targeting url, signing
+// requests with secret when non-empty.
+func NewWebhookPoster(url, secret string) *WebhookPoster {
+	return &WebhookPoster{
+		url:        url,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies this channel.
+func (w *WebhookPoster) Name() string {
+	return "webhook"
+}
+
+// SendDailyReport POSTs tasks as JSON to the configured URL.
+func (w *WebhookPoster) SendDailyReport(tasks []models.Task) error {
+	if w.url == "" {
+		return fmt.Errorf("webhook notifier not configured")
+	}
+
+	body, err := json.Marshal(map[string]any{"update_tasks": tasks})
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck reports whether a target URL is configured.
+func (w *WebhookPoster) HealthCheck() error {
+	if w.url == "" {
+		return fmt.Errorf("webhook notifier not configured")
+	}
+	return nil
+}
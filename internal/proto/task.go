@@ -0,0 +1,33 @@
+// Package proto holds the Go-side mirror of task.proto. The tree has no
+// protoc step wired into its build, so TaskPayload is a hand-written struct
+// with the same fields/tags as the .proto message rather than generated
+// code; it can be swapped for the generated type later without touching
+// call sites.
+package proto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// TaskPayload is the structured task data a dedup key is derived from. See
+// task.proto for field documentation.
+type TaskPayload struct {
+	TaskType    string
+	TVShowID    int64
+	EpisodeID   string
+	Description string
+	Deadline    string
+}
+
+// DedupKey returns the content-addressed key "task:<sha256>" used as the
+// tasks table's dedup_key, so INSERT OR IGNORE gives true structural
+// idempotence instead of a separate existence check racing the insert.
+// Only TaskType, TVShowID, and EpisodeID participate in the hash: they're
+// what make two tasks "the same", whereas Description/Deadline can change
+// across recreations of an otherwise-identical task.
+func (p TaskPayload) DedupKey() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", p.TaskType, p.TVShowID, p.EpisodeID)))
+	return "task:" + hex.EncodeToString(sum[:])
+}
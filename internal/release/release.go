@@ -0,0 +1,196 @@
+// Package release parses candidate episode filenames into structured
+// quality metadata (resolution, source, codec, release group) so low-quality
+// cam/telesync rips can be flagged before a user commits to a resource.
+package release
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReleaseInfo is the metadata parsed out of a single release filename.
+type ReleaseInfo struct {
+	Raw        string `json:"raw"`
+	Season     int    `json:"season"`
+	Episode    int    `json:"episode"`
+	Resolution string `json:"resolution"`
+	Source     string `json:"source"`
+	Codec      string `json:"codec"`
+	Group      string `json:"group"`
+	Qiangban   bool   `json:"qiangban"`
+}
+
+var (
+	nonWordRe        = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	seasonEpisodeRe  = regexp.MustCompile(`(?i)S(\d{1,2})E(\d{1,3})`)
+	seasonEpisodeXRe = regexp.MustCompile(`(?i)(\d{1,2})x(\d{1,3})`)
+	seasonEpisodeWRe = regexp.MustCompile(`(?i)Season[\s._-]*(\d{1,2})[\s._-]*Episode[\s._-]*(\d{1,3})`)
+	animeEpisodeRe   = regexp.MustCompile(`(?i)^\[[^\]]+\]\s*.+?[\s._-]+(\d{1,3})(?:\s|\[|$)`)
+	resolutionRe     = regexp.MustCompile(`(?i)\b(2160p|1080p|720p|480p|4K)\b`)
+	sourceRe         = regexp.MustCompile(`(?i)\b(WEB-DL|WEBRip|Blu-?Ray|HDTV|DVDRip|BDRip|HDRip|CAMRip|HDCAM|CAM|HDTS|TS|TELESYNC|PDVD|PreDVDRip|HDTC|TC|TELECINE|WORKPRINT|WP)\b`)
+	codecRe          = regexp.MustCompile(`(?i)\b(x264|x265|h\.?264|h\.?265|HEVC|AVC|AV1)\b`)
+	groupRe          = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+	animeGroupRe     = regexp.MustCompile(`^\[([^\]]+)\]`)
+)
+
+// resolutionRank orders resolutions from lowest to highest quality, so
+// Dispatcher can enforce a TVShow.MinQuality floor ("at least 1080p")
+// instead of PreferredResolution's exact-match comparison.
+var resolutionRank = map[string]int{
+	"480p":  1,
+	"720p":  2,
+	"1080p": 3,
+	"2160p": 4,
+	"4k":    4,
+}
+
+// MeetsMinResolution reports whether resolution is at least as high quality
+// as min, by resolutionRank. An unrecognized or empty resolution never
+// clears a non-empty floor, since it can't be confirmed.
+func MeetsMinResolution(resolution, min string) bool {
+	if min == "" {
+		return true
+	}
+	minRank, ok := resolutionRank[strings.ToLower(min)]
+	if !ok {
+		return true
+	}
+	rank, ok := resolutionRank[strings.ToLower(resolution)]
+	if !ok {
+		return false
+	}
+	return rank >= minRank
+}
+
+// qiangbanTokens are the cam/telesync/workprint markers this parser flags,
+// matched case-insensitively as whole tokens after non-word characters are
+// normalized to spaces (so "CAM-Rip", "CAM.Rip" and "CAM_Rip" all match).
+var qiangbanTokens = []string{
+	"CAMRip", "CAM-Rip", "CAM", "HDCAM",
+	"TS", "TSRip", "HDTS", "TELESYNC",
+	"PDVD", "PreDVDRip",
+	"TC", "HDTC", "TELECINE",
+	"WP", "WORKPRINT",
+}
+
+// ParseRelease extracts season/episode and quality metadata from a release
+// filename. Any field it can't find is left at its zero value.
+func ParseRelease(name string) *ReleaseInfo {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+
+	info := &ReleaseInfo{Raw: name, Qiangban: IsQiangban(name)}
+
+	switch {
+	case seasonEpisodeRe.MatchString(base):
+		m := seasonEpisodeRe.FindStringSubmatch(base)
+		info.Season, _ = strconv.Atoi(m[1])
+		info.Episode, _ = strconv.Atoi(m[2])
+	case seasonEpisodeWRe.MatchString(base):
+		m := seasonEpisodeWRe.FindStringSubmatch(base)
+		info.Season, _ = strconv.Atoi(m[1])
+		info.Episode, _ = strconv.Atoi(m[2])
+	case seasonEpisodeXRe.MatchString(base):
+		m := seasonEpisodeXRe.FindStringSubmatch(base)
+		info.Season, _ = strconv.Atoi(m[1])
+		info.Episode, _ = strconv.Atoi(m[2])
+	case animeEpisodeRe.MatchString(base):
+		// Anime releases are usually single-cour and rarely label a season,
+		// e.g. "[Group] Show - 02 [1080p]"; default to season 1.
+		m := animeEpisodeRe.FindStringSubmatch(base)
+		info.Season = 1
+		info.Episode, _ = strconv.Atoi(m[1])
+	}
+
+	if m := resolutionRe.FindStringSubmatch(base); m != nil {
+		info.Resolution = normalizeResolution(m[1])
+	}
+	if m := sourceRe.FindStringSubmatch(base); m != nil {
+		info.Source = strings.ToUpper(m[1])
+	}
+	if m := codecRe.FindStringSubmatch(base); m != nil {
+		info.Codec = normalizeCodec(m[1])
+	}
+	if m := animeGroupRe.FindStringSubmatch(base); m != nil {
+		info.Group = m[1]
+	} else if m := groupRe.FindStringSubmatch(base); m != nil {
+		info.Group = m[1]
+	}
+
+	return info
+}
+
+// IsQiangban reports whether name contains a cam/telesync/workprint marker,
+// i.e. a low-quality "枪版" rip that should be flagged or skipped.
+func IsQiangban(name string) bool {
+	tokens := tokenize(name)
+
+	for _, marker := range qiangbanTokens {
+		markerTokens := tokenize(marker)
+		if containsSubsequence(tokens, markerTokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePreference rejects a user-supplied quality/codec/group preference
+// that is itself a cam/telesync/workprint marker (e.g. someone typing "CAM"
+// into PreferredQuality), so a per-show preference can never accidentally
+// ask for the exact thing RejectCam is supposed to filter out. An empty
+// value (no preference) is always valid.
+func ValidatePreference(value string) error {
+	if value == "" {
+		return nil
+	}
+	if IsQiangban(value) {
+		return fmt.Errorf("%q looks like a cam/telesync release marker, not a quality preference", value)
+	}
+	return nil
+}
+
+// tokenize normalizes non-word characters to spaces and splits into tokens.
+func tokenize(s string) []string {
+	return strings.Fields(nonWordRe.ReplaceAllString(s, " "))
+}
+
+// containsSubsequence reports whether sub appears, in order and
+// case-insensitively, as a contiguous run within tokens.
+func containsSubsequence(tokens, sub []string) bool {
+	if len(sub) == 0 || len(sub) > len(tokens) {
+		return false
+	}
+	for i := 0; i+len(sub) <= len(tokens); i++ {
+		match := true
+		for j, s := range sub {
+			if !strings.EqualFold(tokens[i+j], s) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeResolution(raw string) string {
+	if strings.EqualFold(raw, "4k") {
+		return "4K"
+	}
+	return strings.ToLower(raw)
+}
+
+func normalizeCodec(raw string) string {
+	switch strings.ToUpper(strings.ReplaceAll(raw, ".", "")) {
+	case "H264":
+		return "H.264"
+	case "H265":
+		return "H.265"
+	default:
+		return strings.ToUpper(raw)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/timeutil"
+)
+
+// APIKeyRepository handles APIKey database operations.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository.
+func NewAPIKeyRepository(sqliteDB *SQLiteDB) *APIKeyRepository {
+	return &APIKeyRepository{db: sqliteDB.db}
+}
+
+// Create inserts key, which must already carry its salt and argon2id hash,
+// and fills in its ID and CreatedAt.
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	now := timeutil.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO api_keys (owner_label, secret_hash, salt, scopes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, key.OwnerLabel, key.SecretHash, key.Salt, key.Scopes, now, key.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	key.ID = id
+	key.CreatedAt = now
+	return nil
+}
+
+// GetAll returns every issued key, including revoked and expired ones, for
+// the key-management endpoints.
+func (r *APIKeyRepository) GetAll() ([]models.APIKey, error) {
+	rows, err := r.db.Query(`
+		SELECT id, owner_label, secret_hash, salt, scopes, created_at, last_used_at, expires_at, revoked_at
+		FROM api_keys
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows)
+}
+
+// GetByID returns a single key by ID, or nil if no such key exists.
+func (r *APIKeyRepository) GetByID(id int64) (*models.APIKey, error) {
+	row := r.db.QueryRow(`
+		SELECT id, owner_label, secret_hash, salt, scopes, created_at, last_used_at, expires_at, revoked_at
+		FROM api_keys
+		WHERE id = ?
+	`, id)
+
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// TouchLastUsed records that key id was just used to authenticate a request.
+func (r *APIKeyRepository) TouchLastUsed(id int64) error {
+	_, err := r.db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, timeutil.Now(), id)
+	return err
+}
+
+// Revoke marks a key as revoked so it fails authentication from now on,
+// without deleting its audit trail (owner, creation time, last use).
+func (r *APIKeyRepository) Revoke(id int64) error {
+	_, err := r.db.Exec(`UPDATE api_keys SET revoked_at = ? WHERE id = ?`, timeutil.Now(), id)
+	return err
+}
+
+type scannableRow interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row scannableRow) (*models.APIKey, error) {
+	var key models.APIKey
+	var lastUsedAt, expiresAt, revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.OwnerLabel, &key.SecretHash, &key.Salt, &key.Scopes,
+		&key.CreatedAt, &lastUsedAt, &expiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
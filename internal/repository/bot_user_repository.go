@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BotRole is a Telegram chat's authorization level against TelegramBot.
+type BotRole string
+
+const (
+	BotRoleOwner      BotRole = "owner"      // full access, including /register approvals
+	BotRoleSubscriber BotRole = "subscriber" // can subscribe and act on their own tasks
+	BotRoleReadOnly   BotRole = "readonly"   // can view tasks/subscriptions only
+	// BotRolePending marks a chat that ran /register and is awaiting the
+	// owner's approval; it has no access until promoted to another role.
+	BotRolePending BotRole = "pending"
+)
+
+// BotUser is one chat ID's entry in the bot's authorization allow-list.
+type BotUser struct {
+	ChatID    int64     `json:"chat_id"`
+	Username  string    `json:"username"`
+	Role      BotRole   `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BotUserRepository stores the chat ID allow-list backing TelegramBot's
+// per-chat authorization.
+type BotUserRepository struct {
+	db *sql.DB
+}
+
+// NewBotUserRepository creates a new BotUserRepository.
+func NewBotUserRepository(sqliteDB *SQLiteDB) *BotUserRepository {
+	return &BotUserRepository{db: sqliteDB.db}
+}
+
+// Upsert records (or updates) a chat's role.
+func (r *BotUserRepository) Upsert(chatID int64, username string, role BotRole) error {
+	_, err := r.db.Exec(`
+		INSERT INTO bot_users (chat_id, username, role)
+		VALUES (?, ?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET username = excluded.username, role = excluded.role
+	`, chatID, username, string(role))
+	return err
+}
+
+// Get returns the chat's allow-list entry, or nil if it isn't registered.
+func (r *BotUserRepository) Get(chatID int64) (*BotUser, error) {
+	var u BotUser
+	var role string
+	err := r.db.QueryRow(`
+		SELECT chat_id, username, role, created_at FROM bot_users WHERE chat_id = ?
+	`, chatID).Scan(&u.ChatID, &u.Username, &role, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.Role = BotRole(role)
+	return &u, nil
+}
+
+// GetAll returns every registered chat, owners first.
+func (r *BotUserRepository) GetAll() ([]BotUser, error) {
+	rows, err := r.db.Query(`
+		SELECT chat_id, username, role, created_at FROM bot_users
+		ORDER BY CASE role WHEN 'owner' THEN 0 ELSE 1 END, created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []BotUser
+	for rows.Next() {
+		var u BotUser
+		var role string
+		if err := rows.Scan(&u.ChatID, &u.Username, &role, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		u.Role = BotRole(role)
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Delete removes a chat from the allow-list, revoking its access entirely.
+func (r *BotUserRepository) Delete(chatID int64) error {
+	_, err := r.db.Exec(`DELETE FROM bot_users WHERE chat_id = ?`, chatID)
+	return err
+}
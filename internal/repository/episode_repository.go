@@ -2,18 +2,41 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
 
 	"tv-tracker/internal/models"
 )
 
+type episodeDBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 // EpisodeRepository handles Episode database operations
 type EpisodeRepository struct {
-	db *sql.DB
+	db   episodeDBTX
+	base *sql.DB
 }
 
 // NewEpisodeRepository creates a new EpisodeRepository
 func NewEpisodeRepository(sqliteDB *SQLiteDB) *EpisodeRepository {
-	return &EpisodeRepository{db: sqliteDB.db}
+	return &EpisodeRepository{db: sqliteDB.db, base: sqliteDB.db}
+}
+
+func (r *EpisodeRepository) BeginTx() (*sql.Tx, error) {
+	if r.base == nil {
+		return nil, errors.New("episode repository: transactions not supported on tx-scoped repo")
+	}
+	return r.base.Begin()
+}
+
+func (r *EpisodeRepository) WithTx(tx *sql.Tx) *EpisodeRepository {
+	return &EpisodeRepository{db: tx}
 }
 
 // Upsert inserts or updates an episode
@@ -39,12 +62,22 @@ func (r *EpisodeRepository) Upsert(episode *models.Episode) error {
 	return nil
 }
 
-// GetByTMDBID retrieves all episodes for a show by TMDB ID
+// GetByTMDBID retrieves all episodes for a show by TMDB ID, flagging any
+// that have been marked skipped (e.g. ignored specials) or watched.
 func (r *EpisodeRepository) GetByTMDBID(tmdbID int) ([]models.Episode, error) {
 	rows, err := r.db.Query(`
-		SELECT id, tmdb_id, season, episode, title, overview, air_date
-		FROM episodes WHERE tmdb_id = ?
-		ORDER BY season, episode
+		SELECT e.id, e.tmdb_id, e.season, e.episode, e.title, e.overview, e.air_date,
+			CASE WHEN s.episode_code IS NOT NULL THEN 1 ELSE 0 END AS skipped,
+			CASE WHEN w.episode_code IS NOT NULL THEN 1 ELSE 0 END AS watched
+		FROM episodes e
+		LEFT JOIN skipped_episodes s
+			ON s.tmdb_id = e.tmdb_id
+			AND s.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		LEFT JOIN watched_episodes w
+			ON w.tmdb_id = e.tmdb_id
+			AND w.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		WHERE e.tmdb_id = ?
+		ORDER BY e.season, e.episode
 	`, tmdbID)
 	if err != nil {
 		return nil, err
@@ -55,7 +88,7 @@ func (r *EpisodeRepository) GetByTMDBID(tmdbID int) ([]models.Episode, error) {
 	for rows.Next() {
 		var ep models.Episode
 		var airDate sql.NullString
-		err := rows.Scan(&ep.ID, &ep.TMDBID, &ep.Season, &ep.Episode, &ep.Title, &ep.Overview, &airDate)
+		err := rows.Scan(&ep.ID, &ep.TMDBID, &ep.Season, &ep.Episode, &ep.Title, &ep.Overview, &airDate, &ep.Skipped, &ep.Watched)
 		if err != nil {
 			return nil, err
 		}
@@ -67,6 +100,88 @@ func (r *EpisodeRepository) GetByTMDBID(tmdbID int) ([]models.Episode, error) {
 	return episodes, rows.Err()
 }
 
+// GetByID retrieves a single episode by its row ID, or (nil, nil) if no such
+// episode exists.
+func (r *EpisodeRepository) GetByID(id int64) (*models.Episode, error) {
+	row := r.db.QueryRow(`
+		SELECT e.id, e.tmdb_id, e.season, e.episode, e.title, e.overview, e.air_date,
+			CASE WHEN s.episode_code IS NOT NULL THEN 1 ELSE 0 END AS skipped,
+			CASE WHEN w.episode_code IS NOT NULL THEN 1 ELSE 0 END AS watched
+		FROM episodes e
+		LEFT JOIN skipped_episodes s
+			ON s.tmdb_id = e.tmdb_id
+			AND s.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		LEFT JOIN watched_episodes w
+			ON w.tmdb_id = e.tmdb_id
+			AND w.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		WHERE e.id = ?
+	`, id)
+
+	var ep models.Episode
+	var airDate sql.NullString
+	err := row.Scan(&ep.ID, &ep.TMDBID, &ep.Season, &ep.Episode, &ep.Title, &ep.Overview, &airDate, &ep.Skipped, &ep.Watched)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if airDate.Valid {
+		ep.AirDate = airDate.String
+	}
+	return &ep, nil
+}
+
+// SkipSpecials marks the given episode codes (e.g. "S00E03") as ignored for
+// a show, so TaskGenerator stops creating UPDATE tasks for them. Codes are
+// matched explicitly rather than by count since specials are often
+// non-contiguous. It returns how many codes were newly marked, skipping ones
+// already skipped.
+func (r *EpisodeRepository) SkipSpecials(tmdbID int, codes []string) (int, error) {
+	skipped := 0
+	for _, code := range codes {
+		result, err := r.db.Exec(`
+			INSERT OR IGNORE INTO skipped_episodes (tmdb_id, episode_code) VALUES (?, ?)
+		`, tmdbID, code)
+		if err != nil {
+			return skipped, fmt.Errorf("failed to skip episode %s: %w", code, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return skipped, err
+		}
+		skipped += int(affected)
+	}
+	return skipped, nil
+}
+
+// Unskip removes the skipped mark from the given episode codes for a show.
+func (r *EpisodeRepository) Unskip(tmdbID int, codes []string) error {
+	for _, code := range codes {
+		if _, err := r.db.Exec(`
+			DELETE FROM skipped_episodes WHERE tmdb_id = ? AND episode_code = ?
+		`, tmdbID, code); err != nil {
+			return fmt.Errorf("failed to unskip episode %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// IsSkipped reports whether a specific episode code has been marked ignored for a show.
+func (r *EpisodeRepository) IsSkipped(tmdbID int, episodeCode string) (bool, error) {
+	var exists int
+	err := r.db.QueryRow(`
+		SELECT 1 FROM skipped_episodes WHERE tmdb_id = ? AND episode_code = ?
+	`, tmdbID, episodeCode).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // GetByAirDate retrieves all episodes airing on a specific date
 func (r *EpisodeRepository) GetByAirDate(date string) ([]models.Episode, error) {
 	rows, err := r.db.Query(`
@@ -95,8 +210,177 @@ func (r *EpisodeRepository) GetByAirDate(date string) ([]models.Episode, error)
 	return episodes, rows.Err()
 }
 
+// UpcomingEpisodeInfo pairs an episode with its show's display info. Shaped
+// like GetTodayEpisodesWithShowInfo's per-episode result, but scoped to a
+// date range rather than a single day, for GetUpcomingWithShowInfo.
+type UpcomingEpisodeInfo struct {
+	TMDBID       int
+	ShowName     string
+	ResourceTime string
+	Episode      models.Episode
+}
+
+// GetUpcomingWithShowInfo returns every episode of a non-archived show
+// airing in [fromDate, fromDate+horizonDays], ordered by air date then show
+// name, for feeds like the ICS calendar export that need more than a single
+// day's lookahead.
+func (r *EpisodeRepository) GetUpcomingWithShowInfo(fromDate string, horizonDays int) ([]UpcomingEpisodeInfo, error) {
+	rows, err := r.db.Query(`
+		SELECT s.tmdb_id, s.name, s.resource_time, e.id, e.season, e.episode, e.title, e.overview, e.air_date
+		FROM episodes e
+		JOIN tv_shows s ON s.tmdb_id = e.tmdb_id
+		WHERE s.is_archived = FALSE AND e.air_date >= ? AND e.air_date <= date(?, ?)
+		ORDER BY e.air_date ASC, s.name ASC
+	`, fromDate, fromDate, fmt.Sprintf("+%d days", horizonDays))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []UpcomingEpisodeInfo
+	for rows.Next() {
+		var info UpcomingEpisodeInfo
+		var airDate sql.NullString
+		err := rows.Scan(
+			&info.TMDBID, &info.ShowName, &info.ResourceTime,
+			&info.Episode.ID, &info.Episode.Season, &info.Episode.Episode, &info.Episode.Title, &info.Episode.Overview, &airDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if airDate.Valid {
+			info.Episode.AirDate = airDate.String
+		}
+		info.Episode.TMDBID = info.TMDBID
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+// TodayEpisodeInfo pairs an episode with its show's display info for a
+// single day's lookup, the single-day counterpart to UpcomingEpisodeInfo.
+type TodayEpisodeInfo = UpcomingEpisodeInfo
+
+// GetTodayEpisodesWithShowInfo returns every episode of a non-archived show
+// airing on date, for the Telegram bot's "今日更新" button and the webhook
+// daily broadcast.
+func (r *EpisodeRepository) GetTodayEpisodesWithShowInfo(date string) ([]TodayEpisodeInfo, error) {
+	return r.GetUpcomingWithShowInfo(date, 0)
+}
+
 // DeleteByTMDBID deletes all episodes for a show
 func (r *EpisodeRepository) DeleteByTMDBID(tmdbID int) error {
 	_, err := r.db.Exec(`DELETE FROM episodes WHERE tmdb_id = ?`, tmdbID)
 	return err
 }
+
+// episodeCodeRe matches the canonical "SxxExx" format produced by
+// FormatEpisodeID, e.g. "S01E03".
+var episodeCodeRe = regexp.MustCompile(`(?i)^S(\d{1,2})E(\d{1,3})$`)
+
+// parseEpisodeCode parses a "SxxExx" code into its season and episode numbers.
+func parseEpisodeCode(code string) (season, episode int, err error) {
+	m := episodeCodeRe.FindStringSubmatch(code)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid episode code %q", code)
+	}
+	season, err = strconv.Atoi(m[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid episode code %q", code)
+	}
+	episode, err = strconv.Atoi(m[2])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid episode code %q", code)
+	}
+	return season, episode, nil
+}
+
+// markWatched inserts a watched_episodes row for each given code, skipping
+// ones already marked, and returns how many were newly inserted.
+func (r *EpisodeRepository) markWatched(tmdbID int, codes []string, at time.Time) (int, error) {
+	marked := 0
+	for _, code := range codes {
+		result, err := r.db.Exec(`
+			INSERT OR IGNORE INTO watched_episodes (tmdb_id, episode_code, watched_at) VALUES (?, ?, ?)
+		`, tmdbID, code, at)
+		if err != nil {
+			return marked, fmt.Errorf("failed to mark %s watched: %w", code, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return marked, err
+		}
+		marked += int(affected)
+	}
+	return marked, nil
+}
+
+// MarkSeasonWatched marks every known episode of a season watched, skipping
+// ones already marked, and returns the number of episodes newly marked.
+func (r *EpisodeRepository) MarkSeasonWatched(tmdbID, season int, at time.Time) (int, error) {
+	rows, err := r.db.Query(`
+		SELECT episode FROM episodes WHERE tmdb_id = ? AND season = ? ORDER BY episode
+	`, tmdbID, season)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var episode int
+		if err := rows.Scan(&episode); err != nil {
+			return 0, err
+		}
+		codes = append(codes, fmt.Sprintf("S%02dE%02d", season, episode))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.markWatched(tmdbID, codes, at)
+}
+
+// MarkRangeWatched marks every known episode between fromCode and toCode
+// (inclusive, both "SxxExx") watched, skipping ones already marked, and
+// returns the number of episodes newly marked.
+func (r *EpisodeRepository) MarkRangeWatched(tmdbID int, fromCode, toCode string, at time.Time) (int, error) {
+	fromSeason, fromEpisode, err := parseEpisodeCode(fromCode)
+	if err != nil {
+		return 0, err
+	}
+	toSeason, toEpisode, err := parseEpisodeCode(toCode)
+	if err != nil {
+		return 0, err
+	}
+	if fromSeason > toSeason || (fromSeason == toSeason && fromEpisode > toEpisode) {
+		fromSeason, toSeason = toSeason, fromSeason
+		fromEpisode, toEpisode = toEpisode, fromEpisode
+	}
+
+	rows, err := r.db.Query(`
+		SELECT season, episode FROM episodes
+		WHERE tmdb_id = ?
+			AND (season, episode) >= (?, ?)
+			AND (season, episode) <= (?, ?)
+		ORDER BY season, episode
+	`, tmdbID, fromSeason, fromEpisode, toSeason, toEpisode)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var season, episode int
+		if err := rows.Scan(&season, &episode); err != nil {
+			return 0, err
+		}
+		codes = append(codes, fmt.Sprintf("S%02dE%02d", season, episode))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.markWatched(tmdbID, codes, at)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/timeutil"
+)
+
+type episodeResourceDBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// EpisodeResourceRepository handles EpisodeResource database operations
+type EpisodeResourceRepository struct {
+	db   episodeResourceDBTX
+	base *sql.DB
+}
+
+// NewEpisodeResourceRepository creates a new EpisodeResourceRepository
+func NewEpisodeResourceRepository(sqliteDB *SQLiteDB) *EpisodeResourceRepository {
+	return &EpisodeResourceRepository{db: sqliteDB.db, base: sqliteDB.db}
+}
+
+func (r *EpisodeResourceRepository) BeginTx() (*sql.Tx, error) {
+	if r.base == nil {
+		return nil, errors.New("episode resource repository: transactions not supported on tx-scoped repo")
+	}
+	return r.base.Begin()
+}
+
+func (r *EpisodeResourceRepository) WithTx(tx *sql.Tx) *EpisodeResourceRepository {
+	return &EpisodeResourceRepository{db: tx}
+}
+
+// Upsert inserts or updates the candidate resource for episodeID+url,
+// re-parsing its quality metadata in place on a repeat submission rather
+// than creating a duplicate row.
+func (r *EpisodeResourceRepository) Upsert(res *models.EpisodeResource) error {
+	now := timeutil.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO episode_resources (episode_id, title, url, resolution, source, codec, release_group, qiangban, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(episode_id, url) DO UPDATE SET
+			title = excluded.title,
+			resolution = excluded.resolution,
+			source = excluded.source,
+			codec = excluded.codec,
+			release_group = excluded.release_group,
+			qiangban = excluded.qiangban
+	`, res.EpisodeID, res.Title, res.URL, res.Resolution, res.Source, res.Codec, res.Group, res.Qiangban, now)
+	if err != nil {
+		return err
+	}
+	if res.ID == 0 {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if id != 0 {
+			res.ID = id
+		}
+	}
+
+	// created_at is only set by the INSERT branch; a repeat submission for
+	// the same episode_id+url hits DO UPDATE instead, which leaves it alone.
+	// Read it back rather than assuming now, so a re-attach doesn't report a
+	// CreatedAt later than what's actually stored.
+	if err := r.db.QueryRow(
+		`SELECT created_at FROM episode_resources WHERE episode_id = ? AND url = ?`,
+		res.EpisodeID, res.URL,
+	).Scan(&res.CreatedAt); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByEpisodeID retrieves every candidate resource attached to an episode.
+func (r *EpisodeResourceRepository) GetByEpisodeID(episodeID int64) ([]models.EpisodeResource, error) {
+	rows, err := r.db.Query(`
+		SELECT id, episode_id, title, url, resolution, source, codec, release_group, qiangban, created_at
+		FROM episode_resources WHERE episode_id = ?
+		ORDER BY created_at DESC
+	`, episodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resources []models.EpisodeResource
+	for rows.Next() {
+		var res models.EpisodeResource
+		if err := rows.Scan(
+			&res.ID, &res.EpisodeID, &res.Title, &res.URL, &res.Resolution, &res.Source, &res.Codec, &res.Group, &res.Qiangban, &res.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"tv-tracker/internal/timeutil"
+)
+
+// ShowDetailsSeason is the sentinel `season` value used for a show-details
+// cache entry (as opposed to a specific season's episode list), so both
+// share the metadata_cache table under one (provider_id, external_id,
+// language, season) key.
+const ShowDetailsSeason = -1
+
+// CacheEntry is a cached metadata payload along with the ETag/Last-Modified
+// the provider returned for it, so callers can send conditional headers on
+// the next refresh, and the time it was fetched, so callers can apply their
+// own TTL.
+type CacheEntry struct {
+	PayloadJSON  string
+	ETag         string
+	LastModified string
+	FetchedAt    string
+	SoftTTL      time.Duration
+	HardTTL      time.Duration
+	ErrorCount   int
+	Negative     bool
+}
+
+// CacheStatus classifies a CacheEntry against a freshness window, so callers
+// can decide whether to serve it as-is, serve it while revalidating in the
+// background, or block for a synchronous refresh.
+type CacheStatus int
+
+const (
+	// CacheMissing means no cached entry exists at all.
+	CacheMissing CacheStatus = iota
+	// CacheFresh means the entry is within freshTTL and can be served
+	// without any revalidation.
+	CacheFresh
+	// CacheStale means the entry is past freshTTL but within staleTTL: still
+	// safe to serve immediately, but a caller should revalidate soon.
+	CacheStale
+	// CacheExpired means the entry is past staleTTL and should not be served
+	// without a synchronous refresh first.
+	CacheExpired
+	// CacheNegative means the entry records a prior "not found" response
+	// from the provider (see UpsertNegative); callers should treat it the
+	// same as a real 404 without hitting the provider again until it
+	// expires.
+	CacheNegative
+)
+
+// MetadataCacheRepository stores raw metadata.Provider response snapshots
+// (show details or a season's episode list), keyed by provider ID, external
+// ID, language, and season, so several providers (TMDB, and eventually
+// TVDB/AniList/Trakt) can share one cache table instead of each needing
+// their own. ShowDetailsSeason is the season value used for show-details
+// entries.
+type MetadataCacheRepository struct {
+	db *sql.DB
+}
+
+// NewMetadataCacheRepository creates a new MetadataCacheRepository.
+func NewMetadataCacheRepository(sqliteDB *SQLiteDB) *MetadataCacheRepository {
+	return &MetadataCacheRepository{db: sqliteDB.db}
+}
+
+// Get returns the cached payload for a provider ID, external ID, language,
+// and season, touching last_hit so a future eviction policy can tell which
+// cache rows are actually being read.
+func (r *MetadataCacheRepository) Get(providerID, externalID, language string, season int) (*CacheEntry, bool, error) {
+	var entry CacheEntry
+	var softTTLSeconds, hardTTLSeconds int64
+	err := r.db.QueryRow(`
+		SELECT payload_json, etag, last_modified, fetched_at, soft_ttl_seconds, hard_ttl_seconds, error_count, negative
+		FROM metadata_cache
+		WHERE provider_id = ? AND external_id = ? AND language = ? AND season = ?
+	`, providerID, externalID, language, season).Scan(
+		&entry.PayloadJSON, &entry.ETag, &entry.LastModified, &entry.FetchedAt,
+		&softTTLSeconds, &hardTTLSeconds, &entry.ErrorCount, &entry.Negative,
+	)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	entry.SoftTTL = time.Duration(softTTLSeconds) * time.Second
+	entry.HardTTL = time.Duration(hardTTLSeconds) * time.Second
+
+	if _, err := r.db.Exec(`
+		UPDATE metadata_cache SET last_hit = ? WHERE provider_id = ? AND external_id = ? AND language = ? AND season = ?
+	`, timeutil.Now().Format("2006-01-02 15:04:05"), providerID, externalID, language, season); err != nil {
+		return nil, false, err
+	}
+
+	return &entry, true, nil
+}
+
+// GetWithMeta is like Get but also classifies the entry's CacheStatus
+// against freshTTL (Fresh/Stale boundary) and staleTTL (Stale/Expired
+// boundary), so callers can implement stale-while-revalidate: serve a Fresh
+// or Stale entry immediately, but block for a refresh on Expired or Missing.
+func (r *MetadataCacheRepository) GetWithMeta(providerID, externalID, language string, season int, freshTTL, staleTTL time.Duration) (*CacheEntry, CacheStatus, error) {
+	entry, ok, err := r.Get(providerID, externalID, language, season)
+	if err != nil {
+		return nil, CacheMissing, err
+	}
+	if !ok {
+		return nil, CacheMissing, nil
+	}
+
+	age := cacheAge(entry.FetchedAt)
+
+	if entry.Negative {
+		if entry.HardTTL > 0 && age >= entry.HardTTL {
+			return nil, CacheMissing, nil
+		}
+		return entry, CacheNegative, nil
+	}
+
+	// A row written with its own soft/hard TTL (currently nothing does this
+	// for a positive entry, but UpsertNegative does) overrides the
+	// caller-supplied window.
+	if entry.SoftTTL > 0 {
+		freshTTL = entry.SoftTTL
+	}
+	if entry.HardTTL > 0 {
+		staleTTL = entry.HardTTL
+	}
+
+	switch {
+	case age < freshTTL:
+		return entry, CacheFresh, nil
+	case age < staleTTL:
+		return entry, CacheStale, nil
+	default:
+		return entry, CacheExpired, nil
+	}
+}
+
+// cacheAge returns how long ago fetchedAt (in the repository's timestamp
+// layout) was, treating an unparseable timestamp as infinitely old.
+func cacheAge(fetchedAt string) time.Duration {
+	t, err := time.Parse("2006-01-02 15:04:05", fetchedAt)
+	if err != nil {
+		return time.Duration(math.MaxInt64)
+	}
+	return timeutil.Now().Sub(t)
+}
+
+// Upsert writes the latest payload JSON, ETag, and Last-Modified for a
+// provider ID, external ID, language, and season.
+func (r *MetadataCacheRepository) Upsert(providerID, externalID, payloadJSON, etag, lastModified, fetchedAt, language string, season int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO metadata_cache (provider_id, external_id, language, season, payload_json, etag, last_modified, fetched_at, last_hit)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(provider_id, external_id, language, season) DO UPDATE SET
+			payload_json = excluded.payload_json,
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			fetched_at = excluded.fetched_at,
+			last_hit = excluded.last_hit,
+			soft_ttl_seconds = 0,
+			hard_ttl_seconds = 0,
+			error_count = 0,
+			negative = FALSE
+	`, providerID, externalID, language, season, payloadJSON, etag, lastModified, fetchedAt, fetchedAt)
+	return err
+}
+
+// UpsertNegative records that the provider reported providerID/externalID
+// as not found, so a caller can skip hitting it again until ttl elapses
+// instead of repeating a failing lookup on every request. A subsequent
+// Upsert (the provider ID starting to resolve again) clears the negative
+// flag along with its TTL override.
+func (r *MetadataCacheRepository) UpsertNegative(providerID, externalID, language string, season int, fetchedAt string, ttl time.Duration) error {
+	ttlSeconds := int64(ttl / time.Second)
+	_, err := r.db.Exec(`
+		INSERT INTO metadata_cache (provider_id, external_id, language, season, payload_json, fetched_at, last_hit, soft_ttl_seconds, hard_ttl_seconds, negative)
+		VALUES (?, ?, ?, ?, '', ?, ?, ?, ?, TRUE)
+		ON CONFLICT(provider_id, external_id, language, season) DO UPDATE SET
+			fetched_at = excluded.fetched_at,
+			last_hit = excluded.last_hit,
+			soft_ttl_seconds = excluded.soft_ttl_seconds,
+			hard_ttl_seconds = excluded.hard_ttl_seconds,
+			negative = TRUE
+	`, providerID, externalID, language, season, fetchedAt, fetchedAt, ttlSeconds, ttlSeconds)
+	return err
+}
+
+// IncrementErrorCount bumps error_count for an existing entry, used to
+// track repeated upstream failures while stale-while-revalidate keeps
+// serving the last good payload. A missing row is a no-op: there's nothing
+// to count errors against yet.
+func (r *MetadataCacheRepository) IncrementErrorCount(providerID, externalID, language string, season int) error {
+	_, err := r.db.Exec(`
+		UPDATE metadata_cache SET error_count = error_count + 1
+		WHERE provider_id = ? AND external_id = ? AND language = ? AND season = ?
+	`, providerID, externalID, language, season)
+	return err
+}
+
+// TouchFetchedAt bumps fetched_at (and last_hit) without rewriting the
+// payload, used when a provider responds 304 Not Modified to a conditional
+// refresh and the cached payload is still current.
+func (r *MetadataCacheRepository) TouchFetchedAt(providerID, externalID, language string, season int, fetchedAt string) error {
+	_, err := r.db.Exec(`
+		UPDATE metadata_cache SET fetched_at = ?, last_hit = ? WHERE provider_id = ? AND external_id = ? AND language = ? AND season = ?
+	`, fetchedAt, fetchedAt, providerID, externalID, language, season)
+	return err
+}
+
+// Purge deletes cache rows whose fetched_at is older than olderThan,
+// reporting how many rows were removed.
+func (r *MetadataCacheRepository) Purge(olderThan time.Duration) (int64, error) {
+	cutoff := timeutil.Now().Add(-olderThan).Format("2006-01-02 15:04:05")
+	result, err := r.db.Exec(`DELETE FROM metadata_cache WHERE fetched_at < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// InvalidateLanguage removes the cached entry for a provider ID, external
+// ID, and season in a specific language.
+func (r *MetadataCacheRepository) InvalidateLanguage(providerID, externalID, language string, season int) error {
+	_, err := r.db.Exec(`DELETE FROM metadata_cache WHERE provider_id = ? AND external_id = ? AND language = ? AND season = ?`, providerID, externalID, language, season)
+	return err
+}
+
+// InvalidateAll removes every cached language and season variant for a
+// provider ID and external ID.
+func (r *MetadataCacheRepository) InvalidateAll(providerID, externalID string) error {
+	_, err := r.db.Exec(`DELETE FROM metadata_cache WHERE provider_id = ? AND external_id = ?`, providerID, externalID)
+	return err
+}
@@ -0,0 +1,1114 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"tv-tracker/internal/proto"
+)
+
+// Migration is a single versioned schema change. SQL is the canonical
+// migration body used only to compute a checksum (even though Up may issue
+// several statements via the rebuild-table dance) so edited history can be
+// detected. RebuildsTable migrations get PRAGMA foreign_keys toggled off
+// around them, since SQLite only honors that pragma outside a transaction.
+type Migration struct {
+	Version       int
+	Name          string
+	SQL           string
+	RebuildsTable bool
+	Up            func(tx *sql.Tx) error
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+// migrations is the ordered list of schema changes, replacing the old
+// one-off runMigrations/migrateX functions.
+var migrations = []Migration{
+	{
+		Version:       1,
+		Name:          "resource_time_is_manual",
+		SQL:           `ALTER TABLE tv_shows ADD COLUMN resource_time_is_manual BOOLEAN DEFAULT FALSE`,
+		RebuildsTable: true,
+		Up:            migrateResourceTimeIsManual,
+	},
+	{
+		Version: 2,
+		Name:    "metadata_source",
+		SQL:     `ALTER TABLE tv_shows ADD COLUMN metadata_source TEXT DEFAULT 'tmdb'`,
+		Up:      migrateMetadataSource,
+	},
+	{
+		Version:       3,
+		Name:          "tmdb_cache_composite_key",
+		SQL:           `CREATE TABLE tmdb_cache (tmdb_id INTEGER NOT NULL, language TEXT NOT NULL, payload_json TEXT NOT NULL, fetched_at TIMESTAMP NOT NULL, PRIMARY KEY (tmdb_id, language))`,
+		RebuildsTable: true,
+		Up:            migrateTMDBCacheCompositeKey,
+	},
+	{
+		Version: 4,
+		Name:    "tmdb_cache_etag_last_hit",
+		SQL:     `ALTER TABLE tmdb_cache ADD COLUMN etag TEXT DEFAULT ''; ALTER TABLE tmdb_cache ADD COLUMN last_hit TIMESTAMP`,
+		Up:      migrateTMDBCacheETagLastHit,
+	},
+	{
+		Version: 5,
+		Name:    "skipped_episodes",
+		SQL:     `CREATE TABLE IF NOT EXISTS skipped_episodes (tmdb_id INTEGER NOT NULL, episode_code TEXT NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (tmdb_id, episode_code))`,
+		Up:      migrateSkippedEpisodes,
+	},
+	{
+		Version: 6,
+		Name:    "webhook_subscriptions",
+		SQL:     `CREATE TABLE IF NOT EXISTS webhook_subscriptions (id INTEGER PRIMARY KEY AUTOINCREMENT, callback_url TEXT NOT NULL, topic TEXT NOT NULL, secret TEXT DEFAULT '', lease_seconds INTEGER DEFAULT 0, verified_at TIMESTAMP, expires_at TIMESTAMP, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		Up:      migrateWebhookSubscriptions,
+	},
+	{
+		Version: 7,
+		Name:    "show_search_metadata",
+		SQL:     `ALTER TABLE tv_shows ADD COLUMN original_name TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN overview TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN genres TEXT DEFAULT ''`,
+		Up:      migrateShowSearchMetadata,
+	},
+	{
+		Version: 8,
+		Name:    "shows_fts",
+		SQL:     showsFTSSchema,
+		Up:      migrateShowsFTS,
+	},
+	{
+		Version: 9,
+		Name:    "api_keys",
+		SQL:     `CREATE TABLE IF NOT EXISTS api_keys (id INTEGER PRIMARY KEY AUTOINCREMENT, owner_label TEXT NOT NULL, secret_hash TEXT NOT NULL, salt TEXT NOT NULL, scopes INTEGER NOT NULL DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, last_used_at TIMESTAMP, expires_at TIMESTAMP, revoked_at TIMESTAMP)`,
+		Up:      migrateAPIKeys,
+	},
+	{
+		Version: 10,
+		Name:    "watched_episodes",
+		SQL:     `CREATE TABLE IF NOT EXISTS watched_episodes (tmdb_id INTEGER NOT NULL, episode_code TEXT NOT NULL, watched_at TIMESTAMP NOT NULL, PRIMARY KEY (tmdb_id, episode_code))`,
+		Up:      migrateWatchedEpisodes,
+	},
+	{
+		Version:       11,
+		Name:          "tmdb_cache_season_column",
+		SQL:           `CREATE TABLE tmdb_cache (tmdb_id INTEGER NOT NULL, language TEXT NOT NULL, season INTEGER NOT NULL DEFAULT -1, payload_json TEXT NOT NULL, etag TEXT DEFAULT '', fetched_at TIMESTAMP NOT NULL, last_hit TIMESTAMP, PRIMARY KEY (tmdb_id, language, season))`,
+		RebuildsTable: true,
+		Up:            migrateTMDBCacheSeasonColumn,
+	},
+	{
+		Version: 12,
+		Name:    "episodes_tmdb_air_date_index",
+		SQL:     `CREATE INDEX IF NOT EXISTS idx_episodes_tmdb_air_date ON episodes(tmdb_id, air_date)`,
+		Up:      migrateEpisodesTMDBAirDateIndex,
+	},
+	{
+		Version: 13,
+		Name:    "tasks_dedup_key",
+		SQL:     `ALTER TABLE tasks ADD COLUMN episode_id TEXT DEFAULT ''; ALTER TABLE tasks ADD COLUMN dedup_key TEXT DEFAULT ''; CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_dedup_key ON tasks(dedup_key) WHERE dedup_key != ''`,
+		Up:      migrateTasksDedupKey,
+	},
+	{
+		Version: 14,
+		Name:    "show_policy_fields",
+		SQL:     `ALTER TABLE tv_shows ADD COLUMN quality_filter TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN preferred_resolution TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN notify_channel TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN auto_skip_specials BOOLEAN DEFAULT FALSE`,
+		Up:      migrateShowPolicyFields,
+	},
+	{
+		Version: 15,
+		Name:    "sync_runs",
+		SQL:     `CREATE TABLE IF NOT EXISTS sync_runs (id INTEGER PRIMARY KEY AUTOINCREMENT, job_name TEXT NOT NULL, started_at TIMESTAMP NOT NULL, finished_at TIMESTAMP NOT NULL, shows_refreshed INTEGER NOT NULL DEFAULT 0, errors INTEGER NOT NULL DEFAULT 0, triggered_by TEXT NOT NULL DEFAULT 'schedule'); CREATE INDEX IF NOT EXISTS idx_sync_runs_job_name ON sync_runs(job_name, finished_at)`,
+		Up:      migrateSyncRuns,
+	},
+	{
+		Version: 16,
+		Name:    "show_release_preferences",
+		SQL:     `ALTER TABLE tv_shows ADD COLUMN preferred_quality TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN preferred_codec TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN preferred_group TEXT DEFAULT ''; ALTER TABLE tv_shows ADD COLUMN reject_cam BOOLEAN DEFAULT TRUE`,
+		Up:      migrateShowReleasePreferences,
+	},
+	{
+		Version: 17,
+		Name:    "task_priority_due_date",
+		SQL:     `ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0; ALTER TABLE tasks ADD COLUMN due_date TIMESTAMP`,
+		Up:      migrateTaskPriorityDueDate,
+	},
+	{
+		Version: 18,
+		Name:    "schedule_rules",
+		SQL:     `CREATE TABLE IF NOT EXISTS schedule_rules (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, cron_expr TEXT NOT NULL, task_type TEXT NOT NULL, selector TEXT NOT NULL, threshold_days INTEGER NOT NULL DEFAULT 0, description_template TEXT NOT NULL, enabled BOOLEAN NOT NULL DEFAULT TRUE)`,
+		Up:      migrateScheduleRules,
+	},
+	{
+		Version: 19,
+		Name:    "task_audit",
+		SQL:     `CREATE TABLE IF NOT EXISTS task_audit (id INTEGER PRIMARY KEY AUTOINCREMENT, action_id TEXT NOT NULL, action TEXT NOT NULL, task_id INTEGER NOT NULL, new_task_id INTEGER, prior_task_json TEXT NOT NULL, prior_show_archived BOOLEAN, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS idx_task_audit_action_id ON task_audit(action_id)`,
+		Up:      migrateTaskAudit,
+	},
+	{
+		Version: 20,
+		Name:    "tmdb_cache_last_modified",
+		SQL:     `ALTER TABLE tmdb_cache ADD COLUMN last_modified TEXT DEFAULT ''`,
+		Up:      migrateTMDBCacheLastModified,
+	},
+	{
+		Version:       21,
+		Name:          "metadata_cache_provider_key",
+		SQL:           `CREATE TABLE metadata_cache (provider_id TEXT NOT NULL, external_id TEXT NOT NULL, language TEXT NOT NULL, season INTEGER NOT NULL DEFAULT -1, payload_json TEXT NOT NULL, etag TEXT DEFAULT '', last_modified TEXT DEFAULT '', fetched_at TIMESTAMP NOT NULL, last_hit TIMESTAMP, PRIMARY KEY (provider_id, external_id, language, season))`,
+		RebuildsTable: true,
+		Up:            migrateMetadataCacheProviderKey,
+	},
+	{
+		Version: 22,
+		Name:    "show_external_refs",
+		SQL:     `CREATE TABLE IF NOT EXISTS show_external_refs (tv_show_id INTEGER NOT NULL, provider_id TEXT NOT NULL, external_id TEXT NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (tv_show_id, provider_id), FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id))`,
+		Up:      migrateShowExternalRefs,
+	},
+	{
+		Version: 23,
+		Name:    "bot_users",
+		SQL:     `CREATE TABLE IF NOT EXISTS bot_users (chat_id INTEGER PRIMARY KEY, username TEXT NOT NULL DEFAULT '', role TEXT NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		Up:      migrateBotUsers,
+	},
+	{
+		Version: 24,
+		Name:    "show_subscribers",
+		SQL:     `CREATE TABLE IF NOT EXISTS show_subscribers (tv_show_id INTEGER NOT NULL, chat_id INTEGER NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (tv_show_id, chat_id), FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id))`,
+		Up:      migrateShowSubscribers,
+	},
+	{
+		Version: 25,
+		Name:    "show_preferences",
+		SQL:     `CREATE TABLE IF NOT EXISTS show_preferences (chat_id INTEGER NOT NULL, tv_show_id INTEGER NOT NULL, notify_enabled BOOLEAN NOT NULL DEFAULT 1, include_in_daily_report BOOLEAN NOT NULL DEFAULT 1, resource_time_override TEXT NOT NULL DEFAULT '', title_language TEXT NOT NULL DEFAULT '', PRIMARY KEY (chat_id, tv_show_id), FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id))`,
+		Up:      migrateShowPreferences,
+	},
+	{
+		Version: 26,
+		Name:    "settings",
+		SQL:     `CREATE TABLE IF NOT EXISTS settings (key TEXT PRIMARY KEY, value TEXT NOT NULL, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		Up:      migrateSettings,
+	},
+	{
+		Version: 27,
+		Name:    "scheduler_runs",
+		SQL:     `CREATE TABLE IF NOT EXISTS scheduler_runs (job_id TEXT PRIMARY KEY, run_at TEXT NOT NULL)`,
+		Up:      migrateSchedulerRuns,
+	},
+	{
+		Version: 28,
+		Name:    "jobs",
+		SQL:     `CREATE TABLE IF NOT EXISTS jobs (id INTEGER PRIMARY KEY AUTOINCREMENT, type TEXT NOT NULL, payload_json TEXT NOT NULL, state TEXT NOT NULL DEFAULT 'pending', attempts INTEGER NOT NULL DEFAULT 0, max_retries INTEGER NOT NULL DEFAULT 5, run_at TEXT NOT NULL, deadline TEXT, unique_key TEXT, last_error TEXT, completed_at TEXT); CREATE INDEX IF NOT EXISTS idx_jobs_state_run_at ON jobs(state, run_at)`,
+		Up:      migrateJobs,
+	},
+	{
+		Version: 29,
+		Name:    "metadata_cache_ttl_columns",
+		SQL:     `ALTER TABLE metadata_cache ADD COLUMN soft_ttl_seconds INTEGER NOT NULL DEFAULT 0; ALTER TABLE metadata_cache ADD COLUMN hard_ttl_seconds INTEGER NOT NULL DEFAULT 0; ALTER TABLE metadata_cache ADD COLUMN error_count INTEGER NOT NULL DEFAULT 0; ALTER TABLE metadata_cache ADD COLUMN negative BOOLEAN NOT NULL DEFAULT FALSE`,
+		Up:      migrateMetadataCacheTTLColumns,
+	},
+	{
+		Version: 30,
+		Name:    "show_min_quality",
+		SQL:     `ALTER TABLE tv_shows ADD COLUMN min_quality TEXT DEFAULT ''`,
+		Up:      migrateShowMinQuality,
+	},
+	{
+		Version: 31,
+		Name:    "episode_resources",
+		SQL:     `CREATE TABLE IF NOT EXISTS episode_resources (id INTEGER PRIMARY KEY AUTOINCREMENT, episode_id INTEGER NOT NULL, title TEXT NOT NULL, url TEXT NOT NULL, resolution TEXT DEFAULT '', source TEXT DEFAULT '', codec TEXT DEFAULT '', release_group TEXT DEFAULT '', qiangban BOOLEAN DEFAULT FALSE, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, UNIQUE(episode_id, url)); CREATE INDEX IF NOT EXISTS idx_episode_resources_episode_id ON episode_resources(episode_id)`,
+		Up:      migrateEpisodeResources,
+	},
+}
+
+// checksum returns a hex-encoded SHA-256 of a migration's SQL, used to
+// detect whether the code behind an already-applied migration changed.
+func checksum(sqlText string) string {
+	sum := sha256.Sum256([]byte(sqlText))
+	return hex.EncodeToString(sum[:])
+}
+
+// ensureSchemaMigrationsTable creates the ledger table if it doesn't exist.
+func ensureSchemaMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			checksum TEXT NOT NULL
+		)
+	`)
+	return err
+}
+
+// appliedChecksums returns the checksum recorded for each already-applied version.
+func appliedChecksums(db *sql.DB) (map[int]string, error) {
+	rows, err := db.Query(`SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var sum string
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, err
+		}
+		applied[version] = sum
+	}
+	return applied, rows.Err()
+}
+
+// ApplyMigrations runs every pending migration in version order, recording
+// each as it completes. It fails loudly if an already-applied migration's
+// checksum no longer matches the code, since that means history was edited
+// after the fact.
+func ApplyMigrations(db *sql.DB) error {
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedChecksums(db)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		sum := checksum(m.SQL)
+
+		if existingSum, ok := applied[m.Version]; ok {
+			if existingSum != sum {
+				return fmt.Errorf("migration %d (%s) checksum mismatch: recorded %s, code now %s — history was edited after being applied", m.Version, m.Name, existingSum, sum)
+			}
+			continue
+		}
+
+		if err := runMigration(db, m, sum); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// CurrentSchemaVersion returns the version of the newest known migration,
+// for callers (e.g. BackupService) that need to stamp a schema version into
+// data exported outside the database itself.
+func CurrentSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// runMigration executes a single migration inside a transaction and records it.
+func runMigration(db *sql.DB, m Migration, sum string) error {
+	if m.RebuildsTable {
+		if _, err := db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+			return err
+		}
+		defer db.Exec(`PRAGMA foreign_keys = ON`)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`,
+		m.Version, m.Name, sum,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Status returns the applied/pending state of every known migration, newest first omitted — callers get version order.
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedChecksums(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		_, ok := applied[m.Version]
+		statuses = append(statuses, MigrationStatus{Version: m.Version, Name: m.Name, Applied: ok})
+	}
+	return statuses, nil
+}
+
+// Down un-records the latest n applied migrations from the ledger. Our
+// migrations are table-rebuild style without a safe automatic reverse, so
+// this does not undo the schema change itself — it only lets an operator
+// re-run `migrate up` after manually reverting the schema out of band.
+func Down(db *sql.DB, n int) ([]MigrationStatus, error) {
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedChecksums(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	var toUnmark []Migration
+	for i := len(migrations) - 1; i >= 0 && len(toUnmark) < n; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			toUnmark = append(toUnmark, migrations[i])
+		}
+	}
+
+	var reverted []MigrationStatus
+	for _, m := range toUnmark {
+		if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+			return nil, fmt.Errorf("failed to unmark migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, MigrationStatus{Version: m.Version, Name: m.Name, Applied: false})
+	}
+
+	return reverted, nil
+}
+
+// migrateResourceTimeIsManual adds the resource_time_is_manual column
+func migrateResourceTimeIsManual(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT resource_time_is_manual FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	// Create new table with the column
+	if _, err := tx.Exec(`
+		CREATE TABLE tv_shows_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tmdb_id INTEGER UNIQUE NOT NULL,
+			name TEXT NOT NULL,
+			total_seasons INTEGER DEFAULT 1,
+			status TEXT DEFAULT 'Unknown',
+			origin_country TEXT DEFAULT '',
+			resource_time TEXT DEFAULT '待定',
+			resource_time_is_manual BOOLEAN DEFAULT FALSE,
+			is_archived BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO tv_shows_new (
+			id, tmdb_id, name, total_seasons, status, origin_country,
+			resource_time, is_archived, created_at, updated_at
+		)
+		SELECT
+			id, tmdb_id, name, total_seasons, status, origin_country,
+			resource_time, is_archived, created_at, updated_at
+		FROM tv_shows
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DROP TABLE tv_shows`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE tv_shows_new RENAME TO tv_shows`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_shows_tmdb_archived ON tv_shows(tmdb_id, is_archived)`)
+	return err
+}
+
+// migrateMetadataSource adds the metadata_source column for shows created
+// before pluggable metadata providers existed.
+func migrateMetadataSource(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT metadata_source FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	_, err = tx.Exec(`ALTER TABLE tv_shows ADD COLUMN metadata_source TEXT DEFAULT 'tmdb'`)
+	return err
+}
+
+// migrateTMDBCacheCompositeKey rebuilds tmdb_cache with a composite
+// (tmdb_id, language) primary key so responses can be cached per language
+// instead of clobbering each other under a single tmdb_id key.
+func migrateTMDBCacheCompositeKey(tx *sql.Tx) error {
+	var pkColumns int
+	rows, err := tx.Query(`PRAGMA table_info(tmdb_cache)`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if pk > 0 {
+			pkColumns++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Already migrated (composite key has two pk columns: tmdb_id, language).
+	if pkColumns != 1 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`
+		CREATE TABLE tmdb_cache_new (
+			tmdb_id INTEGER NOT NULL,
+			language TEXT NOT NULL,
+			payload_json TEXT NOT NULL,
+			fetched_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (tmdb_id, language)
+		)
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO tmdb_cache_new (tmdb_id, language, payload_json, fetched_at)
+		SELECT tmdb_id, language, payload_json, fetched_at FROM tmdb_cache
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DROP TABLE tmdb_cache`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE tmdb_cache_new RENAME TO tmdb_cache`)
+	return err
+}
+
+// migrateTMDBCacheETagLastHit adds the etag and last_hit columns used for
+// conditional (If-None-Match) refreshes and future cache-eviction policies.
+func migrateTMDBCacheETagLastHit(tx *sql.Tx) error {
+	var etag string
+	err := tx.QueryRow("SELECT etag FROM tmdb_cache LIMIT 1").Scan(&etag)
+	if err != nil && err != sql.ErrNoRows {
+		if _, err := tx.Exec(`ALTER TABLE tmdb_cache ADD COLUMN etag TEXT DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+
+	var lastHit sql.NullString
+	err = tx.QueryRow("SELECT last_hit FROM tmdb_cache LIMIT 1").Scan(&lastHit)
+	if err != nil && err != sql.ErrNoRows {
+		if _, err := tx.Exec(`ALTER TABLE tmdb_cache ADD COLUMN last_hit TIMESTAMP`); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateTMDBCacheSeasonColumn rebuilds tmdb_cache with a season column
+// folded into the primary key, so a show's TV details (season =
+// repository.ShowDetailsSeason) and each season's episode list can be
+// cached independently instead of only one payload per language.
+func migrateTMDBCacheSeasonColumn(tx *sql.Tx) error {
+	var pkColumns int
+	rows, err := tx.Query(`PRAGMA table_info(tmdb_cache)`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if pk > 0 {
+			pkColumns++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Already migrated (season folded into the pk makes it three columns).
+	if pkColumns != 2 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`
+		CREATE TABLE tmdb_cache_new (
+			tmdb_id INTEGER NOT NULL,
+			language TEXT NOT NULL,
+			season INTEGER NOT NULL DEFAULT -1,
+			payload_json TEXT NOT NULL,
+			etag TEXT DEFAULT '',
+			fetched_at TIMESTAMP NOT NULL,
+			last_hit TIMESTAMP,
+			PRIMARY KEY (tmdb_id, language, season)
+		)
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO tmdb_cache_new (tmdb_id, language, season, payload_json, etag, fetched_at, last_hit)
+		SELECT tmdb_id, language, -1, payload_json, etag, fetched_at, last_hit FROM tmdb_cache
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DROP TABLE tmdb_cache`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE tmdb_cache_new RENAME TO tmdb_cache`)
+	return err
+}
+
+// migrateEpisodesTMDBAirDateIndex adds the index GetActiveQueue relies on to
+// find each show's earliest unwatched episode without a full table scan.
+func migrateEpisodesTMDBAirDateIndex(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_episodes_tmdb_air_date ON episodes(tmdb_id, air_date)`)
+	return err
+}
+
+// migrateShowPolicyFields adds the per-show download/notify override
+// columns TVShow.Policy() reads. All four default to the zero value, which
+// Dispatcher treats as "no override, fall back to global behavior".
+func migrateShowPolicyFields(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT quality_filter FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	for _, stmt := range []string{
+		`ALTER TABLE tv_shows ADD COLUMN quality_filter TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN preferred_resolution TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN notify_channel TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN auto_skip_specials BOOLEAN DEFAULT FALSE`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateWebhookSubscriptions creates the webhook_subscriptions table used
+// by the WebSub-style webhook registration flow.
+func migrateWebhookSubscriptions(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			callback_url TEXT NOT NULL,
+			topic TEXT NOT NULL,
+			secret TEXT DEFAULT '',
+			lease_seconds INTEGER DEFAULT 0,
+			verified_at TIMESTAMP,
+			expires_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// migrateSkippedEpisodes creates the skipped_episodes table used to mark
+// individual specials (season 0) as ignored, matched by explicit episode
+// code rather than by count since specials are often non-contiguous.
+func migrateSkippedEpisodes(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS skipped_episodes (
+			tmdb_id INTEGER NOT NULL,
+			episode_code TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (tmdb_id, episode_code)
+		)
+	`)
+	return err
+}
+
+// migrateShowSearchMetadata adds the original_name, overview, and genres
+// columns used to populate shows_fts for databases created before library
+// search existed.
+func migrateShowSearchMetadata(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT original_name FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE tv_shows ADD COLUMN original_name TEXT DEFAULT ''`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE tv_shows ADD COLUMN overview TEXT DEFAULT ''`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`ALTER TABLE tv_shows ADD COLUMN genres TEXT DEFAULT ''`)
+	return err
+}
+
+// migrateAPIKeys creates the api_keys table used by internal/auth to persist
+// issued key credentials for databases created before key authentication
+// existed.
+func migrateAPIKeys(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			owner_label TEXT NOT NULL,
+			secret_hash TEXT NOT NULL,
+			salt TEXT NOT NULL,
+			scopes INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP,
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// migrateWatchedEpisodes creates the watched_episodes table used by
+// EpisodeRepository.MarkSeasonWatched/MarkRangeWatched for databases created
+// before bulk watch-marking existed.
+func migrateWatchedEpisodes(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS watched_episodes (
+			tmdb_id INTEGER NOT NULL,
+			episode_code TEXT NOT NULL,
+			watched_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (tmdb_id, episode_code)
+		)
+	`)
+	return err
+}
+
+// migrateSyncRuns creates the ledger table the scheduled TMDB refresh and
+// task-generation jobs record themselves into, so last-run status survives
+// a restart.
+func migrateSyncRuns(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS sync_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_name TEXT NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP NOT NULL,
+			shows_refreshed INTEGER NOT NULL DEFAULT 0,
+			errors INTEGER NOT NULL DEFAULT 0,
+			triggered_by TEXT NOT NULL DEFAULT 'schedule'
+		)
+	`); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_sync_runs_job_name ON sync_runs(job_name, finished_at)`)
+	return err
+}
+
+// migrateShowReleasePreferences adds the per-show quality/codec/group
+// preference fields used to annotate generated UPDATE task descriptions.
+func migrateShowReleasePreferences(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT preferred_quality FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	for _, stmt := range []string{
+		`ALTER TABLE tv_shows ADD COLUMN preferred_quality TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN preferred_codec TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN preferred_group TEXT DEFAULT ''`,
+		`ALTER TABLE tv_shows ADD COLUMN reject_cam BOOLEAN DEFAULT TRUE`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateScheduleRules creates the schedule_rules table used by
+// service/scheduler.TaskScheduler to generate tasks on a cron schedule.
+func migrateScheduleRules(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schedule_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			cron_expr TEXT NOT NULL,
+			task_type TEXT NOT NULL,
+			selector TEXT NOT NULL,
+			threshold_days INTEGER NOT NULL DEFAULT 0,
+			description_template TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE
+		)
+	`)
+	return err
+}
+
+// migrateTaskAudit creates the task_audit table used by
+// TaskBoardService.UndoLastAction to reverse a bulk complete/postpone.
+func migrateTaskAudit(tx *sql.Tx) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS task_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			task_id INTEGER NOT NULL,
+			new_task_id INTEGER,
+			prior_task_json TEXT NOT NULL,
+			prior_show_archived BOOLEAN,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_audit_action_id ON task_audit(action_id)`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTMDBCacheLastModified adds the last_modified column so conditional
+// refreshes can send If-Modified-Since alongside If-None-Match.
+func migrateTMDBCacheLastModified(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE tmdb_cache ADD COLUMN last_modified TEXT DEFAULT ''`)
+	return err
+}
+
+// migrateMetadataCacheProviderKey generalizes tmdb_cache (keyed on an
+// integer TMDB ID) into metadata_cache, keyed on (provider_id, external_id)
+// so a future TVDB/AniList/Trakt provider can share the same cache table
+// instead of each needing its own. Existing rows are backfilled with
+// provider_id = "tmdb" and external_id = the stringified tmdb_id.
+func migrateMetadataCacheProviderKey(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE metadata_cache (
+			provider_id TEXT NOT NULL,
+			external_id TEXT NOT NULL,
+			language TEXT NOT NULL,
+			season INTEGER NOT NULL DEFAULT -1,
+			payload_json TEXT NOT NULL,
+			etag TEXT DEFAULT '',
+			last_modified TEXT DEFAULT '',
+			fetched_at TIMESTAMP NOT NULL,
+			last_hit TIMESTAMP,
+			PRIMARY KEY (provider_id, external_id, language, season)
+		)
+	`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO metadata_cache (provider_id, external_id, language, season, payload_json, etag, last_modified, fetched_at, last_hit)
+		SELECT 'tmdb', CAST(tmdb_id AS TEXT), language, season, payload_json, etag, last_modified, fetched_at, last_hit FROM tmdb_cache
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DROP TABLE tmdb_cache`)
+	return err
+}
+
+// migrateShowExternalRefs adds a table of secondary-provider cross
+// references for a show, e.g. its AniList or Trakt ID, alongside the
+// primary tv_shows.tmdb_id/metadata_source identity.
+func migrateShowExternalRefs(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS show_external_refs (
+			tv_show_id INTEGER NOT NULL,
+			provider_id TEXT NOT NULL,
+			external_id TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (tv_show_id, provider_id),
+			FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+		)
+	`)
+	return err
+}
+
+// migrateBotUsers adds the chat ID allow-list backing Telegram bot
+// authorization (see repository.BotUserRepository): each row pins one chat
+// to a role (owner/subscriber/read-only), replacing the old single
+// hard-coded admin chat ID.
+func migrateBotUsers(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS bot_users (
+			chat_id INTEGER PRIMARY KEY,
+			username TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// migrateShowSubscribers adds a join table scoping which chat subscribed to
+// which show, so a multi-user bot can fan out per-recipient reports instead
+// of every user seeing every subscription.
+func migrateShowSubscribers(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS show_subscribers (
+			tv_show_id INTEGER NOT NULL,
+			chat_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (tv_show_id, chat_id),
+			FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+		)
+	`)
+	return err
+}
+
+func migrateShowPreferences(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS show_preferences (
+			chat_id INTEGER NOT NULL,
+			tv_show_id INTEGER NOT NULL,
+			notify_enabled BOOLEAN NOT NULL DEFAULT 1,
+			include_in_daily_report BOOLEAN NOT NULL DEFAULT 1,
+			resource_time_override TEXT NOT NULL DEFAULT '',
+			title_language TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (chat_id, tv_show_id),
+			FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+		)
+	`)
+	return err
+}
+
+// migrateSettings adds a small key/value store for runtime-configurable
+// settings that need to survive a restart, e.g. a TMDB API key rotated live
+// via the Telegram bot instead of the TMDB_API_KEY env var (see
+// repository.SettingsRepository and tmdb.Client.SetAPIKey).
+func migrateSettings(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// migrateSchedulerRuns adds the last-run ledger consulted by
+// service.Scheduler's catch-up window on startup (see
+// repository.SchedulerRunRepository).
+func migrateSchedulerRuns(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduler_runs (
+			job_id TEXT PRIMARY KEY,
+			run_at TEXT NOT NULL
+		)
+	`)
+	return err
+}
+
+// migrateMetadataCacheTTLColumns adds the columns TMDBCacheService needs to
+// classify a cache row without a caller-supplied TTL: a per-row soft/hard
+// TTL override (used by negative caching, whose TTL is much shorter than a
+// normal entry's), an error_count for tracking repeated upstream failures,
+// and a negative flag marking a cached "this ID doesn't exist" result.
+func migrateMetadataCacheTTLColumns(tx *sql.Tx) error {
+	for _, stmt := range []string{
+		`ALTER TABLE metadata_cache ADD COLUMN soft_ttl_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE metadata_cache ADD COLUMN hard_ttl_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE metadata_cache ADD COLUMN error_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE metadata_cache ADD COLUMN negative BOOLEAN NOT NULL DEFAULT FALSE`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateShowMinQuality adds the per-show resolution floor enforced by
+// Dispatcher.Accepts for databases created before it existed.
+func migrateShowMinQuality(tx *sql.Tx) error {
+	var result string
+	err := tx.QueryRow("SELECT min_quality FROM tv_shows LIMIT 1").Scan(&result)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	_, err = tx.Exec(`ALTER TABLE tv_shows ADD COLUMN min_quality TEXT DEFAULT ''`)
+	return err
+}
+
+// migrateEpisodeResources creates the table backing
+// SubscriptionManager.AttachResource, for databases created before
+// episode-level resource tracking existed.
+func migrateEpisodeResources(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS episode_resources (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			url TEXT NOT NULL,
+			resolution TEXT DEFAULT '',
+			source TEXT DEFAULT '',
+			codec TEXT DEFAULT '',
+			release_group TEXT DEFAULT '',
+			qiangban BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(episode_id, url)
+		)
+	`); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_episode_resources_episode_id ON episode_resources(episode_id)`)
+	return err
+}
+
+// migrateJobs creates the durable task queue table used by the jobs
+// package, plus the index its claim query relies on.
+func migrateJobs(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			payload_json TEXT NOT NULL,
+			state TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_retries INTEGER NOT NULL DEFAULT 5,
+			run_at TEXT NOT NULL,
+			deadline TEXT,
+			unique_key TEXT,
+			last_error TEXT,
+			completed_at TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_jobs_state_run_at ON jobs(state, run_at)
+	`)
+	return err
+}
+
+// migrateTaskPriorityDueDate adds the priority and due_date columns used by
+// the dashboard's composite sort (see TaskBoardService.GetDashboardData).
+func migrateTaskPriorityDueDate(tx *sql.Tx) error {
+	var priority int
+	err := tx.QueryRow("SELECT priority FROM tasks LIMIT 1").Scan(&priority)
+	if err == nil || err == sql.ErrNoRows {
+		return nil
+	}
+
+	for _, stmt := range []string{
+		`ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE tasks ADD COLUMN due_date TIMESTAMP`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateShowsFTS creates the shows_fts/shows_fts_trigram virtual tables and
+// their sync triggers (a no-op if InitSchema already created them for a
+// fresh database) and backfills both indexes for any rows that existed
+// before the triggers did.
+func migrateShowsFTS(tx *sql.Tx) error {
+	if _, err := tx.Exec(showsFTSSchema); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO shows_fts(shows_fts) VALUES ('rebuild')`); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO shows_fts_trigram(shows_fts_trigram) VALUES ('rebuild')`)
+	return err
+}
+
+// legacyEpisodePrefixRe extracts the "SxxExx" prefix task_generator used to
+// pack into the front of Description (e.g. "S01E05|新剧集更新: ...") before
+// Task.EpisodeID existed, so existing rows can be backfilled.
+var legacyEpisodePrefixRe = regexp.MustCompile(`^(S\d{1,2}E\d{1,3})\|`)
+
+// migrateTasksDedupKey adds Task.EpisodeID and the content-addressed
+// dedup_key column, backfilling both for existing rows from the legacy
+// "SxxExx|description" convention, then collapses any rows that turn out to
+// share a dedup_key (true duplicates the old "count and hope" idempotence
+// check let through) before the unique index is created.
+func migrateTasksDedupKey(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE tasks ADD COLUMN episode_id TEXT DEFAULT ''`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE tasks ADD COLUMN dedup_key TEXT DEFAULT ''`); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id, tv_show_id, task_type, description FROM tasks`)
+	if err != nil {
+		return err
+	}
+	type taskRow struct {
+		id       int64
+		showID   int64
+		taskType string
+		desc     string
+	}
+	var all []taskRow
+	for rows.Next() {
+		var t taskRow
+		if err := rows.Scan(&t.id, &t.showID, &t.taskType, &t.desc); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, t)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	seen := make(map[string]bool, len(all))
+	for _, t := range all {
+		episodeID := ""
+		if m := legacyEpisodePrefixRe.FindStringSubmatch(t.desc); m != nil {
+			episodeID = m[1]
+		}
+		key := proto.TaskPayload{TaskType: t.taskType, TVShowID: t.showID, EpisodeID: episodeID}.DedupKey()
+
+		if seen[key] {
+			// A true duplicate the old existence check let through; drop it
+			// rather than fail the new unique index on stale data.
+			if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, t.id); err != nil {
+				return err
+			}
+			continue
+		}
+		seen[key] = true
+
+		if _, err := tx.Exec(`UPDATE tasks SET episode_id = ?, dedup_key = ? WHERE id = ?`, episodeID, key, t.id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_dedup_key ON tasks(dedup_key) WHERE dedup_key != ''`)
+	return err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+
+	"tv-tracker/internal/models"
+)
+
+// ScheduleRule is a recurring task-generation rule: on CronExpr, TaskScheduler
+// runs Selector against the show library and materializes a TaskType task
+// (via DescriptionTemplate, with "{{show}}" replaced by the show name) for
+// every match, deduplicated by TaskRepository.Create's dedup key. Rule tasks
+// carry no EpisodeID, so the key is just show+type, and an existing task for
+// it, pending or completed, blocks another.
+type ScheduleRule struct {
+	ID                  int64
+	Name                string
+	CronExpr            string
+	TaskType            models.TaskType
+	Selector            string
+	ThresholdDays       int
+	DescriptionTemplate string
+	Enabled             bool
+}
+
+// ScheduleRuleRepository handles ScheduleRule database operations.
+type ScheduleRuleRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleRuleRepository creates a new ScheduleRuleRepository.
+func NewScheduleRuleRepository(sqliteDB *SQLiteDB) *ScheduleRuleRepository {
+	return &ScheduleRuleRepository{db: sqliteDB.db}
+}
+
+// Create inserts a new ScheduleRule.
+func (r *ScheduleRuleRepository) Create(rule *ScheduleRule) error {
+	result, err := r.db.Exec(`
+		INSERT INTO schedule_rules (name, cron_expr, task_type, selector, threshold_days, description_template, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.CronExpr, rule.TaskType, rule.Selector, rule.ThresholdDays, rule.DescriptionTemplate, rule.Enabled)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rule.ID = id
+	return nil
+}
+
+// GetEnabled returns every enabled ScheduleRule.
+func (r *ScheduleRuleRepository) GetEnabled() ([]ScheduleRule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, cron_expr, task_type, selector, threshold_days, description_template, enabled
+		FROM schedule_rules
+		WHERE enabled = TRUE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ScheduleRule
+	for rows.Next() {
+		var rule ScheduleRule
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.CronExpr, &rule.TaskType, &rule.Selector, &rule.ThresholdDays, &rule.DescriptionTemplate, &rule.Enabled); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetAll returns every ScheduleRule, enabled or not.
+func (r *ScheduleRuleRepository) GetAll() ([]ScheduleRule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, cron_expr, task_type, selector, threshold_days, description_template, enabled
+		FROM schedule_rules
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ScheduleRule
+	for rows.Next() {
+		var rule ScheduleRule
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.CronExpr, &rule.TaskType, &rule.Selector, &rule.ThresholdDays, &rule.DescriptionTemplate, &rule.Enabled); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// SetEnabled enables or disables a rule without touching its other fields.
+func (r *ScheduleRuleRepository) SetEnabled(id int64, enabled bool) error {
+	_, err := r.db.Exec(`UPDATE schedule_rules SET enabled = ? WHERE id = ?`, enabled, id)
+	return err
+}
+
+// Delete removes a rule.
+func (r *ScheduleRuleRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM schedule_rules WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import "database/sql"
+
+// SchedulerRunRepository tracks the last successful run of each job
+// registered with service.Scheduler, so a catch-up window can tell whether
+// a job was missed while the process was down (see
+// service.Scheduler.Register).
+type SchedulerRunRepository struct {
+	db *sql.DB
+}
+
+// NewSchedulerRunRepository creates a new SchedulerRunRepository.
+func NewSchedulerRunRepository(sqliteDB *SQLiteDB) *SchedulerRunRepository {
+	return &SchedulerRunRepository{db: sqliteDB.db}
+}
+
+// GetLastRun returns the last recorded run_at for jobID, or (zero, false) if
+// it has never run.
+func (r *SchedulerRunRepository) GetLastRun(jobID string) (string, bool, error) {
+	var runAt string
+	err := r.db.QueryRow(`SELECT run_at FROM scheduler_runs WHERE job_id = ?`, jobID).Scan(&runAt)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return runAt, true, nil
+}
+
+// SetLastRun upserts jobID's last run_at.
+func (r *SchedulerRunRepository) SetLastRun(jobID, runAt string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO scheduler_runs (job_id, run_at)
+		VALUES (?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET run_at = excluded.run_at
+	`, jobID, runAt)
+	return err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import "database/sql"
+
+// SettingKeyTMDBAPIKey is the settings row key under which the
+// runtime-rotated TMDB API key is persisted (see tmdb.Client.SetAPIKey).
+const SettingKeyTMDBAPIKey = "tmdb_api_key"
+
+// SettingsRepository stores small runtime-configurable key/value settings
+// that should survive a restart without going through an env var, e.g. a
+// TMDB API key rotated live via the Telegram bot.
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository creates a new SettingsRepository.
+func NewSettingsRepository(sqliteDB *SQLiteDB) *SettingsRepository {
+	return &SettingsRepository{db: sqliteDB.db}
+}
+
+// Get returns the value stored under key, or ("", false) if it has never
+// been set.
+func (r *SettingsRepository) Get(key string) (string, bool, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Set upserts key's value.
+func (r *SettingsRepository) Set(key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO settings (key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value)
+	return err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import "database/sql"
+
+// ShowExternalRef is a secondary-provider cross reference for a show, e.g.
+// its AniList or Trakt ID, kept alongside the show's primary identity
+// (TVShow.TMDBID/MetadataSource) so a show pinned to one metadata.Provider
+// can still be looked up by another.
+type ShowExternalRef struct {
+	TVShowID   int64
+	ProviderID string
+	ExternalID string
+}
+
+// ShowExternalRefRepository stores secondary metadata.Provider cross
+// references for a show.
+type ShowExternalRefRepository struct {
+	db *sql.DB
+}
+
+// NewShowExternalRefRepository creates a new ShowExternalRefRepository.
+func NewShowExternalRefRepository(sqliteDB *SQLiteDB) *ShowExternalRefRepository {
+	return &ShowExternalRefRepository{db: sqliteDB.db}
+}
+
+// Set records (or replaces) a show's external ID on a provider.
+func (r *ShowExternalRefRepository) Set(showID int64, providerID, externalID string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO show_external_refs (tv_show_id, provider_id, external_id)
+		VALUES (?, ?, ?)
+		ON CONFLICT(tv_show_id, provider_id) DO UPDATE SET external_id = excluded.external_id
+	`, showID, providerID, externalID)
+	return err
+}
+
+// GetAll returns every secondary-provider cross reference recorded for a show.
+func (r *ShowExternalRefRepository) GetAll(showID int64) ([]ShowExternalRef, error) {
+	rows, err := r.db.Query(`
+		SELECT tv_show_id, provider_id, external_id FROM show_external_refs WHERE tv_show_id = ?
+	`, showID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []ShowExternalRef
+	for rows.Next() {
+		var ref ShowExternalRef
+		if err := rows.Scan(&ref.TVShowID, &ref.ProviderID, &ref.ExternalID); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// Delete removes a show's cross reference for a provider.
+func (r *ShowExternalRefRepository) Delete(showID int64, providerID string) error {
+	_, err := r.db.Exec(`DELETE FROM show_external_refs WHERE tv_show_id = ? AND provider_id = ?`, showID, providerID)
+	return err
+}
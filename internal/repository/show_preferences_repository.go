@@ -0,0 +1,135 @@
+package repository
+
+import "database/sql"
+
+// ShowPreferences are one (chat, show) pair's Telegram bot settings. They're
+// kept separate from TVShow's own NotifyChannel/ResourceTime columns because
+// ShowSubscriberRepository now lets more than one chat subscribe to the same
+// show, and each chat wants its own notification and override settings.
+type ShowPreferences struct {
+	ChatID               int64
+	TVShowID             int64
+	NotifyEnabled        bool
+	IncludeInDailyReport bool
+	ResourceTimeOverride string // "" inherits TVShow.ResourceTime
+	TitleLanguage        string // "" inherits the show's own title language
+}
+
+// defaultShowPreferences is what a (chat, show) pair starts with before any
+// settings button has been tapped: notified and included in the daily
+// report, no overrides.
+func defaultShowPreferences(chatID, showID int64) ShowPreferences {
+	return ShowPreferences{
+		ChatID:               chatID,
+		TVShowID:             showID,
+		NotifyEnabled:        true,
+		IncludeInDailyReport: true,
+	}
+}
+
+// ShowPreferencesRepository stores each chat's per-show Telegram bot
+// settings, toggled from the subscription settings submenu.
+type ShowPreferencesRepository struct {
+	db *sql.DB
+}
+
+// NewShowPreferencesRepository creates a new ShowPreferencesRepository.
+func NewShowPreferencesRepository(sqliteDB *SQLiteDB) *ShowPreferencesRepository {
+	return &ShowPreferencesRepository{db: sqliteDB.db}
+}
+
+// Get returns chatID's preferences for showID, defaulted if no row exists yet.
+func (r *ShowPreferencesRepository) Get(chatID, showID int64) (ShowPreferences, error) {
+	var p ShowPreferences
+	err := r.db.QueryRow(`
+		SELECT chat_id, tv_show_id, notify_enabled, include_in_daily_report, resource_time_override, title_language
+		FROM show_preferences WHERE chat_id = ? AND tv_show_id = ?
+	`, chatID, showID).Scan(&p.ChatID, &p.TVShowID, &p.NotifyEnabled, &p.IncludeInDailyReport, &p.ResourceTimeOverride, &p.TitleLanguage)
+	if err == sql.ErrNoRows {
+		return defaultShowPreferences(chatID, showID), nil
+	}
+	if err != nil {
+		return ShowPreferences{}, err
+	}
+	return p, nil
+}
+
+func (r *ShowPreferencesRepository) upsert(p ShowPreferences) error {
+	_, err := r.db.Exec(`
+		INSERT INTO show_preferences (chat_id, tv_show_id, notify_enabled, include_in_daily_report, resource_time_override, title_language)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chat_id, tv_show_id) DO UPDATE SET
+			notify_enabled = excluded.notify_enabled,
+			include_in_daily_report = excluded.include_in_daily_report,
+			resource_time_override = excluded.resource_time_override,
+			title_language = excluded.title_language
+	`, p.ChatID, p.TVShowID, p.NotifyEnabled, p.IncludeInDailyReport, p.ResourceTimeOverride, p.TitleLanguage)
+	return err
+}
+
+// ToggleNotify flips notify_enabled and returns the new value.
+func (r *ShowPreferencesRepository) ToggleNotify(chatID, showID int64) (bool, error) {
+	p, err := r.Get(chatID, showID)
+	if err != nil {
+		return false, err
+	}
+	p.NotifyEnabled = !p.NotifyEnabled
+	return p.NotifyEnabled, r.upsert(p)
+}
+
+// ToggleDailyReport flips include_in_daily_report and returns the new value.
+func (r *ShowPreferencesRepository) ToggleDailyReport(chatID, showID int64) (bool, error) {
+	p, err := r.Get(chatID, showID)
+	if err != nil {
+		return false, err
+	}
+	p.IncludeInDailyReport = !p.IncludeInDailyReport
+	return p.IncludeInDailyReport, r.upsert(p)
+}
+
+// resourceTimeOverrideCycle is the fixed rotation CycleResourceTimeOverride
+// steps through: "" (inherit the show's own resource time), then the same
+// slots InferResourceTime assigns by origin country.
+var resourceTimeOverrideCycle = []string{"", "18:00", "20:00", "23:00"}
+
+// CycleResourceTimeOverride advances resource_time_override to the next
+// value in resourceTimeOverrideCycle and returns it.
+func (r *ShowPreferencesRepository) CycleResourceTimeOverride(chatID, showID int64) (string, error) {
+	p, err := r.Get(chatID, showID)
+	if err != nil {
+		return "", err
+	}
+	p.ResourceTimeOverride = nextInCycle(resourceTimeOverrideCycle, p.ResourceTimeOverride)
+	return p.ResourceTimeOverride, r.upsert(p)
+}
+
+// titleLanguageCycle is the fixed rotation CycleTitleLanguage steps
+// through: "" (inherit the show's own title language) then a few commonly
+// requested ones.
+var titleLanguageCycle = []string{"", "zh-CN", "en-US", "ja-JP"}
+
+// CycleTitleLanguage advances title_language to the next value in
+// titleLanguageCycle and returns it.
+func (r *ShowPreferencesRepository) CycleTitleLanguage(chatID, showID int64) (string, error) {
+	p, err := r.Get(chatID, showID)
+	if err != nil {
+		return "", err
+	}
+	p.TitleLanguage = nextInCycle(titleLanguageCycle, p.TitleLanguage)
+	return p.TitleLanguage, r.upsert(p)
+}
+
+func nextInCycle(cycle []string, current string) string {
+	for i, v := range cycle {
+		if v == current {
+			return cycle[(i+1)%len(cycle)]
+		}
+	}
+	return cycle[0]
+}
+
+// Delete removes chatID's preferences for showID, e.g. once they unsubscribe.
+func (r *ShowPreferencesRepository) Delete(chatID, showID int64) error {
+	_, err := r.db.Exec(`DELETE FROM show_preferences WHERE chat_id = ? AND tv_show_id = ?`, chatID, showID)
+	return err
+}
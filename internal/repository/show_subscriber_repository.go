@@ -0,0 +1,69 @@
+package repository
+
+import "database/sql"
+
+// ShowSubscriberRepository records which chat subscribed to which show, so
+// a multi-user TelegramBot can scope a subscription to the chat that
+// created it rather than treating the library as a single shared list.
+type ShowSubscriberRepository struct {
+	db *sql.DB
+}
+
+// NewShowSubscriberRepository creates a new ShowSubscriberRepository.
+func NewShowSubscriberRepository(sqliteDB *SQLiteDB) *ShowSubscriberRepository {
+	return &ShowSubscriberRepository{db: sqliteDB.db}
+}
+
+// Add records that chatID subscribes to showID. Re-adding an existing pair
+// is a no-op.
+func (r *ShowSubscriberRepository) Add(showID, chatID int64) error {
+	_, err := r.db.Exec(`
+		INSERT INTO show_subscribers (tv_show_id, chat_id) VALUES (?, ?)
+		ON CONFLICT(tv_show_id, chat_id) DO NOTHING
+	`, showID, chatID)
+	return err
+}
+
+// Remove drops chatID's subscription to showID.
+func (r *ShowSubscriberRepository) Remove(showID, chatID int64) error {
+	_, err := r.db.Exec(`DELETE FROM show_subscribers WHERE tv_show_id = ? AND chat_id = ?`, showID, chatID)
+	return err
+}
+
+// GetShowIDsForChat returns every show ID chatID subscribes to.
+func (r *ShowSubscriberRepository) GetShowIDsForChat(chatID int64) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT tv_show_id FROM show_subscribers WHERE chat_id = ?`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetChatIDsForShow returns every chat subscribed to showID.
+func (r *ShowSubscriberRepository) GetChatIDsForShow(showID int64) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT chat_id FROM show_subscribers WHERE tv_show_id = ?`, showID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
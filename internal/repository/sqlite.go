@@ -2,8 +2,12 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
+	// Library search (shows_fts, see InitSchema) requires FTS5, which
+	// mattn/go-sqlite3 only compiles in under the "sqlite_fts5" build tag:
+	//   go build -tags sqlite_fts5 ./...
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -33,6 +37,13 @@ func (s *SQLiteDB) Close() error {
 	return s.db.Close()
 }
 
+// DB returns the underlying *sql.DB, for callers (e.g. BackupService) that
+// need to operate on the connection pool directly rather than through a
+// repository.
+func (s *SQLiteDB) DB() *sql.DB {
+	return s.db
+}
+
 // InitSchema creates the database tables and runs migrations
 func (s *SQLiteDB) InitSchema() error {
 	// Create tables
@@ -41,12 +52,25 @@ func (s *SQLiteDB) InitSchema() error {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		tmdb_id INTEGER UNIQUE NOT NULL,
 		name TEXT NOT NULL,
+		original_name TEXT DEFAULT '',
+		overview TEXT DEFAULT '',
+		genres TEXT DEFAULT '',
 		total_seasons INTEGER DEFAULT 1,
 		status TEXT DEFAULT 'Unknown',
 		origin_country TEXT DEFAULT '',
 		resource_time TEXT DEFAULT '待定',
 		resource_time_is_manual BOOLEAN DEFAULT FALSE,
+		metadata_source TEXT DEFAULT 'tmdb',
 		is_archived BOOLEAN DEFAULT FALSE,
+		preferred_quality TEXT DEFAULT '',
+		preferred_codec TEXT DEFAULT '',
+		preferred_group TEXT DEFAULT '',
+		reject_cam BOOLEAN DEFAULT TRUE,
+		min_quality TEXT DEFAULT '',
+		quality_filter TEXT DEFAULT '',
+		preferred_resolution TEXT DEFAULT '',
+		notify_channel TEXT DEFAULT '',
+		auto_skip_specials BOOLEAN DEFAULT FALSE,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
@@ -66,17 +90,175 @@ func (s *SQLiteDB) InitSchema() error {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		tv_show_id INTEGER NOT NULL,
 		task_type TEXT NOT NULL,
+		episode_id TEXT DEFAULT '',
 		description TEXT NOT NULL,
+		dedup_key TEXT DEFAULT '',
 		is_completed BOOLEAN DEFAULT FALSE,
+		priority INTEGER NOT NULL DEFAULT 0,
+		due_date TIMESTAMP,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
 	);
 
-	CREATE TABLE IF NOT EXISTS tmdb_cache (
-		tmdb_id INTEGER PRIMARY KEY,
+	CREATE TABLE IF NOT EXISTS metadata_cache (
+		provider_id TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		language TEXT NOT NULL,
+		season INTEGER NOT NULL DEFAULT -1,
 		payload_json TEXT NOT NULL,
+		etag TEXT DEFAULT '',
+		last_modified TEXT DEFAULT '',
 		fetched_at TIMESTAMP NOT NULL,
-		language TEXT NOT NULL
+		last_hit TIMESTAMP,
+		soft_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+		hard_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+		error_count INTEGER NOT NULL DEFAULT 0,
+		negative BOOLEAN NOT NULL DEFAULT FALSE,
+		PRIMARY KEY (provider_id, external_id, language, season)
+	);
+
+	CREATE TABLE IF NOT EXISTS show_external_refs (
+		tv_show_id INTEGER NOT NULL,
+		provider_id TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tv_show_id, provider_id),
+		FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS bot_users (
+		chat_id INTEGER PRIMARY KEY,
+		username TEXT NOT NULL DEFAULT '',
+		role TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS show_subscribers (
+		tv_show_id INTEGER NOT NULL,
+		chat_id INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tv_show_id, chat_id),
+		FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS show_preferences (
+		chat_id INTEGER NOT NULL,
+		tv_show_id INTEGER NOT NULL,
+		notify_enabled BOOLEAN NOT NULL DEFAULT 1,
+		include_in_daily_report BOOLEAN NOT NULL DEFAULT 1,
+		resource_time_override TEXT NOT NULL DEFAULT '',
+		title_language TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (chat_id, tv_show_id),
+		FOREIGN KEY (tv_show_id) REFERENCES tv_shows(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS scheduler_runs (
+		job_id TEXT PRIMARY KEY,
+		run_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		payload_json TEXT NOT NULL,
+		state TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_retries INTEGER NOT NULL DEFAULT 5,
+		run_at TEXT NOT NULL,
+		deadline TEXT,
+		unique_key TEXT,
+		last_error TEXT,
+		completed_at TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_jobs_state_run_at ON jobs(state, run_at);
+
+	CREATE TABLE IF NOT EXISTS skipped_episodes (
+		tmdb_id INTEGER NOT NULL,
+		episode_code TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tmdb_id, episode_code)
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		callback_url TEXT NOT NULL,
+		topic TEXT NOT NULL,
+		secret TEXT DEFAULT '',
+		lease_seconds INTEGER DEFAULT 0,
+		verified_at TIMESTAMP,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS watched_episodes (
+		tmdb_id INTEGER NOT NULL,
+		episode_code TEXT NOT NULL,
+		watched_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (tmdb_id, episode_code)
+	);
+
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner_label TEXT NOT NULL,
+		secret_hash TEXT NOT NULL,
+		salt TEXT NOT NULL,
+		scopes INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP,
+		expires_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS task_audit (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		task_id INTEGER NOT NULL,
+		new_task_id INTEGER,
+		prior_task_json TEXT NOT NULL,
+		prior_show_archived BOOLEAN,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS schedule_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		cron_expr TEXT NOT NULL,
+		task_type TEXT NOT NULL,
+		selector TEXT NOT NULL,
+		threshold_days INTEGER NOT NULL DEFAULT 0,
+		description_template TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE
+	);
+
+	CREATE TABLE IF NOT EXISTS sync_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_name TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NOT NULL,
+		shows_refreshed INTEGER NOT NULL DEFAULT 0,
+		errors INTEGER NOT NULL DEFAULT 0,
+		triggered_by TEXT NOT NULL DEFAULT 'schedule'
+	);
+
+	CREATE TABLE IF NOT EXISTS episode_resources (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		episode_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		url TEXT NOT NULL,
+		resolution TEXT DEFAULT '',
+		source TEXT DEFAULT '',
+		codec TEXT DEFAULT '',
+		release_group TEXT DEFAULT '',
+		qiangban BOOLEAN DEFAULT FALSE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(episode_id, url)
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_episodes_air_date ON episodes(air_date);
@@ -86,94 +268,84 @@ func (s *SQLiteDB) InitSchema() error {
 	
 	-- 复合索引优化 JOIN 查询性能
 	CREATE INDEX IF NOT EXISTS idx_episodes_air_date_tmdb ON episodes(air_date, tmdb_id);
+	CREATE INDEX IF NOT EXISTS idx_episodes_tmdb_air_date ON episodes(tmdb_id, air_date);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_dedup_key ON tasks(dedup_key) WHERE dedup_key != '';
 	CREATE INDEX IF NOT EXISTS idx_shows_tmdb_archived ON tv_shows(tmdb_id, is_archived);
 	CREATE INDEX IF NOT EXISTS idx_tasks_show_completed ON tasks(tv_show_id, is_completed);
+	CREATE INDEX IF NOT EXISTS idx_sync_runs_job_name ON sync_runs(job_name, finished_at);
+	CREATE INDEX IF NOT EXISTS idx_task_audit_action_id ON task_audit(action_id);
+	CREATE INDEX IF NOT EXISTS idx_episode_resources_episode_id ON episode_resources(episode_id);
 	`
 
-	if _, err := s.db.Exec(schema); err != nil {
+	if err := ensureFTS5(s.db); err != nil {
 		return err
 	}
 
-	// Run migrations
-	return s.runMigrations()
-}
-
-// runMigrations executes pending database migrations
-func (s *SQLiteDB) runMigrations() error {
-	// Check if resource_time_is_manual column exists
-	var result string
-	err := s.db.QueryRow("SELECT resource_time_is_manual FROM tv_shows LIMIT 1").Scan(&result)
-
-	if err != nil {
-		// Column doesn't exist, need to migrate
-		return s.migrateResourceTimeIsManual()
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
 	}
 
-	return nil
+	// Run the versioned migration ledger. This also fails loudly if an
+	// already-applied migration's checksum no longer matches the code. The
+	// show_search_metadata and shows_fts migrations add the columns and
+	// virtual table/triggers that library search depends on.
+	return ApplyMigrations(s.db)
 }
 
-// migrateResourceTimeIsManual adds the resource_time_is_manual column
-func (s *SQLiteDB) migrateResourceTimeIsManual() error {
-	// Start transaction
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Create new table with the column
-	_, err = tx.Exec(`
-		CREATE TABLE tv_shows_new (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			tmdb_id INTEGER UNIQUE NOT NULL,
-			name TEXT NOT NULL,
-			total_seasons INTEGER DEFAULT 1,
-			status TEXT DEFAULT 'Unknown',
-			origin_country TEXT DEFAULT '',
-			resource_time TEXT DEFAULT '待定',
-			resource_time_is_manual BOOLEAN DEFAULT FALSE,
-			is_archived BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return err
-	}
+// showsFTSSchema creates the shows_fts virtual table used by
+// TVShowRepository.SearchShows, kept in sync with tv_shows via triggers
+// rather than rebuilt on every query. FTS5 can't mix the porter stemmer with
+// the trigram tokenizer in one table, so shows_fts (porter, for stemmed
+// English matching) and shows_fts_trigram (trigram, a substring-style
+// fallback for CJK queries porter can't stem) are kept side by side; the
+// triggers below maintain both from a single INSERT/UPDATE/DELETE.
+const showsFTSSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS shows_fts USING fts5(
+		name, original_name, overview, genres, origin_country,
+		content='tv_shows', content_rowid='id',
+		tokenize='porter unicode61'
+	);
 
-	// Copy data from old table
-	_, err = tx.Exec(`
-		INSERT INTO tv_shows_new (
-			id, tmdb_id, name, total_seasons, status, origin_country, 
-			resource_time, is_archived, created_at, updated_at
-		)
-		SELECT 
-			id, tmdb_id, name, total_seasons, status, origin_country, 
-			resource_time, is_archived, created_at, updated_at
-		FROM tv_shows
-	`)
-	if err != nil {
-		return err
-	}
+	CREATE VIRTUAL TABLE IF NOT EXISTS shows_fts_trigram USING fts5(
+		name, original_name, overview, genres, origin_country,
+		content='tv_shows', content_rowid='id',
+		tokenize='trigram'
+	);
 
-	// Drop old table
-	_, err = tx.Exec(`DROP TABLE tv_shows`)
-	if err != nil {
-		return err
-	}
+	CREATE TRIGGER IF NOT EXISTS shows_fts_ai AFTER INSERT ON tv_shows BEGIN
+		INSERT INTO shows_fts(rowid, name, original_name, overview, genres, origin_country)
+		VALUES (new.id, new.name, new.original_name, new.overview, new.genres, new.origin_country);
+		INSERT INTO shows_fts_trigram(rowid, name, original_name, overview, genres, origin_country)
+		VALUES (new.id, new.name, new.original_name, new.overview, new.genres, new.origin_country);
+	END;
 
-	// Rename new table
-	_, err = tx.Exec(`ALTER TABLE tv_shows_new RENAME TO tv_shows`)
-	if err != nil {
-		return err
-	}
+	CREATE TRIGGER IF NOT EXISTS shows_fts_ad AFTER DELETE ON tv_shows BEGIN
+		INSERT INTO shows_fts(shows_fts, rowid, name, original_name, overview, genres, origin_country)
+		VALUES ('delete', old.id, old.name, old.original_name, old.overview, old.genres, old.origin_country);
+		INSERT INTO shows_fts_trigram(shows_fts_trigram, rowid, name, original_name, overview, genres, origin_country)
+		VALUES ('delete', old.id, old.name, old.original_name, old.overview, old.genres, old.origin_country);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS shows_fts_au AFTER UPDATE ON tv_shows BEGIN
+		INSERT INTO shows_fts(shows_fts, rowid, name, original_name, overview, genres, origin_country)
+		VALUES ('delete', old.id, old.name, old.original_name, old.overview, old.genres, old.origin_country);
+		INSERT INTO shows_fts(rowid, name, original_name, overview, genres, origin_country)
+		VALUES (new.id, new.name, new.original_name, new.overview, new.genres, new.origin_country);
+		INSERT INTO shows_fts_trigram(shows_fts_trigram, rowid, name, original_name, overview, genres, origin_country)
+		VALUES ('delete', old.id, old.name, old.original_name, old.overview, old.genres, old.origin_country);
+		INSERT INTO shows_fts_trigram(rowid, name, original_name, overview, genres, origin_country)
+		VALUES (new.id, new.name, new.original_name, new.overview, new.genres, new.origin_country);
+	END;
+`
 
-	// Recreate index
-	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_shows_tmdb_archived ON tv_shows(tmdb_id, is_archived)`)
+// ensureFTS5 fails fast with a clear error if the linked sqlite3 driver was
+// not built with FTS5 support, instead of letting library search silently
+// fall back to returning nothing.
+func ensureFTS5(db *sql.DB) error {
+	_, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS fts5_probe USING fts5(x)`)
 	if err != nil {
-		return err
+		return fmt.Errorf("sqlite3 driver was not built with FTS5 support (rebuild with -tags sqlite_fts5): %w", err)
 	}
-
-	// Commit transaction
-	return tx.Commit()
+	_, err = db.Exec(`DROP TABLE fts5_probe`)
+	return err
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// SyncRun is one recorded execution of a scheduled job (the TMDB refresh or
+// the task-generation sync), kept so status survives a restart.
+type SyncRun struct {
+	JobName        string
+	StartedAt      string
+	FinishedAt     string
+	ShowsRefreshed int
+	Errors         int
+	TriggeredBy    string // "schedule" or "manual"
+}
+
+// SyncRunRepository records and reports on scheduled job runs.
+type SyncRunRepository struct {
+	db *sql.DB
+}
+
+// NewSyncRunRepository creates a new SyncRunRepository.
+func NewSyncRunRepository(sqliteDB *SQLiteDB) *SyncRunRepository {
+	return &SyncRunRepository{db: sqliteDB.db}
+}
+
+// Record inserts a completed run.
+func (r *SyncRunRepository) Record(run SyncRun) error {
+	_, err := r.db.Exec(`
+		INSERT INTO sync_runs (job_name, started_at, finished_at, shows_refreshed, errors, triggered_by)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, run.JobName, run.StartedAt, run.FinishedAt, run.ShowsRefreshed, run.Errors, run.TriggeredBy)
+	return err
+}
+
+// Latest returns the most recently finished run for jobName, if any.
+func (r *SyncRunRepository) Latest(jobName string) (*SyncRun, error) {
+	var run SyncRun
+	run.JobName = jobName
+	err := r.db.QueryRow(`
+		SELECT started_at, finished_at, shows_refreshed, errors, triggered_by
+		FROM sync_runs
+		WHERE job_name = ?
+		ORDER BY finished_at DESC
+		LIMIT 1
+	`, jobName).Scan(&run.StartedAt, &run.FinishedAt, &run.ShowsRefreshed, &run.Errors, &run.TriggeredBy)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
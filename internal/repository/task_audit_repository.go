@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tv-tracker/internal/timeutil"
+)
+
+// maxAuditActions bounds how many distinct bulk actions task_audit retains;
+// older ones are pruned on every Record so the ledger can't grow without
+// bound across a long-lived dashboard.
+const maxAuditActions = 200
+
+// TaskAudit is one task's prior state captured by a destructive
+// TaskBoardService operation (CompleteMany, PostponeMany), so
+// UndoLastAction can reverse it. Several rows share the same ActionID when
+// a single bulk call touched multiple tasks.
+type TaskAudit struct {
+	ID                int64
+	ActionID          string
+	Action            string // "complete" or "postpone"
+	TaskID            int64  // the task the action was applied to
+	NewTaskID         *int64 // for "postpone": the replacement task's ID (nil for "complete")
+	PriorTaskJSON     string // json-encoded models.Task as it was before the action
+	PriorShowArchived *bool  // for "complete" on an ORGANIZE task: the show's is_archived before Archive; nil otherwise
+	CreatedAt         time.Time
+}
+
+type taskAuditDBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// TaskAuditRepository records and replays TaskAudit entries.
+type TaskAuditRepository struct {
+	db   taskAuditDBTX
+	base *sql.DB
+}
+
+// NewTaskAuditRepository creates a new TaskAuditRepository.
+func NewTaskAuditRepository(sqliteDB *SQLiteDB) *TaskAuditRepository {
+	return &TaskAuditRepository{db: sqliteDB.db, base: sqliteDB.db}
+}
+
+// WithTx returns a TaskAuditRepository that runs its queries against tx
+// instead of the pool, so an audit row commits atomically with the task
+// change it records.
+func (r *TaskAuditRepository) WithTx(tx *sql.Tx) *TaskAuditRepository {
+	return &TaskAuditRepository{db: tx}
+}
+
+// Record inserts an audit row and prunes any actions beyond the most recent
+// maxAuditActions.
+func (r *TaskAuditRepository) Record(audit TaskAudit) error {
+	_, err := r.db.Exec(`
+		INSERT INTO task_audit (action_id, action, task_id, new_task_id, prior_task_json, prior_show_archived, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, audit.ActionID, audit.Action, audit.TaskID, audit.NewTaskID, audit.PriorTaskJSON, audit.PriorShowArchived, timeutil.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		DELETE FROM task_audit WHERE action_id NOT IN (
+			SELECT action_id FROM (
+				SELECT action_id, MAX(created_at) AS last_at FROM task_audit
+				GROUP BY action_id ORDER BY last_at DESC LIMIT ?
+			)
+		)
+	`, maxAuditActions)
+	return err
+}
+
+// GetByActionID returns every audit row recorded for actionID, in the order
+// the underlying tasks were processed.
+func (r *TaskAuditRepository) GetByActionID(actionID string) ([]TaskAudit, error) {
+	rows, err := r.db.Query(`
+		SELECT id, action_id, action, task_id, new_task_id, prior_task_json, prior_show_archived, created_at
+		FROM task_audit
+		WHERE action_id = ?
+		ORDER BY id ASC
+	`, actionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var audits []TaskAudit
+	for rows.Next() {
+		var a TaskAudit
+		if err := rows.Scan(&a.ID, &a.ActionID, &a.Action, &a.TaskID, &a.NewTaskID, &a.PriorTaskJSON, &a.PriorShowArchived, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		audits = append(audits, a)
+	}
+	return audits, rows.Err()
+}
@@ -2,28 +2,113 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"tv-tracker/internal/models"
+	"tv-tracker/internal/proto"
+	"tv-tracker/internal/repository/cursortoken"
 )
 
+type taskDBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 // TaskRepository handles Task database operations
 type TaskRepository struct {
-	db *sql.DB
+	db   taskDBTX
+	base *sql.DB
 }
 
 // NewTaskRepository creates a new TaskRepository
 func NewTaskRepository(sqliteDB *SQLiteDB) *TaskRepository {
-	return &TaskRepository{db: sqliteDB.db}
+	return &TaskRepository{db: sqliteDB.db, base: sqliteDB.db}
+}
+
+// BeginTx starts a transaction for callers (e.g. TaskBoardService) that need
+// to update tasks alongside other repositories atomically.
+func (r *TaskRepository) BeginTx() (*sql.Tx, error) {
+	if r.base == nil {
+		return nil, errors.New("task repository: transactions not supported on tx-scoped repo")
+	}
+	return r.base.Begin()
+}
+
+// WithTx returns a TaskRepository that runs its queries against tx instead
+// of the pool.
+func (r *TaskRepository) WithTx(tx *sql.Tx) *TaskRepository {
+	return &TaskRepository{db: tx}
+}
+
+// nullableTime converts a possibly-nil *time.Time into the sql.NullTime the
+// driver expects, so an unset DueDate is stored as SQL NULL rather than the
+// zero time.
+func nullableTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
 }
 
-// Create inserts a new Task into the database
-func (r *TaskRepository) Create(task *models.Task) error {
+// Create inserts a new Task into the database. It's keyed by a
+// content-addressed dedup key derived from TaskType, TVShowID, and
+// EpisodeID, so INSERT OR IGNORE gives true structural idempotence: calling
+// Create twice for "the same" task is a no-op rather than a duplicate row,
+// with no separate existence check to race. created is false when an
+// identical task already existed; task.ID is left at its prior value in
+// that case.
+func (r *TaskRepository) Create(task *models.Task) (created bool, err error) {
 	now := time.Now()
+	dedupKey := proto.TaskPayload{
+		TaskType:  string(task.TaskType),
+		TVShowID:  task.TVShowID,
+		EpisodeID: task.EpisodeID,
+	}.DedupKey()
+
+	result, err := r.db.Exec(`
+		INSERT OR IGNORE INTO tasks (tv_show_id, task_type, episode_id, description, dedup_key, is_completed, priority, due_date, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.TVShowID, task.TaskType, task.EpisodeID, task.Description, dedupKey, task.IsCompleted, task.Priority, nullableTime(task.DueDate), now)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	task.ID = id
+	task.CreatedAt = now
+	return true, nil
+}
+
+// CreateWithDate inserts a new Task with an explicit created_at instead of
+// now, for callers (e.g. TaskBoardService.Snooze) that are recreating a
+// task at a future date. Unlike Create, this always inserts rather than
+// INSERT OR IGNORE, since the caller is expected to have already removed
+// any task sharing the same dedup key (e.g. the task being snoozed).
+func (r *TaskRepository) CreateWithDate(task *models.Task, createdAt string) error {
+	dedupKey := proto.TaskPayload{
+		TaskType:  string(task.TaskType),
+		TVShowID:  task.TVShowID,
+		EpisodeID: task.EpisodeID,
+	}.DedupKey()
+
 	result, err := r.db.Exec(`
-		INSERT INTO tasks (tv_show_id, task_type, description, is_completed, created_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, task.TVShowID, task.TaskType, task.Description, task.IsCompleted, now)
+		INSERT INTO tasks (tv_show_id, task_type, episode_id, description, dedup_key, is_completed, priority, due_date, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.TVShowID, task.TaskType, task.EpisodeID, task.Description, dedupKey, task.IsCompleted, task.Priority, nullableTime(task.DueDate), createdAt)
 	if err != nil {
 		return err
 	}
@@ -32,14 +117,39 @@ func (r *TaskRepository) Create(task *models.Task) error {
 		return err
 	}
 	task.ID = id
-	task.CreatedAt = now
 	return nil
 }
 
+// Restore re-inserts a task at its original ID with its original
+// created_at, for TaskBoardService.UndoLastAction reversing a postpone.
+// Plain INSERT OR IGNORE on Create can't be reused here since it assigns a
+// fresh ID rather than replaying the one the task held before it was
+// deleted.
+func (r *TaskRepository) Restore(task models.Task) error {
+	dedupKey := proto.TaskPayload{
+		TaskType:  string(task.TaskType),
+		TVShowID:  task.TVShowID,
+		EpisodeID: task.EpisodeID,
+	}.DedupKey()
+
+	_, err := r.db.Exec(`
+		INSERT INTO tasks (id, tv_show_id, task_type, episode_id, description, dedup_key, is_completed, priority, due_date, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.ID, task.TVShowID, task.TaskType, task.EpisodeID, task.Description, dedupKey, task.IsCompleted, task.Priority, nullableTime(task.DueDate), task.CreatedAt)
+	return err
+}
+
+// Delete removes a task outright, used when recreating it under a new
+// created_at (see TaskBoardService.Snooze) rather than updating in place.
+func (r *TaskRepository) Delete(taskID int64) error {
+	_, err := r.db.Exec(`DELETE FROM tasks WHERE id = ?`, taskID)
+	return err
+}
+
 // GetPendingByType retrieves all pending tasks of a specific type
 func (r *TaskRepository) GetPendingByType(taskType models.TaskType) ([]models.Task, error) {
 	rows, err := r.db.Query(`
-		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.description, t.is_completed, t.created_at
+		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.episode_id, t.description, t.is_completed, t.priority, t.due_date, t.created_at
 		FROM tasks t
 		JOIN tv_shows s ON t.tv_show_id = s.id
 		WHERE t.is_completed = FALSE AND t.task_type = ?
@@ -53,29 +163,34 @@ func (r *TaskRepository) GetPendingByType(taskType models.TaskType) ([]models.Ta
 	var tasks []models.Task
 	for rows.Next() {
 		var task models.Task
+		var dueDate sql.NullTime
 		err := rows.Scan(
 			&task.ID, &task.TVShowID, &task.TVShowName, &task.ResourceTime,
-			&task.TaskType, &task.Description, &task.IsCompleted, &task.CreatedAt,
+			&task.TaskType, &task.EpisodeID, &task.Description, &task.IsCompleted, &task.Priority, &dueDate, &task.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if dueDate.Valid {
+			task.DueDate = &dueDate.Time
+		}
 		tasks = append(tasks, task)
 	}
 	return tasks, rows.Err()
 }
 
-// GetByShowAndEpisode retrieves a task by show ID and episode description
-func (r *TaskRepository) GetByShowAndEpisode(showID int64, episode string) (*models.Task, error) {
+// GetByShowAndEpisode retrieves a task by show ID and exact episode ID (SxxExx).
+func (r *TaskRepository) GetByShowAndEpisode(showID int64, episodeID string) (*models.Task, error) {
 	task := &models.Task{}
+	var dueDate sql.NullTime
 	err := r.db.QueryRow(`
-		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.description, t.is_completed, t.created_at
+		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.episode_id, t.description, t.is_completed, t.priority, t.due_date, t.created_at
 		FROM tasks t
 		JOIN tv_shows s ON t.tv_show_id = s.id
-		WHERE t.tv_show_id = ? AND t.description LIKE ?
-	`, showID, "%"+episode+"%").Scan(
+		WHERE t.tv_show_id = ? AND t.episode_id = ?
+	`, showID, episodeID).Scan(
 		&task.ID, &task.TVShowID, &task.TVShowName, &task.ResourceTime,
-		&task.TaskType, &task.Description, &task.IsCompleted, &task.CreatedAt,
+		&task.TaskType, &task.EpisodeID, &task.Description, &task.IsCompleted, &task.Priority, &dueDate, &task.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -83,6 +198,9 @@ func (r *TaskRepository) GetByShowAndEpisode(showID int64, episode string) (*mod
 	if err != nil {
 		return nil, err
 	}
+	if dueDate.Valid {
+		task.DueDate = &dueDate.Time
+	}
 	return task, nil
 }
 
@@ -107,10 +225,18 @@ func (r *TaskRepository) Complete(taskID int64) error {
 	return err
 }
 
+// SetCompleted sets a task's completion flag directly, used by
+// TaskBoardService.UndoLastAction to reverse a completed task back to
+// pending (Complete only ever sets it to true).
+func (r *TaskRepository) SetCompleted(taskID int64, completed bool) error {
+	_, err := r.db.Exec(`UPDATE tasks SET is_completed = ? WHERE id = ?`, completed, taskID)
+	return err
+}
+
 // GetAllPending retrieves all pending tasks
 func (r *TaskRepository) GetAllPending() ([]models.Task, error) {
 	rows, err := r.db.Query(`
-		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.description, t.is_completed, t.created_at
+		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.episode_id, t.description, t.is_completed, t.priority, t.due_date, t.created_at
 		FROM tasks t
 		JOIN tv_shows s ON t.tv_show_id = s.id
 		WHERE t.is_completed = FALSE
@@ -124,29 +250,153 @@ func (r *TaskRepository) GetAllPending() ([]models.Task, error) {
 	var tasks []models.Task
 	for rows.Next() {
 		var task models.Task
+		var dueDate sql.NullTime
 		err := rows.Scan(
 			&task.ID, &task.TVShowID, &task.TVShowName, &task.ResourceTime,
-			&task.TaskType, &task.Description, &task.IsCompleted, &task.CreatedAt,
+			&task.TaskType, &task.EpisodeID, &task.Description, &task.IsCompleted, &task.Priority, &dueDate, &task.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if dueDate.Valid {
+			task.DueDate = &dueDate.Time
+		}
 		tasks = append(tasks, task)
 	}
 	return tasks, rows.Err()
 }
 
+// TaskFilter narrows a ListPaged query. A zero-value field means "don't
+// filter on it".
+type TaskFilter struct {
+	TaskType  models.TaskType
+	Completed *bool
+	Query     string // case-insensitive substring match against description
+}
+
+// taskSortColumns maps the API-facing sort keys to the SQL expression used
+// both for ORDER BY and the cursor's keyset comparison. Tasks have no
+// "next_air_date" of their own, so that key falls back to "added" like any
+// other unrecognized sort.
+var taskSortColumns = map[string]string{
+	"name":  "s.name",
+	"added": "t.created_at",
+}
+
+func (f TaskFilter) hash(sort string) string {
+	completed := "nil"
+	if f.Completed != nil {
+		completed = fmt.Sprintf("%v", *f.Completed)
+	}
+	return cursortoken.HashFilter(sort, string(f.TaskType), completed, f.Query)
+}
+
+// ListPaged returns one page of tasks matching filter, ordered by sort
+// ("name" or "added"; defaults to "added"), resuming from cursor if
+// non-empty. limit must be in [1, 200]. The returned nextCursor is empty
+// once hasMore is false.
+func (r *TaskRepository) ListPaged(filter TaskFilter, sort, cursor string, limit int) (tasks []models.Task, nextCursor string, hasMore bool, err error) {
+	if limit < 1 || limit > 200 {
+		return nil, "", false, fmt.Errorf("limit must be between 1 and 200, got %d", limit)
+	}
+	sortExpr, ok := taskSortColumns[sort]
+	if !ok {
+		sort = "added"
+		sortExpr = taskSortColumns[sort]
+	}
+
+	tok, err := cursortoken.Decode(cursor, filter.hash(sort))
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var conditions []string
+	var args []any
+
+	if filter.TaskType != "" {
+		conditions = append(conditions, "t.task_type = ?")
+		args = append(args, filter.TaskType)
+	}
+	if filter.Completed != nil {
+		conditions = append(conditions, "t.is_completed = ?")
+		args = append(args, *filter.Completed)
+	}
+	if filter.Query != "" {
+		conditions = append(conditions, "t.description LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+	}
+	if tok.LastSort != "" || tok.LastID != 0 {
+		conditions = append(conditions, fmt.Sprintf("(%s > ? OR (%s = ? AND t.id > ?))", sortExpr, sortExpr))
+		args = append(args, tok.LastSort, tok.LastSort, tok.LastID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.episode_id, t.description, t.is_completed, t.priority, t.due_date, t.created_at, %s AS sort_key
+		FROM tasks t
+		JOIN tv_shows s ON t.tv_show_id = s.id
+	`, sortExpr)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s ASC, t.id ASC LIMIT ?", sortExpr)
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer rows.Close()
+
+	var sortKeys []string
+	for rows.Next() {
+		var task models.Task
+		var dueDate sql.NullTime
+		var sortKey string
+		if err := rows.Scan(
+			&task.ID, &task.TVShowID, &task.TVShowName, &task.ResourceTime,
+			&task.TaskType, &task.EpisodeID, &task.Description, &task.IsCompleted, &task.Priority, &dueDate, &task.CreatedAt, &sortKey,
+		); err != nil {
+			return nil, "", false, err
+		}
+		if dueDate.Valid {
+			task.DueDate = &dueDate.Time
+		}
+		tasks = append(tasks, task)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", false, err
+	}
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		sortKeys = sortKeys[:limit]
+		hasMore = true
+	}
+	if hasMore {
+		last := tasks[len(tasks)-1]
+		nextCursor = cursortoken.Encode(cursortoken.Token{
+			LastID:     last.ID,
+			LastSort:   sortKeys[len(sortKeys)-1],
+			Direction:  "next",
+			FilterHash: filter.hash(sort),
+		})
+	}
+	return tasks, nextCursor, hasMore, nil
+}
+
 // GetByID retrieves a task by its ID
 func (r *TaskRepository) GetByID(taskID int64) (*models.Task, error) {
 	task := &models.Task{}
+	var dueDate sql.NullTime
 	err := r.db.QueryRow(`
-		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.description, t.is_completed, t.created_at
+		SELECT t.id, t.tv_show_id, s.name, s.resource_time, t.task_type, t.episode_id, t.description, t.is_completed, t.priority, t.due_date, t.created_at
 		FROM tasks t
 		JOIN tv_shows s ON t.tv_show_id = s.id
 		WHERE t.id = ?
 	`, taskID).Scan(
 		&task.ID, &task.TVShowID, &task.TVShowName, &task.ResourceTime,
-		&task.TaskType, &task.Description, &task.IsCompleted, &task.CreatedAt,
+		&task.TaskType, &task.EpisodeID, &task.Description, &task.IsCompleted, &task.Priority, &dueDate, &task.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -154,5 +404,20 @@ func (r *TaskRepository) GetByID(taskID int64) (*models.Task, error) {
 	if err != nil {
 		return nil, err
 	}
+	if dueDate.Valid {
+		task.DueDate = &dueDate.Time
+	}
 	return task, nil
 }
+
+// SetPriority updates a task's priority score in place.
+func (r *TaskRepository) SetPriority(taskID int64, score int) error {
+	_, err := r.db.Exec(`UPDATE tasks SET priority = ? WHERE id = ?`, score, taskID)
+	return err
+}
+
+// SetDueDate updates a task's due date in place. A nil due clears it.
+func (r *TaskRepository) SetDueDate(taskID int64, due *time.Time) error {
+	_, err := r.db.Exec(`UPDATE tasks SET due_date = ? WHERE id = ?`, nullableTime(due), taskID)
+	return err
+}
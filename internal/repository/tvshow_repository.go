@@ -3,8 +3,13 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository/cursortoken"
 	"tv-tracker/internal/timeutil"
 )
 
@@ -39,10 +44,13 @@ func (r *TVShowRepository) WithTx(tx *sql.Tx) *TVShowRepository {
 // Create inserts a new TVShow into the database
 func (r *TVShowRepository) Create(show *models.TVShow) error {
 	now := timeutil.Now()
+	if show.MetadataSource == "" {
+		show.MetadataSource = metadata.ProviderTMDB
+	}
 	result, err := r.db.Exec(`
-		INSERT INTO tv_shows (tmdb_id, name, total_seasons, status, origin_country, resource_time, resource_time_is_manual, is_archived, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, show.TMDBID, show.Name, show.TotalSeasons, show.Status, show.OriginCountry, show.ResourceTime, show.ResourceTimeIsManual, show.IsArchived, now, now)
+		INSERT INTO tv_shows (tmdb_id, name, original_name, overview, genres, total_seasons, status, origin_country, resource_time, resource_time_is_manual, metadata_source, is_archived, quality_filter, preferred_resolution, notify_channel, auto_skip_specials, preferred_quality, preferred_codec, preferred_group, reject_cam, min_quality, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, show.TMDBID, show.Name, show.OriginalName, show.Overview, show.Genres, show.TotalSeasons, show.Status, show.OriginCountry, show.ResourceTime, show.ResourceTimeIsManual, show.MetadataSource, show.IsArchived, show.QualityFilter, show.PreferredResolution, show.NotifyChannel, show.AutoSkipSpecials, show.PreferredQuality, show.PreferredCodec, show.PreferredGroup, show.RejectCam, show.MinQuality, now, now)
 	if err != nil {
 		return err
 	}
@@ -60,11 +68,13 @@ func (r *TVShowRepository) Create(show *models.TVShow) error {
 func (r *TVShowRepository) GetByTMDBID(tmdbID int) (*models.TVShow, error) {
 	show := &models.TVShow{}
 	err := r.db.QueryRow(`
-		SELECT id, tmdb_id, name, total_seasons, status, origin_country, resource_time, resource_time_is_manual, is_archived, created_at, updated_at
+		SELECT id, tmdb_id, name, original_name, overview, genres, total_seasons, status, origin_country, resource_time, resource_time_is_manual, metadata_source, is_archived, quality_filter, preferred_resolution, notify_channel, auto_skip_specials, preferred_quality, preferred_codec, preferred_group, reject_cam, min_quality, created_at, updated_at
 		FROM tv_shows WHERE tmdb_id = ?
 	`, tmdbID).Scan(
-		&show.ID, &show.TMDBID, &show.Name, &show.TotalSeasons, &show.Status,
-		&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+		&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+		&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived,
+		&show.QualityFilter, &show.PreferredResolution, &show.NotifyChannel, &show.AutoSkipSpecials,
+		&show.PreferredQuality, &show.PreferredCodec, &show.PreferredGroup, &show.RejectCam, &show.MinQuality, &show.CreatedAt, &show.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -79,11 +89,13 @@ func (r *TVShowRepository) GetByTMDBID(tmdbID int) (*models.TVShow, error) {
 func (r *TVShowRepository) GetByID(id int64) (*models.TVShow, error) {
 	show := &models.TVShow{}
 	err := r.db.QueryRow(`
-		SELECT id, tmdb_id, name, total_seasons, status, origin_country, resource_time, resource_time_is_manual, is_archived, created_at, updated_at
+		SELECT id, tmdb_id, name, original_name, overview, genres, total_seasons, status, origin_country, resource_time, resource_time_is_manual, metadata_source, is_archived, quality_filter, preferred_resolution, notify_channel, auto_skip_specials, preferred_quality, preferred_codec, preferred_group, reject_cam, min_quality, created_at, updated_at
 		FROM tv_shows WHERE id = ?
 	`, id).Scan(
-		&show.ID, &show.TMDBID, &show.Name, &show.TotalSeasons, &show.Status,
-		&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+		&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+		&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived,
+		&show.QualityFilter, &show.PreferredResolution, &show.NotifyChannel, &show.AutoSkipSpecials,
+		&show.PreferredQuality, &show.PreferredCodec, &show.PreferredGroup, &show.RejectCam, &show.MinQuality, &show.CreatedAt, &show.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -97,7 +109,7 @@ func (r *TVShowRepository) GetByID(id int64) (*models.TVShow, error) {
 // GetAllActive retrieves all non-archived TVShows
 func (r *TVShowRepository) GetAllActive() ([]models.TVShow, error) {
 	rows, err := r.db.Query(`
-		SELECT id, tmdb_id, name, total_seasons, status, origin_country, resource_time, resource_time_is_manual, is_archived, created_at, updated_at
+		SELECT id, tmdb_id, name, original_name, overview, genres, total_seasons, status, origin_country, resource_time, resource_time_is_manual, metadata_source, is_archived, quality_filter, preferred_resolution, notify_channel, auto_skip_specials, preferred_quality, preferred_codec, preferred_group, reject_cam, min_quality, created_at, updated_at
 		FROM tv_shows WHERE is_archived = FALSE
 	`)
 	if err != nil {
@@ -109,8 +121,10 @@ func (r *TVShowRepository) GetAllActive() ([]models.TVShow, error) {
 	for rows.Next() {
 		var show models.TVShow
 		err := rows.Scan(
-			&show.ID, &show.TMDBID, &show.Name, &show.TotalSeasons, &show.Status,
-			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived,
+			&show.QualityFilter, &show.PreferredResolution, &show.NotifyChannel, &show.AutoSkipSpecials,
+			&show.PreferredQuality, &show.PreferredCodec, &show.PreferredGroup, &show.RejectCam, &show.MinQuality, &show.CreatedAt, &show.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -120,10 +134,112 @@ func (r *TVShowRepository) GetAllActive() ([]models.TVShow, error) {
 	return shows, rows.Err()
 }
 
+// TVShowWithProgress pairs a TVShow with its episode-watch progress,
+// computed via a JOIN against episodes/watched_episodes/skipped_episodes so
+// callers don't have to issue a follow-up query per show.
+type TVShowWithProgress struct {
+	models.TVShow
+	TotalEpisodes        int    `json:"total_episodes"`
+	WatchedEpisodes      int    `json:"watched_episodes"`
+	SkippedEpisodes      int    `json:"skipped_episodes"`
+	NextUnwatchedAirDate string `json:"next_unwatched_air_date,omitempty"`
+}
+
+// GetActiveQueue returns every non-archived show with its watch progress,
+// sorted by the air date of its earliest unwatched, unskipped episode (shows
+// with none, e.g. fully caught up or with no episodes stored yet, sort last).
+// This turns the flat active-shows list into an "up next" queue.
+func (r *TVShowRepository) GetActiveQueue() ([]TVShowWithProgress, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country,
+			s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.created_at, s.updated_at,
+			COUNT(e.id) AS total_episodes,
+			COUNT(w.episode_code) AS watched_episodes,
+			COUNT(sk.episode_code) AS skipped_episodes,
+			COALESCE(MIN(CASE WHEN w.episode_code IS NULL AND sk.episode_code IS NULL THEN e.air_date END), '9999-12-31') AS next_unwatched_air_date
+		FROM tv_shows s
+		LEFT JOIN episodes e ON e.tmdb_id = s.tmdb_id
+		LEFT JOIN watched_episodes w
+			ON w.tmdb_id = e.tmdb_id
+			AND w.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		LEFT JOIN skipped_episodes sk
+			ON sk.tmdb_id = e.tmdb_id
+			AND sk.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		WHERE s.is_archived = FALSE
+		GROUP BY s.id
+		ORDER BY next_unwatched_air_date ASC, s.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shows []TVShowWithProgress
+	for rows.Next() {
+		var show TVShowWithProgress
+		if err := rows.Scan(
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+			&show.TotalEpisodes, &show.WatchedEpisodes, &show.SkippedEpisodes, &show.NextUnwatchedAirDate,
+		); err != nil {
+			return nil, err
+		}
+		if show.NextUnwatchedAirDate == "9999-12-31" {
+			show.NextUnwatchedAirDate = ""
+		}
+		shows = append(shows, show)
+	}
+	return shows, rows.Err()
+}
+
+// GetAllWithProgress is GetAll with each show's watched/skipped episode
+// counts attached, the whole-library (archived included) counterpart to
+// GetActiveQueue's active-only view - e.g. for a library screen that wants
+// progress counts without a separate per-show query.
+func (r *TVShowRepository) GetAllWithProgress() ([]TVShowWithProgress, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country,
+			s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.created_at, s.updated_at,
+			COUNT(e.id) AS total_episodes,
+			COUNT(w.episode_code) AS watched_episodes,
+			COUNT(sk.episode_code) AS skipped_episodes
+		FROM tv_shows s
+		LEFT JOIN episodes e ON e.tmdb_id = s.tmdb_id
+		LEFT JOIN watched_episodes w
+			ON w.tmdb_id = e.tmdb_id
+			AND w.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		LEFT JOIN skipped_episodes sk
+			ON sk.tmdb_id = e.tmdb_id
+			AND sk.episode_code = printf('S%02dE%02d', e.season, e.episode)
+		GROUP BY s.id
+		ORDER BY s.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shows []TVShowWithProgress
+	for rows.Next() {
+		var show TVShowWithProgress
+		if err := rows.Scan(
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+			&show.TotalEpisodes, &show.WatchedEpisodes, &show.SkippedEpisodes,
+		); err != nil {
+			return nil, err
+		}
+		shows = append(shows, show)
+	}
+	return shows, rows.Err()
+}
+
 // GetAll retrieves all TVShows
 func (r *TVShowRepository) GetAll() ([]models.TVShow, error) {
 	rows, err := r.db.Query(`
-		SELECT id, tmdb_id, name, total_seasons, status, origin_country, resource_time, resource_time_is_manual, is_archived, created_at, updated_at
+		SELECT id, tmdb_id, name, original_name, overview, genres, total_seasons, status, origin_country, resource_time, resource_time_is_manual, metadata_source, is_archived, quality_filter, preferred_resolution, notify_channel, auto_skip_specials, preferred_quality, preferred_codec, preferred_group, reject_cam, min_quality, created_at, updated_at
 		FROM tv_shows
 	`)
 	if err != nil {
@@ -135,8 +251,79 @@ func (r *TVShowRepository) GetAll() ([]models.TVShow, error) {
 	for rows.Next() {
 		var show models.TVShow
 		err := rows.Scan(
-			&show.ID, &show.TMDBID, &show.Name, &show.TotalSeasons, &show.Status,
-			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt,
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived,
+			&show.QualityFilter, &show.PreferredResolution, &show.NotifyChannel, &show.AutoSkipSpecials,
+			&show.PreferredQuality, &show.PreferredCodec, &show.PreferredGroup, &show.RejectCam, &show.MinQuality, &show.CreatedAt, &show.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shows = append(shows, show)
+	}
+	return shows, rows.Err()
+}
+
+// GetStaleForOrganize returns active shows whose most recently aired episode
+// was more than days ago, excluding shows that already have a pending
+// ORGANIZE task, for schedule rules that generate ORGANIZE tasks (see
+// internal/service/scheduler.SelectorStaleLastEpisode).
+func (r *TVShowRepository) GetStaleForOrganize(days int) ([]models.TVShow, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country, s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.quality_filter, s.preferred_resolution, s.notify_channel, s.auto_skip_specials, s.preferred_quality, s.preferred_codec, s.preferred_group, s.reject_cam, s.min_quality, s.created_at, s.updated_at
+		FROM tv_shows s
+		JOIN episodes e ON e.tmdb_id = s.tmdb_id
+		WHERE s.is_archived = FALSE
+		GROUP BY s.id
+		HAVING MAX(e.air_date) <= date('now', printf('-%d days', ?))
+		   AND NOT EXISTS (
+			SELECT 1 FROM tasks t
+			WHERE t.tv_show_id = s.id AND t.task_type = ? AND t.is_completed = FALSE
+		   )
+	`, days, models.TaskTypeOrganize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTVShows(rows)
+}
+
+// GetUpcomingForUpdate returns active shows with an unaired episode due
+// within days, excluding shows that already have a pending UPDATE task, for
+// schedule rules that generate UPDATE tasks (see
+// internal/service/scheduler.SelectorUpcomingNextEpisode).
+func (r *TVShowRepository) GetUpcomingForUpdate(days int) ([]models.TVShow, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country, s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.quality_filter, s.preferred_resolution, s.notify_channel, s.auto_skip_specials, s.preferred_quality, s.preferred_codec, s.preferred_group, s.reject_cam, s.min_quality, s.created_at, s.updated_at
+		FROM tv_shows s
+		JOIN episodes e ON e.tmdb_id = s.tmdb_id
+		WHERE s.is_archived = FALSE
+		  AND e.air_date >= date('now')
+		  AND e.air_date <= date('now', printf('+%d days', ?))
+		  AND NOT EXISTS (
+			SELECT 1 FROM tasks t
+			WHERE t.tv_show_id = s.id AND t.task_type = ? AND t.is_completed = FALSE
+		  )
+		GROUP BY s.id
+	`, days, models.TaskTypeUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTVShows(rows)
+}
+
+// scanTVShows scans the full tv_shows column set (matching GetAll's select
+// list) into a slice of models.TVShow.
+func scanTVShows(rows *sql.Rows) ([]models.TVShow, error) {
+	var shows []models.TVShow
+	for rows.Next() {
+		var show models.TVShow
+		err := rows.Scan(
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived,
+			&show.QualityFilter, &show.PreferredResolution, &show.NotifyChannel, &show.AutoSkipSpecials,
+			&show.PreferredQuality, &show.PreferredCodec, &show.PreferredGroup, &show.RejectCam, &show.MinQuality, &show.CreatedAt, &show.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -149,11 +336,14 @@ func (r *TVShowRepository) GetAll() ([]models.TVShow, error) {
 // Update updates an existing TVShow in the database
 func (r *TVShowRepository) Update(show *models.TVShow) error {
 	now := timeutil.Now()
+	if show.MetadataSource == "" {
+		show.MetadataSource = metadata.ProviderTMDB
+	}
 	_, err := r.db.Exec(`
-		UPDATE tv_shows 
-		SET name = ?, total_seasons = ?, status = ?, origin_country = ?, resource_time = ?, resource_time_is_manual = ?, is_archived = ?, updated_at = ?
+		UPDATE tv_shows
+		SET name = ?, original_name = ?, overview = ?, genres = ?, total_seasons = ?, status = ?, origin_country = ?, resource_time = ?, resource_time_is_manual = ?, metadata_source = ?, is_archived = ?, quality_filter = ?, preferred_resolution = ?, notify_channel = ?, auto_skip_specials = ?, preferred_quality = ?, preferred_codec = ?, preferred_group = ?, reject_cam = ?, min_quality = ?, updated_at = ?
 		WHERE id = ?
-	`, show.Name, show.TotalSeasons, show.Status, show.OriginCountry, show.ResourceTime, show.ResourceTimeIsManual, show.IsArchived, now, show.ID)
+	`, show.Name, show.OriginalName, show.Overview, show.Genres, show.TotalSeasons, show.Status, show.OriginCountry, show.ResourceTime, show.ResourceTimeIsManual, show.MetadataSource, show.IsArchived, show.QualityFilter, show.PreferredResolution, show.NotifyChannel, show.AutoSkipSpecials, show.PreferredQuality, show.PreferredCodec, show.PreferredGroup, show.RejectCam, show.MinQuality, now, show.ID)
 	if err != nil {
 		return err
 	}
@@ -163,9 +353,196 @@ func (r *TVShowRepository) Update(show *models.TVShow) error {
 
 // Archive sets a TVShow as archived
 func (r *TVShowRepository) Archive(showID int64) error {
+	return r.SetArchived(showID, true)
+}
+
+// SetArchived sets a TVShow's archive flag directly, used by Archive and by
+// TaskBoardService.UndoLastAction to restore a show's prior archive state
+// when reversing a completed ORGANIZE task.
+func (r *TVShowRepository) SetArchived(showID int64, archived bool) error {
 	now := timeutil.Now()
 	_, err := r.db.Exec(`
-		UPDATE tv_shows SET is_archived = TRUE, updated_at = ? WHERE id = ?
-	`, now, showID)
+		UPDATE tv_shows SET is_archived = ?, updated_at = ? WHERE id = ?
+	`, archived, now, showID)
 	return err
 }
+
+// ShowFilter narrows a ListPaged query. A zero-value field means "don't
+// filter on it" except Archived, which is a pointer so "archived=false" can
+// be distinguished from "no opinion".
+type ShowFilter struct {
+	Status        string
+	OriginCountry string
+	Archived      *bool
+	Query         string // case-insensitive substring match against name
+}
+
+// showSortColumns maps the API-facing sort keys to the SQL expression used
+// both for ORDER BY and for the cursor's keyset comparison. next_air_date is
+// the soonest upcoming episode across any season, with shows that have none
+// sorted last.
+var showSortColumns = map[string]string{
+	"name":          "s.name",
+	"added":         "s.created_at",
+	"next_air_date": "COALESCE((SELECT MIN(e.air_date) FROM episodes e WHERE e.tmdb_id = s.tmdb_id AND e.air_date >= DATE('now')), '9999-12-31')",
+}
+
+func (f ShowFilter) hash(sort string) string {
+	archived := "nil"
+	if f.Archived != nil {
+		archived = strconv.FormatBool(*f.Archived)
+	}
+	return cursortoken.HashFilter(sort, f.Status, f.OriginCountry, archived, f.Query)
+}
+
+// ListPaged returns one page of shows matching filter, ordered by sort
+// ("name", "added", or "next_air_date"; defaults to "added"), resuming from
+// cursor if non-empty. limit must be in [1, 200]. The returned nextCursor is
+// empty once hasMore is false.
+func (r *TVShowRepository) ListPaged(filter ShowFilter, sort, cursor string, limit int) (shows []models.TVShow, nextCursor string, hasMore bool, err error) {
+	if limit < 1 || limit > 200 {
+		return nil, "", false, fmt.Errorf("limit must be between 1 and 200, got %d", limit)
+	}
+	sortExpr, ok := showSortColumns[sort]
+	if !ok {
+		sort = "added"
+		sortExpr = showSortColumns[sort]
+	}
+
+	tok, err := cursortoken.Decode(cursor, filter.hash(sort))
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var conditions []string
+	var args []any
+
+	if filter.Status != "" {
+		conditions = append(conditions, "s.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.OriginCountry != "" {
+		conditions = append(conditions, "s.origin_country = ?")
+		args = append(args, filter.OriginCountry)
+	}
+	if filter.Archived != nil {
+		conditions = append(conditions, "s.is_archived = ?")
+		args = append(args, *filter.Archived)
+	}
+	if filter.Query != "" {
+		conditions = append(conditions, "s.name LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+	}
+	if tok.LastSort != "" || tok.LastID != 0 {
+		conditions = append(conditions, fmt.Sprintf("(%s > ? OR (%s = ? AND s.id > ?))", sortExpr, sortExpr))
+		args = append(args, tok.LastSort, tok.LastSort, tok.LastID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country, s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.created_at, s.updated_at, %s AS sort_key
+		FROM tv_shows s
+	`, sortExpr)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s ASC, s.id ASC LIMIT ?", sortExpr)
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer rows.Close()
+
+	var sortKeys []string
+	for rows.Next() {
+		var show models.TVShow
+		var sortKey string
+		if err := rows.Scan(
+			&show.ID, &show.TMDBID, &show.Name, &show.OriginalName, &show.Overview, &show.Genres, &show.TotalSeasons, &show.Status,
+			&show.OriginCountry, &show.ResourceTime, &show.ResourceTimeIsManual, &show.MetadataSource, &show.IsArchived, &show.CreatedAt, &show.UpdatedAt, &sortKey,
+		); err != nil {
+			return nil, "", false, err
+		}
+		shows = append(shows, show)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", false, err
+	}
+
+	if len(shows) > limit {
+		shows = shows[:limit]
+		sortKeys = sortKeys[:limit]
+		hasMore = true
+	}
+	if hasMore {
+		last := shows[len(shows)-1]
+		nextCursor = cursortoken.Encode(cursortoken.Token{
+			LastID:     last.ID,
+			LastSort:   sortKeys[len(sortKeys)-1],
+			Direction:  "next",
+			FilterHash: filter.hash(sort),
+		})
+	}
+	return shows, nextCursor, hasMore, nil
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return r.Replace(s)
+}
+
+// ShowSearchHit is one library search result: the matched show plus an
+// HTML-highlighted snippet of whichever field matched best.
+type ShowSearchHit struct {
+	Show    models.TVShow `json:"show"`
+	Snippet string        `json:"snippet"`
+}
+
+// cjkLanguages are the ?lang= hints that route to the trigram fallback
+// table, since the porter tokenizer's English stemming finds no useful
+// word boundaries in CJK text.
+var cjkLanguages = map[string]bool{
+	"zh": true, "zh-cn": true, "zh-tw": true, "ja": true, "ko": true,
+}
+
+// SearchShows runs a full-text search over subscribed shows via shows_fts
+// (or shows_fts_trigram, when lang names a CJK language), returning matches
+// ranked by relevance with a snippet of the best-matching field.
+func (r *TVShowRepository) SearchShows(query, lang string) ([]ShowSearchHit, error) {
+	table := "shows_fts"
+	if cjkLanguages[strings.ToLower(lang)] {
+		table = "shows_fts_trigram"
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT s.id, s.tmdb_id, s.name, s.original_name, s.overview, s.genres, s.total_seasons, s.status, s.origin_country, s.resource_time, s.resource_time_is_manual, s.metadata_source, s.is_archived, s.created_at, s.updated_at,
+		       snippet(%s, 0, '<mark>', '</mark>', '…', 10)
+		FROM %s
+		JOIN tv_shows s ON s.id = %s.rowid
+		WHERE %s MATCH ?
+		ORDER BY rank
+		LIMIT 50
+	`, table, table, table, table)
+
+	rows, err := r.db.Query(sqlQuery, query)
+	if err != nil {
+		return nil, fmt.Errorf("library search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []ShowSearchHit
+	for rows.Next() {
+		var hit ShowSearchHit
+		if err := rows.Scan(
+			&hit.Show.ID, &hit.Show.TMDBID, &hit.Show.Name, &hit.Show.OriginalName, &hit.Show.Overview, &hit.Show.Genres, &hit.Show.TotalSeasons, &hit.Show.Status,
+			&hit.Show.OriginCountry, &hit.Show.ResourceTime, &hit.Show.ResourceTimeIsManual, &hit.Show.MetadataSource, &hit.Show.IsArchived, &hit.Show.CreatedAt, &hit.Show.UpdatedAt,
+			&hit.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/timeutil"
+)
+
+// WebhookRepository handles WebhookSubscription database operations.
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository.
+func NewWebhookRepository(sqliteDB *SQLiteDB) *WebhookRepository {
+	return &WebhookRepository{db: sqliteDB.db}
+}
+
+// Create inserts a verified subscription (the WebSub handshake must already
+// have succeeded by the time this is called).
+func (r *WebhookRepository) Create(sub *models.WebhookSubscription) error {
+	now := timeutil.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO webhook_subscriptions (callback_url, topic, secret, lease_seconds, verified_at, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sub.CallbackURL, sub.Topic, sub.Secret, sub.LeaseSeconds, sub.VerifiedAt, sub.ExpiresAt, now)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	sub.ID = id
+	sub.CreatedAt = now
+	return nil
+}
+
+// GetAll returns every registered subscription.
+func (r *WebhookRepository) GetAll() ([]models.WebhookSubscription, error) {
+	rows, err := r.db.Query(`
+		SELECT id, callback_url, topic, secret, lease_seconds, verified_at, expires_at, created_at
+		FROM webhook_subscriptions
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookSubscriptions(rows)
+}
+
+// GetMatchingTopic returns every non-expired subscription matching topic "*"
+// or the given TMDB ID.
+func (r *WebhookRepository) GetMatchingTopic(tmdbID int) ([]models.WebhookSubscription, error) {
+	now := timeutil.Now()
+	rows, err := r.db.Query(`
+		SELECT id, callback_url, topic, secret, lease_seconds, verified_at, expires_at, created_at
+		FROM webhook_subscriptions
+		WHERE (topic = '*' OR topic = ?)
+			AND verified_at IS NOT NULL
+			AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY id
+	`, strconv.Itoa(tmdbID), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookSubscriptions(rows)
+}
+
+// Delete removes a subscription by ID.
+func (r *WebhookRepository) Delete(id int64) error {
+	_, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	return err
+}
+
+// DeleteExpired removes subscriptions whose lease has expired without renewal.
+func (r *WebhookRepository) DeleteExpired() error {
+	_, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE expires_at IS NOT NULL AND expires_at <= ?`, timeutil.Now())
+	return err
+}
+
+func scanWebhookSubscriptions(rows *sql.Rows) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		var verifiedAt, expiresAt sql.NullTime
+		if err := rows.Scan(&sub.ID, &sub.CallbackURL, &sub.Topic, &sub.Secret, &sub.LeaseSeconds, &verifiedAt, &expiresAt, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		if verifiedAt.Valid {
+			sub.VerifiedAt = &verifiedAt.Time
+		}
+		if expiresAt.Valid {
+			sub.ExpiresAt = &expiresAt.Time
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// serviceName matches tvtracker.proto's "package tvtracker.rpc" plus
+// service name, used to build the Twirp route prefix.
+const serviceName = "tvtracker.rpc.TVTrackerService"
+
+// twirpError is Twirp's wire error shape: {"code": ..., "msg": ...}.
+type twirpError struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// rpcMethod is one registered RPC: decode the request, run it against svc
+// with the caller's ID, return the response to be JSON-encoded.
+type rpcMethod func(callerID string, body []byte) (any, error)
+
+// NewHandler returns an http.Handler serving TVTrackerService the way
+// protoc-gen-twirp's generated server would: POST
+// /twirp/tvtracker.rpc.TVTrackerService/<Method> with a JSON request body,
+// a JSON response body on success, and Twirp's {"code","msg"} error shape
+// otherwise. callerID is taken from the request's Bearer token, left for
+// the configured Authorizer to interpret.
+func NewHandler(svc *TVTrackerService) http.Handler {
+	prefix := "/twirp/" + serviceName + "/"
+	methods := map[string]rpcMethod{
+		"SearchTV": func(callerID string, body []byte) (any, error) {
+			var req SearchTVRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.SearchTV(callerID, req)
+		},
+		"AddShow": func(callerID string, body []byte) (any, error) {
+			var req AddShowRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.AddShow(callerID, req)
+		},
+		"ListPendingTasks": func(callerID string, body []byte) (any, error) {
+			var req ListPendingTasksRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.ListPendingTasks(callerID, req)
+		},
+		"CompleteTask": func(callerID string, body []byte) (any, error) {
+			var req CompleteTaskRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.CompleteTask(callerID, req)
+		},
+		"RefreshTMDBCache": func(callerID string, body []byte) (any, error) {
+			var req RefreshTMDBCacheRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.RefreshTMDBCache(callerID, req)
+		},
+		"TriggerDailyReport": func(callerID string, body []byte) (any, error) {
+			var req TriggerDailyReportRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return svc.TriggerDailyReport(callerID, req)
+		},
+	}
+
+	mux := http.NewServeMux()
+	for name, fn := range methods {
+		mux.Handle(prefix+name, serveRPC(fn))
+	}
+	return mux
+}
+
+// serveRPC wraps one rpcMethod as an http.Handler.
+func serveRPC(fn rpcMethod) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeTwirpError(w, http.StatusMethodNotAllowed, "bad_route", "twirp RPCs must be called with POST")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeTwirpError(w, http.StatusBadRequest, "malformed", "failed to read request body")
+			return
+		}
+
+		resp, err := fn(callerIDFromRequest(r), body)
+		if err != nil {
+			var permErr *PermissionError
+			if errors.As(err, &permErr) {
+				writeTwirpError(w, http.StatusForbidden, "permission_denied", err.Error())
+				return
+			}
+			writeTwirpError(w, http.StatusInternalServerError, "internal", err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// callerIDFromRequest extracts the bearer token, if any, for the configured
+// Authorizer to interpret; an unauthenticated request is passed through as
+// an empty callerID and left to Authorize to reject.
+func callerIDFromRequest(r *http.Request) string {
+	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return parts[1]
+}
+
+func writeTwirpError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(twirpError{Code: code, Msg: msg})
+}
@@ -0,0 +1,283 @@
+// Package rpc is the Go-side mirror of tvtracker.proto. The tree has no
+// protoc/twirp-gen step wired into its build, so the request/response types
+// and the TVTrackerService implementation below are hand-written with the
+// same shape the generated Twirp code would have (see internal/proto for
+// the same convention applied to TaskPayload); they can be swapped for
+// generated code later without changing call sites. server.go hand-rolls
+// the Twirp wire protocol (JSON over POST /twirp/<Service>/<Method>) that
+// protoc-gen-twirp would otherwise emit.
+package rpc
+
+import (
+	"fmt"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/service"
+	"tv-tracker/internal/tmdb"
+)
+
+// Role is the authorization level a caller needs for an RPC. See each
+// TVTrackerService method's doc comment for which role it requires.
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // read-only: SearchTV, ListPendingTasks
+	RoleEditor Role = "editor" // mutating: AddShow, CompleteTask, RefreshTMDBCache, TriggerDailyReport
+	RoleAdmin  Role = "admin"  // reserved for future operator-only RPCs
+)
+
+// roleRank orders roles so Authorize can treat higher roles as satisfying a
+// lower requirement, the same way repository.BotRole's owner/subscriber
+// tiers work.
+var roleRank = map[Role]int{RoleViewer: 0, RoleEditor: 1, RoleAdmin: 2}
+
+// Authorizer gates an RPC call by the caller's identity (an API key, a bot
+// chat ID, whatever the embedding transport authenticates) and the role the
+// method requires. Implementations live outside this package so rpc stays
+// agnostic to how callers are authenticated.
+type Authorizer interface {
+	Authorize(callerID string, required Role) error
+}
+
+// PermissionError is returned by an Authorizer when a caller's role doesn't
+// meet a method's requirement. server.go matches on this type to map the
+// rejection to Twirp's "permission_denied" code instead of an internal
+// error, so callers can tell "not allowed" apart from "something broke".
+type PermissionError struct {
+	Role     Role
+	Required Role
+}
+
+// Error implements error.
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("role %q cannot call a %q-gated method", e.Role, e.Required)
+}
+
+// StaticAuthorizer grants every caller a single fixed role, useful for a
+// trusted LAN deployment or local testing where per-caller identity isn't
+// worth modeling yet.
+type StaticAuthorizer struct {
+	Role Role
+}
+
+// Authorize implements Authorizer.
+func (a StaticAuthorizer) Authorize(_ string, required Role) error {
+	if roleRank[a.Role] < roleRank[required] {
+		return &PermissionError{Role: a.Role, Required: required}
+	}
+	return nil
+}
+
+// TVTrackerService implements the methods declared in tvtracker.proto,
+// wired to the same repositories and services the HTTP handlers use.
+type TVTrackerService struct {
+	tmdbClient *tmdb.Client
+	subManager *service.SubscriptionManager
+	taskRepo   *repository.TaskRepository
+	cacheSvc   *service.TMDBCacheService
+	reportSvc  service.ReportSender
+	authorizer Authorizer
+}
+
+// NewTVTrackerService creates a new TVTrackerService. reportSvc may be nil,
+// in which case TriggerDailyReport fails with an error instead of panicking.
+func NewTVTrackerService(
+	tmdbClient *tmdb.Client,
+	subManager *service.SubscriptionManager,
+	taskRepo *repository.TaskRepository,
+	cacheSvc *service.TMDBCacheService,
+	reportSvc service.ReportSender,
+	authorizer Authorizer,
+) *TVTrackerService {
+	return &TVTrackerService{
+		tmdbClient: tmdbClient,
+		subManager: subManager,
+		taskRepo:   taskRepo,
+		cacheSvc:   cacheSvc,
+		reportSvc:  reportSvc,
+		authorizer: authorizer,
+	}
+}
+
+// SearchTVRequest is SearchTVRequest in tvtracker.proto.
+type SearchTVRequest struct {
+	Query string `json:"query"`
+}
+
+// SearchResult is SearchResult in tvtracker.proto.
+type SearchResult struct {
+	TMDBID       int64  `json:"tmdb_id"`
+	Name         string `json:"name"`
+	PosterPath   string `json:"poster_path"`
+	FirstAirDate string `json:"first_air_date"`
+}
+
+// SearchTVResponse is SearchTVResponse in tvtracker.proto.
+type SearchTVResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// SearchTV requires RoleViewer.
+func (s *TVTrackerService) SearchTV(callerID string, req SearchTVRequest) (SearchTVResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleViewer); err != nil {
+		return SearchTVResponse{}, err
+	}
+
+	results, err := s.tmdbClient.SearchTV(req.Query)
+	if err != nil {
+		return SearchTVResponse{}, fmt.Errorf("search tv: %w", err)
+	}
+
+	resp := SearchTVResponse{Results: make([]SearchResult, len(results))}
+	for i, r := range results {
+		resp.Results[i] = SearchResult{
+			TMDBID:       int64(r.ID),
+			Name:         r.Name,
+			PosterPath:   r.PosterPath,
+			FirstAirDate: r.FirstAirDate,
+		}
+	}
+	return resp, nil
+}
+
+// AddShowRequest is AddShowRequest in tvtracker.proto.
+type AddShowRequest struct {
+	TMDBID int64 `json:"tmdb_id"`
+}
+
+// AddShowResponse is AddShowResponse in tvtracker.proto.
+type AddShowResponse struct {
+	TVShowID          int64  `json:"tv_show_id"`
+	Name              string `json:"name"`
+	AlreadySubscribed bool   `json:"already_subscribed"`
+}
+
+// AddShow requires RoleEditor.
+func (s *TVTrackerService) AddShow(callerID string, req AddShowRequest) (AddShowResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleEditor); err != nil {
+		return AddShowResponse{}, err
+	}
+
+	show, alreadySubscribed, err := s.subManager.Subscribe(int(req.TMDBID))
+	if err != nil {
+		return AddShowResponse{}, fmt.Errorf("add show: %w", err)
+	}
+	return AddShowResponse{
+		TVShowID:          show.ID,
+		Name:              show.Name,
+		AlreadySubscribed: alreadySubscribed,
+	}, nil
+}
+
+// ListPendingTasksRequest is ListPendingTasksRequest in tvtracker.proto.
+type ListPendingTasksRequest struct {
+	TaskType string `json:"task_type"`
+}
+
+// Task is Task in tvtracker.proto.
+type Task struct {
+	ID          int64  `json:"id"`
+	TVShowID    int64  `json:"tv_show_id"`
+	TVShowName  string `json:"tv_show_name"`
+	TaskType    string `json:"task_type"`
+	EpisodeID   string `json:"episode_id"`
+	Description string `json:"description"`
+}
+
+// ListPendingTasksResponse is ListPendingTasksResponse in tvtracker.proto.
+type ListPendingTasksResponse struct {
+	Tasks []Task `json:"tasks"`
+}
+
+// ListPendingTasks requires RoleViewer. An empty TaskType lists every type.
+func (s *TVTrackerService) ListPendingTasks(callerID string, req ListPendingTasksRequest) (ListPendingTasksResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleViewer); err != nil {
+		return ListPendingTasksResponse{}, err
+	}
+
+	var tasks []models.Task
+	var err error
+	if req.TaskType == "" {
+		tasks, err = s.taskRepo.GetAllPending()
+	} else {
+		tasks, err = s.taskRepo.GetPendingByType(models.TaskType(req.TaskType))
+	}
+	if err != nil {
+		return ListPendingTasksResponse{}, fmt.Errorf("list pending tasks: %w", err)
+	}
+
+	resp := ListPendingTasksResponse{Tasks: make([]Task, len(tasks))}
+	for i, t := range tasks {
+		resp.Tasks[i] = Task{
+			ID:          t.ID,
+			TVShowID:    t.TVShowID,
+			TVShowName:  t.TVShowName,
+			TaskType:    string(t.TaskType),
+			EpisodeID:   t.EpisodeID,
+			Description: t.Description,
+		}
+	}
+	return resp, nil
+}
+
+// CompleteTaskRequest is CompleteTaskRequest in tvtracker.proto.
+type CompleteTaskRequest struct {
+	TaskID int64 `json:"task_id"`
+}
+
+// CompleteTaskResponse is CompleteTaskResponse in tvtracker.proto.
+type CompleteTaskResponse struct{}
+
+// CompleteTask requires RoleEditor.
+func (s *TVTrackerService) CompleteTask(callerID string, req CompleteTaskRequest) (CompleteTaskResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleEditor); err != nil {
+		return CompleteTaskResponse{}, err
+	}
+	if err := s.taskRepo.Complete(req.TaskID); err != nil {
+		return CompleteTaskResponse{}, fmt.Errorf("complete task: %w", err)
+	}
+	return CompleteTaskResponse{}, nil
+}
+
+// RefreshTMDBCacheRequest is RefreshTMDBCacheRequest in tvtracker.proto.
+type RefreshTMDBCacheRequest struct {
+	TMDBID int64 `json:"tmdb_id"`
+}
+
+// RefreshTMDBCacheResponse is RefreshTMDBCacheResponse in tvtracker.proto.
+type RefreshTMDBCacheResponse struct {
+	Name string `json:"name"`
+}
+
+// RefreshTMDBCache requires RoleEditor.
+func (s *TVTrackerService) RefreshTMDBCache(callerID string, req RefreshTMDBCacheRequest) (RefreshTMDBCacheResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleEditor); err != nil {
+		return RefreshTMDBCacheResponse{}, err
+	}
+	details, err := s.cacheSvc.Refresh(int(req.TMDBID))
+	if err != nil {
+		return RefreshTMDBCacheResponse{}, fmt.Errorf("refresh tmdb cache: %w", err)
+	}
+	return RefreshTMDBCacheResponse{Name: details.Name}, nil
+}
+
+// TriggerDailyReportRequest is TriggerDailyReportRequest in tvtracker.proto.
+type TriggerDailyReportRequest struct{}
+
+// TriggerDailyReportResponse is TriggerDailyReportResponse in tvtracker.proto.
+type TriggerDailyReportResponse struct{}
+
+// TriggerDailyReport requires RoleEditor.
+func (s *TVTrackerService) TriggerDailyReport(callerID string, req TriggerDailyReportRequest) (TriggerDailyReportResponse, error) {
+	if err := s.authorizer.Authorize(callerID, RoleEditor); err != nil {
+		return TriggerDailyReportResponse{}, err
+	}
+	if s.reportSvc == nil {
+		return TriggerDailyReportResponse{}, fmt.Errorf("daily report sender not configured")
+	}
+	if err := s.reportSvc.SendDailyReport(); err != nil {
+		return TriggerDailyReportResponse{}, fmt.Errorf("trigger daily report: %w", err)
+	}
+	return TriggerDailyReportResponse{}, nil
+}
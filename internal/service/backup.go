@@ -1,6 +1,13 @@
 package service
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -8,44 +15,75 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"tv-tracker/internal/backupstore"
+	"tv-tracker/internal/events"
+	"tv-tracker/internal/repository"
 )
 
 // BackupService handles database backup operations
 // Requirements: 11.1, 11.2, 11.3
 type BackupService struct {
+	db         *sql.DB
 	dbPath     string
 	backupDir  string
 	maxBackups int
+
+	targets   []backupstore.Target
+	retention backupstore.RetentionPolicy
+
+	router *events.Router
 }
 
-// NewBackupService creates a new BackupService
-func NewBackupService(dbPath, backupDir string) *BackupService {
+// NewBackupService creates a new BackupService. db must be the same
+// connection pool the rest of the app uses against dbPath, since backup and
+// restore both need to coordinate with it directly.
+func NewBackupService(db *sql.DB, dbPath, backupDir string) *BackupService {
 	return &BackupService{
+		db:         db,
 		dbPath:     dbPath,
 		backupDir:  backupDir,
 		maxBackups: 4, // Keep last 4 weekly backups
+		retention:  backupstore.RetentionPolicy{KeepLast: 4, KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 6},
 	}
 }
 
-// Backup creates a backup of the database
+// AddTarget registers an object-storage/WebDAV/local destination that
+// PushSnapshot pushes new snapshots to, in addition to the local backupDir.
+// Targets are pushed to in the order they were added.
+func (b *BackupService) AddTarget(t backupstore.Target) {
+	b.targets = append(b.targets, t)
+}
+
+// SetRetentionPolicy overrides the default keep-last/daily/weekly/monthly
+// retention applied to every target after a PushSnapshot.
+func (b *BackupService) SetRetentionPolicy(policy backupstore.RetentionPolicy) {
+	b.retention = policy
+}
+
+// SetRouter configures an events.Router to alert on a failed PushSnapshot via
+// events.KindBackupFailed. Left nil, PushSnapshot failures are only reported
+// to the caller.
+func (b *BackupService) SetRouter(router *events.Router) {
+	b.router = router
+}
+
+// Backup creates an online backup of the database using SQLite's backup API,
+// so the snapshot stays consistent even while writes are in flight (unlike a
+// raw file copy, which can capture a torn page).
 // Requirements: 11.1, 11.2
 func (b *BackupService) Backup() (string, error) {
-	// Ensure backup directory exists
 	if err := os.MkdirAll(b.backupDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	// Generate backup filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_150405")
-	backupName := fmt.Sprintf("tv_tracker_backup_%s.db", timestamp)
-	backupPath := filepath.Join(b.backupDir, backupName)
-
-	// Copy database file
-	if err := copyFile(b.dbPath, backupPath); err != nil {
-		return "", fmt.Errorf("failed to copy database: %w", err)
+	backupPath := b.backupPath("")
+	if err := b.backupOnline(backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up database: %w", err)
 	}
 
-	// Clean old backups
 	if err := b.CleanOldBackups(); err != nil {
 		// Log but don't fail - backup was successful
 		fmt.Printf("Warning: failed to clean old backups: %v\n", err)
@@ -54,6 +92,529 @@ func (b *BackupService) Backup() (string, error) {
 	return backupPath, nil
 }
 
+// BackupCompressed creates an online backup and gzip-compresses it in place,
+// removing the uncompressed .db file afterwards.
+func (b *BackupService) BackupCompressed() (string, error) {
+	backupPath, err := b.Backup()
+	if err != nil {
+		return "", err
+	}
+
+	compressedPath, err := gzipFile(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress backup: %w", err)
+	}
+	if err := os.Remove(backupPath); err != nil {
+		return "", fmt.Errorf("failed to remove uncompressed backup: %w", err)
+	}
+
+	return compressedPath, nil
+}
+
+// VacuumInto creates a backup via VACUUM INTO, which snapshots and compacts
+// the database file in a single pass instead of copying it as-is.
+func (b *BackupService) VacuumInto() (string, error) {
+	if err := os.MkdirAll(b.backupDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	backupPath := b.backupPath("_vacuum")
+	if _, err := b.db.Exec(fmt.Sprintf("VACUUM INTO '%s'", backupPath)); err != nil {
+		return "", fmt.Errorf("failed to vacuum database into backup: %w", err)
+	}
+
+	if err := b.CleanOldBackups(); err != nil {
+		fmt.Printf("Warning: failed to clean old backups: %v\n", err)
+	}
+
+	return backupPath, nil
+}
+
+// Manifest describes the contents of a snapshot tarball, stored alongside
+// the database file as manifest.json so a snapshot pulled from a target can
+// be sanity-checked (schema compatibility, corruption) before it's restored.
+type Manifest struct {
+	SchemaVersion      int    `json:"schema_version"`
+	Checksum           string `json:"checksum_sha256"` // of the database file, not the tarball
+	ShowCount          int    `json:"show_count"`
+	MetadataCacheCount int    `json:"metadata_cache_count"`
+	CreatedAt          string `json:"created_at"`
+}
+
+// buildManifest stamps a Manifest for the database file at dbPath.
+func (b *BackupService) buildManifest(dbPath string) (Manifest, error) {
+	sum, err := sha256File(dbPath)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to checksum database file: %w", err)
+	}
+
+	var showCount, cacheCount int
+	if err := b.db.QueryRow(`SELECT COUNT(*) FROM tv_shows`).Scan(&showCount); err != nil {
+		return Manifest{}, fmt.Errorf("failed to count tv_shows: %w", err)
+	}
+	if err := b.db.QueryRow(`SELECT COUNT(*) FROM metadata_cache`).Scan(&cacheCount); err != nil {
+		return Manifest{}, fmt.Errorf("failed to count metadata_cache: %w", err)
+	}
+
+	return Manifest{
+		SchemaVersion:      repository.CurrentSchemaVersion(),
+		Checksum:           sum,
+		ShowCount:          showCount,
+		MetadataCacheCount: cacheCount,
+		CreatedAt:          time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// CreateSnapshotTarball produces a VACUUM INTO database snapshot plus a
+// manifest.json describing it, bundled into a single gzipped tarball. The
+// caller is responsible for removing the returned path once it's no longer
+// needed (e.g. after PushSnapshot has uploaded it to every target).
+func (b *BackupService) CreateSnapshotTarball() (string, error) {
+	dbPath, err := b.VacuumInto()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(dbPath)
+
+	manifest, err := b.buildManifest(dbPath)
+	if err != nil {
+		return "", err
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	tarballPath := strings.TrimSuffix(dbPath, ".db") + ".tar.gz"
+	if err := writeSnapshotTarball(tarballPath, dbPath, manifestJSON); err != nil {
+		return "", err
+	}
+	return tarballPath, nil
+}
+
+// writeSnapshotTarball bundles dbPath and manifest.json (content
+// manifestJSON) into a gzipped tarball at tarballPath.
+func writeSnapshotTarball(tarballPath, dbPath string, manifestJSON []byte) error {
+	out, err := os.Create(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	dbInfo, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	if err := addFileToTar(tw, "snapshot.db", dbInfo.ModTime(), dbPath); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    "manifest.json",
+		Size:    int64(len(manifestJSON)),
+		Mode:    0644,
+		ModTime: dbInfo.ModTime(),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addFileToTar(tw *tar.Writer, name string, modTime time.Time, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Size:    info.Size(),
+		Mode:    0644,
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// PushSnapshot builds a snapshot tarball and uploads it to every registered
+// target (see AddTarget), then applies the retention policy to each target
+// independently. If no targets were registered, the tarball is left in
+// backupDir and treated as the sole "local" target.
+func (b *BackupService) PushSnapshot(ctx context.Context) (string, error) {
+	tarballPath, err := b.CreateSnapshotTarball()
+	if err != nil {
+		return "", err
+	}
+	key := filepath.Base(tarballPath)
+
+	targets := b.targets
+	if len(targets) == 0 {
+		local, err := backupstore.NewLocalTarget(b.backupDir)
+		if err != nil {
+			return "", err
+		}
+		targets = []backupstore.Target{local}
+		// The local target writes key into backupDir, where the tarball
+		// already is; upload from a renamed copy so Put doesn't truncate the
+		// file it is reading.
+		staged := tarballPath + ".partial"
+		if err := os.Rename(tarballPath, staged); err != nil {
+			return "", err
+		}
+		tarballPath = staged
+	}
+	defer os.Remove(tarballPath)
+
+	for _, target := range targets {
+		if err := b.pushToTarget(ctx, target, key, tarballPath); err != nil {
+			pushErr := fmt.Errorf("failed to push snapshot to target %s: %w", target.Name(), err)
+			b.notifyBackupFailed(ctx, pushErr)
+			return "", pushErr
+		}
+	}
+
+	return key, nil
+}
+
+// notifyBackupFailed alerts the configured router, if any, that PushSnapshot
+// failed. Errors from the router itself are swallowed: PushSnapshot's own
+// error is already the caller's signal that something went wrong.
+func (b *BackupService) notifyBackupFailed(ctx context.Context, pushErr error) {
+	if b.router == nil {
+		return
+	}
+	b.router.Route(ctx, events.Notification{
+		Kind:  events.KindBackupFailed,
+		Title: "TV Tracker 备份失败",
+		Body:  pushErr.Error(),
+		Data:  map[string]any{"error": pushErr.Error()},
+	})
+}
+
+func (b *BackupService) pushToTarget(ctx context.Context, target backupstore.Target, key, tarballPath string) error {
+	info, err := os.Stat(tarballPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := target.Put(ctx, key, f, info.Size()); err != nil {
+		return err
+	}
+
+	snapshots, err := target.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots for retention: %w", err)
+	}
+	_, remove := backupstore.ApplyRetention(snapshots, b.retention)
+	for _, s := range remove {
+		if err := target.Delete(ctx, s.Key); err != nil {
+			return fmt.Errorf("failed to prune snapshot %s: %w", s.Key, err)
+		}
+	}
+	return nil
+}
+
+// ListSnapshots lists the snapshots available on every registered target,
+// keyed by target name.
+func (b *BackupService) ListSnapshots(ctx context.Context) (map[string][]backupstore.Snapshot, error) {
+	result := make(map[string][]backupstore.Snapshot, len(b.targets))
+	for _, target := range b.targets {
+		snapshots, err := target.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots on target %s: %w", target.Name(), err)
+		}
+		result[target.Name()] = snapshots
+	}
+	return result, nil
+}
+
+// RestoreSnapshot fetches the snapshot tarball named key from the named
+// target, verifies its manifest checksum against the extracted database
+// file, and restores it as the live database.
+func (b *BackupService) RestoreSnapshot(ctx context.Context, targetName, key string) error {
+	var target backupstore.Target
+	for _, t := range b.targets {
+		if t.Name() == targetName {
+			target = t
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no backup target registered with name %q", targetName)
+	}
+
+	r, err := target.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to fetch snapshot %s: %w", key, err)
+	}
+	defer r.Close()
+
+	return b.restoreFromTarball(r)
+}
+
+// RestoreUploadedSnapshot restores the live database from a snapshot
+// tarball read directly from r (e.g. a multipart file upload to
+// POST /api/backup/restore), rather than one fetched from a registered
+// target.
+func (b *BackupService) RestoreUploadedSnapshot(r io.Reader) error {
+	return b.restoreFromTarball(r)
+}
+
+// restoreFromTarball extracts a snapshot tarball, verifies its manifest
+// checksum against the extracted database file, and restores it as the
+// live database.
+func (b *BackupService) restoreFromTarball(r io.Reader) error {
+	dbPath, manifest, err := extractSnapshotTarball(r)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dbPath)
+
+	sum, err := sha256File(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to checksum extracted snapshot: %w", err)
+	}
+	if sum != manifest.Checksum {
+		return fmt.Errorf("snapshot checksum mismatch: manifest says %s, extracted file hashes to %s", manifest.Checksum, sum)
+	}
+
+	return b.Restore(dbPath)
+}
+
+// extractSnapshotTarball extracts a tarball produced by CreateSnapshotTarball
+// into a temporary database file and returns its path along with the parsed
+// manifest. The caller is responsible for removing the returned path.
+func extractSnapshotTarball(r io.Reader) (string, Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tmp, err := os.CreateTemp("", "tv_tracker_snapshot_*.db")
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	defer tmp.Close()
+
+	var manifest Manifest
+	var sawDB, sawManifest bool
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return "", Manifest{}, fmt.Errorf("failed to read tarball: %w", err)
+		}
+
+		switch header.Name {
+		case "snapshot.db":
+			if _, err := io.Copy(tmp, tr); err != nil {
+				os.Remove(tmp.Name())
+				return "", Manifest{}, err
+			}
+			sawDB = true
+		case "manifest.json":
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				os.Remove(tmp.Name())
+				return "", Manifest{}, fmt.Errorf("failed to parse manifest.json: %w", err)
+			}
+			sawManifest = true
+		}
+	}
+
+	if !sawDB || !sawManifest {
+		os.Remove(tmp.Name())
+		return "", Manifest{}, fmt.Errorf("snapshot tarball is missing snapshot.db or manifest.json")
+	}
+
+	return tmp.Name(), manifest, tmp.Sync()
+}
+
+// sha256File returns the hex-encoded SHA-256 checksum of the file at path.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Restore replaces the live database with backupPath after verifying its
+// integrity, so a truncated or corrupt backup can't silently get swapped in.
+// It restores via the SQLite backup API (the same mechanism backupOnline
+// uses, run in reverse) rather than closing b.db and copying the file over
+// dbPath: b.db is the same connection pool the rest of the app uses, and
+// closing it would be terminal for every query process-wide, not something
+// a later query transparently reopens.
+func (b *BackupService) Restore(backupPath string) error {
+	if err := verifyIntegrity(backupPath); err != nil {
+		return fmt.Errorf("backup failed integrity check: %w", err)
+	}
+
+	srcDB, err := sql.Open("sqlite3", backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer srcDB.Close()
+
+	ctx := context.Background()
+
+	srcConn, err := srcDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destConn, err := b.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			srcSQLiteConn, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source driver connection type %T", srcRaw)
+			}
+			destSQLiteConn, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination driver connection type %T", destRaw)
+			}
+
+			backup, err := destSQLiteConn.Backup("main", srcSQLiteConn, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start sqlite restore: %w", err)
+			}
+			defer backup.Close()
+
+			done, err := backup.Step(-1)
+			if err != nil {
+				return fmt.Errorf("restore step failed: %w", err)
+			}
+			if !done {
+				return fmt.Errorf("restore did not complete in a single step")
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// RestoreFromCompressed decompresses a gzip backup and restores it.
+func (b *BackupService) RestoreFromCompressed(backupPath string) error {
+	tmpPath, err := gunzipToTemp(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	return b.Restore(tmpPath)
+}
+
+// backupOnline performs a live backup via the SQLite backup API (sqlite3_backup),
+// obtained through database/sql's Conn.Raw, rather than copying the file on disk.
+func (b *BackupService) backupOnline(destPath string) error {
+	destDB, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return err
+	}
+	defer destDB.Close()
+
+	ctx := context.Background()
+
+	srcConn, err := b.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			srcSQLiteConn, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source driver connection type %T", srcRaw)
+			}
+			destSQLiteConn, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination driver connection type %T", destRaw)
+			}
+
+			backup, err := destSQLiteConn.Backup("main", srcSQLiteConn, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start sqlite backup: %w", err)
+			}
+			defer backup.Close()
+
+			done, err := backup.Step(-1)
+			if err != nil {
+				return fmt.Errorf("backup step failed: %w", err)
+			}
+			if !done {
+				return fmt.Errorf("backup did not complete in a single step")
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// verifyIntegrity runs PRAGMA integrity_check against a standalone database
+// file, used to validate a backup before it's restored over the live one.
+func verifyIntegrity(path string) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+	return nil
+}
+
 // GetLastBackupTime returns the time of the most recent backup
 // Requirements: 11.4
 func (b *BackupService) GetLastBackupTime() (time.Time, error) {
@@ -76,7 +637,6 @@ func (b *BackupService) GetLastBackupTime() (time.Time, error) {
 	return info.ModTime(), nil
 }
 
-
 // CleanOldBackups removes old backups, keeping only the most recent ones
 // Requirements: 11.3
 func (b *BackupService) CleanOldBackups() error {
@@ -111,7 +671,10 @@ func (b *BackupService) listBackups() ([]string, error) {
 
 	var backups []string
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "tv_tracker_backup_") && strings.HasSuffix(entry.Name(), ".db") {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "tv_tracker_backup_") {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".db") || strings.HasSuffix(entry.Name(), ".db.gz") {
 			backups = append(backups, filepath.Join(b.backupDir, entry.Name()))
 		}
 	}
@@ -122,24 +685,65 @@ func (b *BackupService) listBackups() ([]string, error) {
 	return backups, nil
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+// backupPath builds a timestamped backup file path, with an optional suffix
+// inserted before the .db extension (e.g. "_vacuum").
+func (b *BackupService) backupPath(suffix string) string {
+	timestamp := time.Now().Format("2006-01-02_150405")
+	backupName := fmt.Sprintf("tv_tracker_backup_%s%s.db", timestamp, suffix)
+	return filepath.Join(b.backupDir, backupName)
+}
+
+// gzipFile compresses path into path+".gz".
+func gzipFile(path string) (string, error) {
+	src, err := os.Open(path)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer sourceFile.Close()
+	defer src.Close()
 
-	destFile, err := os.Create(dst)
+	destPath := path + ".gz"
+	dest, err := os.Create(destPath)
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer dest.Close()
+
+	gz := gzip.NewWriter(dest)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
+	return destPath, dest.Sync()
+}
+
+// gunzipToTemp decompresses a gzip backup into a temporary file and returns
+// its path; the caller is responsible for removing it.
+func gunzipToTemp(path string) (string, error) {
+	src, err := os.Open(path)
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer src.Close()
+
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tmp, err := os.CreateTemp("", "tv_tracker_restore_*.db")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, gz); err != nil {
+		return "", err
 	}
 
-	return destFile.Sync()
+	return tmp.Name(), tmp.Sync()
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/timeutil"
+)
+
+// BackupScheduler runs BackupService.PushSnapshot on a cron schedule,
+// mirroring SyncScheduler's single dedicated cron-driven background job
+// rather than registering with Scheduler, and keeps the last run's outcome
+// for Status.
+type BackupScheduler struct {
+	backupSvc *BackupService
+	schedule  *cronutil.Schedule
+	stopChan  chan struct{}
+	mu        sync.Mutex
+	running   bool
+	lastKey   string
+	lastErr   error
+	lastRunAt time.Time
+}
+
+// NewBackupScheduler creates a new BackupScheduler from a 5-field cron
+// expression (e.g. "0 3 * * *" for daily at 3am).
+func NewBackupScheduler(backupSvc *BackupService, cronExpr string) (*BackupScheduler, error) {
+	schedule, err := cronutil.Parse(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup cron expression %q: %w", cronExpr, err)
+	}
+	return &BackupScheduler{
+		backupSvc: backupSvc,
+		schedule:  schedule,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+// Start begins running backups on their cron schedule in the background.
+func (s *BackupScheduler) Start() {
+	go s.run()
+}
+
+// Stop stops the scheduler.
+func (s *BackupScheduler) Stop() {
+	close(s.stopChan)
+}
+
+func (s *BackupScheduler) run() {
+	for {
+		next := s.schedule.Next(timeutil.Now())
+		duration := time.Until(next)
+		log.Printf("Next scheduled backup at %s (in %v)", next.Format("2006-01-02 15:04:05"), duration.Round(time.Minute))
+
+		select {
+		case <-time.After(duration):
+			if _, err := s.RunNow(); err != nil {
+				log.Printf("Scheduled backup failed: %v", err)
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// RunNow pushes a snapshot immediately, regardless of the schedule.
+func (s *BackupScheduler) RunNow() (string, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return "", fmt.Errorf("a backup is already in progress")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	key, err := s.backupSvc.PushSnapshot(context.Background())
+
+	s.mu.Lock()
+	s.running = false
+	s.lastKey = key
+	s.lastErr = err
+	s.lastRunAt = timeutil.Now()
+	s.mu.Unlock()
+
+	return key, err
+}
+
+// BackupScheduleStatus reports the outcome of the most recent scheduled
+// backup and when the next one is due, for the /api/backups status surface.
+type BackupScheduleStatus struct {
+	Running   bool
+	LastKey   string
+	LastError error
+	LastRunAt time.Time
+	NextRun   time.Time
+}
+
+// Status reports the last run outcome and the next scheduled run.
+func (s *BackupScheduler) Status() BackupScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return BackupScheduleStatus{
+		Running:   s.running,
+		LastKey:   s.lastKey,
+		LastError: s.lastErr,
+		LastRunAt: s.lastRunAt,
+		NextRun:   s.schedule.Next(timeutil.Now()),
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/release"
+)
+
+// Dispatcher applies a show's models.ShowPolicy overrides when deciding
+// whether a candidate release is worth surfacing and which notify.Registry
+// channel should hear about its tasks. It holds no state, so a single zero
+// value is shared by every caller.
+type Dispatcher struct{}
+
+// NewDispatcher creates a new Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Accepts reports whether info clears show's quality policy. An empty
+// QualityFilter accepts everything; QualityFilterExcludeCam rejects
+// cam/telesync/workprint rips. When PreferredResolution is set, info must
+// either match it or leave its own Resolution unparsed. When MinQuality is
+// set, info's Resolution must meet or exceed it by release.MeetsMinResolution
+// (an unparsed Resolution fails a non-empty floor, since it can't be
+// confirmed).
+func (d *Dispatcher) Accepts(show *models.TVShow, info *release.ReleaseInfo) bool {
+	policy := show.Policy()
+
+	if policy.QualityFilter == models.QualityFilterExcludeCam && info.Qiangban {
+		return false
+	}
+	if policy.PreferredResolution != "" && info.Resolution != "" && info.Resolution != policy.PreferredResolution {
+		return false
+	}
+	if !release.MeetsMinResolution(info.Resolution, policy.MinQuality) {
+		return false
+	}
+	return true
+}
+
+// Channels returns the notify.Registry channel names a task event for show
+// should be restricted to, or nil to mean "every channel subscribed to the
+// severity" (EventNotifier.SendEvent's existing default).
+func (d *Dispatcher) Channels(show *models.TVShow) []string {
+	if show.NotifyChannel == "" {
+		return nil
+	}
+	return []string{show.NotifyChannel}
+}
+
+// SkipsSpecials reports whether show's policy opts out of syncing and
+// tasking season 0 (specials).
+func (d *Dispatcher) SkipsSpecials(show *models.TVShow) bool {
+	return show.Policy().AutoSkipSpecials
+}
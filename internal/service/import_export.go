@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportExportService backs bulk subscribe via an OPML-style document
+// upload/download over the Telegram bot (see notify.TelegramBot's
+// tele.OnDocument handler), mirroring TaskBoardService's Import/Export but
+// scoped to TMDB-ID subscriptions instead of tasks, and offering a real
+// migration path beyond the raw SQLite backup.
+type ImportExportService struct {
+	subMgr *SubscriptionManager
+}
+
+// NewImportExportService creates a new ImportExportService.
+func NewImportExportService(subMgr *SubscriptionManager) *ImportExportService {
+	return &ImportExportService{subMgr: subMgr}
+}
+
+// OPMLImportResult is one TMDB ID's outcome within an ImportOPML call,
+// mirroring TaskBoardService.ImportResult's success/failure shape so one bad
+// ID doesn't abort the rest of the batch.
+type OPMLImportResult struct {
+	TMDBID int    `json:"tmdb_id"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes an ImportOPML call for FormatImportReport.
+type ImportReport struct {
+	Results   []OPMLImportResult `json:"results"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+}
+
+// opmlDocument is the subset of OPML 2.0 ImportOPML/ExportOPML round-trip:
+// one <outline> per subscription, its TMDB ID encoded as "tmdb://<id>" in
+// xmlUrl the same way flowerss and other feed-reader-style bots repurpose
+// OPML for non-RSS subscription lists.
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Title  string `xml:"title,attr"`
+	XMLURL string `xml:"xmlUrl,attr"`
+}
+
+const tmdbURLPrefix = "tmdb://"
+
+// ImportOPML reads TMDB IDs from r - either an OPML document with
+// <outline xmlUrl="tmdb://12345"/> entries, or a plain-text/JSON list with
+// one TMDB ID per line - and calls subMgr.Subscribe for each, continuing
+// past individual failures so one bad ID doesn't abort the batch.
+func (s *ImportExportService) ImportOPML(r io.Reader) (ImportReport, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return ImportReport{}, fmt.Errorf("failed to read import document: %w", err)
+	}
+
+	ids, err := parseTMDBIDs(raw)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{Results: make([]OPMLImportResult, 0, len(ids))}
+	for _, id := range ids {
+		if _, _, err := s.subMgr.Subscribe(id); err != nil {
+			report.Results = append(report.Results, OPMLImportResult{TMDBID: id, Error: err.Error()})
+			report.Failed++
+			continue
+		}
+		report.Results = append(report.Results, OPMLImportResult{TMDBID: id})
+		report.Succeeded++
+	}
+
+	return report, nil
+}
+
+// parseTMDBIDs extracts TMDB IDs from raw, accepting an OPML document
+// (detected by a leading '<'), a JSON array of integers, or one bare TMDB ID
+// per line.
+func parseTMDBIDs(raw []byte) ([]int, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(trimmed, "<") {
+		var doc opmlDocument
+		if err := xml.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse OPML document: %w", err)
+		}
+		ids := make([]int, 0, len(doc.Body.Outlines))
+		for _, outline := range doc.Body.Outlines {
+			idStr := strings.TrimPrefix(outline.XMLURL, tmdbURLPrefix)
+			id, err := strconv.Atoi(idStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid outline xmlUrl %q: %w", outline.XMLURL, err)
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+
+	if strings.HasPrefix(trimmed, "[") {
+		var ids []int
+		if err := json.Unmarshal(raw, &ids); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON import document: %w", err)
+		}
+		return ids, nil
+	}
+
+	var ids []int
+	scanner := bufio.NewScanner(strings.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TMDB ID line %q: %w", line, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import document: %w", err)
+	}
+	return ids, nil
+}
+
+// ExportOPML writes every subscribed show to w as an OPML document, one
+// <outline> per show with its TMDB ID encoded in xmlUrl, importable again
+// via ImportOPML.
+func (s *ImportExportService) ExportOPML(w io.Writer) error {
+	shows, err := s.subMgr.GetAllSubscriptions()
+	if err != nil {
+		return fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+
+	doc := opmlDocument{Body: opmlBody{Outlines: make([]opmlOutline, 0, len(shows))}}
+	for _, show := range shows {
+		doc.Body.Outlines = append(doc.Body.Outlines, opmlOutline{
+			Title:  show.Name,
+			XMLURL: fmt.Sprintf("%s%d", tmdbURLPrefix, show.TMDBID),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode OPML document: %w", err)
+	}
+	return nil
+}
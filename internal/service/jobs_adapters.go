@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tv-tracker/internal/jobs"
+	"tv-tracker/internal/models"
+)
+
+// Job types run through the internal/jobs queue by this package's adapters.
+const (
+	JobTypeTMDBRefresh = "tmdb_refresh"
+	JobTypeDailyReport = "daily_report"
+)
+
+// TMDBRefreshPayload identifies the show to refresh for a JobTypeTMDBRefresh
+// job, enqueued by SyncScheduler.RunNow in place of calling
+// TMDBCacheService.Refresh inline.
+type TMDBRefreshPayload struct {
+	TMDBID int `json:"tmdb_id"`
+}
+
+// NewTMDBRefreshHandler adapts cacheSvc.Refresh into a jobs.Handler, so a
+// transient TMDB error is retried with backoff instead of permanently
+// skipping that show until the next scheduled sync.
+func NewTMDBRefreshHandler(cacheSvc *TMDBCacheService) jobs.Handler {
+	return func(payload json.RawMessage) error {
+		var p TMDBRefreshPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("unmarshal tmdb refresh payload: %w", err)
+		}
+		_, err := cacheSvc.Refresh(p.TMDBID)
+		return err
+	}
+}
+
+// DailyReportPayload carries the tasks to report, captured at enqueue time
+// so a retried send doesn't need to re-query the task board.
+type DailyReportPayload struct {
+	Tasks []models.Task `json:"tasks"`
+}
+
+// DailyReportFunc delivers a daily report for the given tasks. It's a plain
+// func type rather than an interface so this package doesn't need to depend
+// on notify (notify already depends on service, for ImportExportService);
+// notify.Notifier.SendDailyReport satisfies it directly.
+type DailyReportFunc func(tasks []models.Task) error
+
+// NewDailyReportHandler adapts send into a jobs.Handler, so a failed report
+// delivery is retried with backoff instead of failing the request that
+// triggered it.
+func NewDailyReportHandler(send DailyReportFunc) jobs.Handler {
+	return func(payload json.RawMessage) error {
+		var p DailyReportPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("unmarshal daily report payload: %w", err)
+		}
+		return send(p.Tasks)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"tv-tracker/internal/metadata"
+	"tv-tracker/internal/tmdb"
+)
+
+// metadataToTVDetails adapts a provider-agnostic metadata.ShowDetails back
+// into tmdb.TVDetails so existing task-generation logic (which was written
+// against the TMDB shape) works unchanged regardless of which provider the
+// data actually came from.
+func metadataToTVDetails(tmdbID int, details *metadata.ShowDetails) *tmdb.TVDetails {
+	originCountry := []string(nil)
+	if details.OriginCountry != "" {
+		originCountry = []string{details.OriginCountry}
+	}
+
+	return &tmdb.TVDetails{
+		ID:               tmdbID,
+		Name:             details.Name,
+		Status:           details.Status,
+		OriginCountry:    originCountry,
+		NumberOfSeasons:  details.NumberOfSeasons,
+		NextEpisodeToAir: metadataToEpisodeInfo(details.NextEpisodeToAir),
+		LastEpisodeToAir: metadataToEpisodeInfo(details.LastEpisodeToAir),
+	}
+}
+
+func metadataToEpisodeInfo(ep *metadata.Episode) *tmdb.EpisodeInfo {
+	if ep == nil {
+		return nil
+	}
+	return &tmdb.EpisodeInfo{
+		AirDate:       ep.AirDate,
+		EpisodeNumber: ep.Episode,
+		SeasonNumber:  ep.Season,
+		Name:          ep.Name,
+		Overview:      ep.Overview,
+	}
+}
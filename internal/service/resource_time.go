@@ -0,0 +1,77 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultResourceTime is returned for any origin country with no configured
+// rule.
+const defaultResourceTime = "待定"
+
+// resourceTimeRules maps an upper-cased origin country code to the "HH:MM"
+// resource time inferred for shows from that country. It is a package-level
+// override point, following the same pattern as timeutil.SetNowFunc, so the
+// table can be swapped at startup (LoadResourceTimeRulesFile) or in tests
+// (SetResourceTimeRules) without changing InferResourceTime's signature or
+// any of its call sites.
+var resourceTimeRules = defaultResourceTimeRules()
+
+func defaultResourceTimeRules() map[string]string {
+	return map[string]string{
+		"US": "18:00",
+		"UK": "18:00",
+		"CA": "18:00",
+		"GB": "18:00", // GB is the ISO code for UK
+		"CN": "20:00",
+		"TW": "20:00",
+		"JP": "23:00",
+		"KR": "23:00",
+	}
+}
+
+// SetResourceTimeRules replaces the country -> resource time table used by
+// InferResourceTime. Passing nil resets it to the compiled-in defaults.
+func SetResourceTimeRules(rules map[string]string) {
+	if rules == nil {
+		resourceTimeRules = defaultResourceTimeRules()
+		return
+	}
+	resourceTimeRules = rules
+}
+
+// LoadResourceTimeRulesFile loads a JSON object of origin country -> "HH:MM"
+// from path and installs it via SetResourceTimeRules, so deployments can
+// tune resource times without a rebuild.
+func LoadResourceTimeRulesFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read resource time rules file: %w", err)
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse resource time rules file: %w", err)
+	}
+
+	rules := make(map[string]string, len(raw))
+	for country, value := range raw {
+		rules[strings.ToUpper(strings.TrimSpace(country))] = value
+	}
+	SetResourceTimeRules(rules)
+	return nil
+}
+
+// InferResourceTime infers the expected resource availability time based on
+// origin country, consulting resourceTimeRules (US/UK/CA -> "18:00",
+// CN/TW -> "20:00", JP/KR -> "23:00" by default, others -> "待定").
+func InferResourceTime(originCountry string) string {
+	country := strings.ToUpper(strings.TrimSpace(originCountry))
+
+	if value, ok := resourceTimeRules[country]; ok {
+		return value
+	}
+	return defaultResourceTime
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+)
+
+// ResourceTimeReconciler periodically re-infers ResourceTime for every show
+// that hasn't had it manually overridden, so shows pick up rule changes
+// (e.g. from LoadResourceTimeRulesFile) without needing a resubscribe.
+// Shows with ResourceTimeIsManual set are never touched.
+type ResourceTimeReconciler struct {
+	showRepo *repository.TVShowRepository
+	schedule *cronutil.Schedule
+	stopChan chan struct{}
+}
+
+// NewResourceTimeReconciler creates a new ResourceTimeReconciler from a
+// 5-field cron expression (e.g. "0 3 * * *" for nightly at 3am).
+func NewResourceTimeReconciler(showRepo *repository.TVShowRepository, cronExpr string) (*ResourceTimeReconciler, error) {
+	schedule, err := cronutil.Parse(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource time reconciler cron expression %q: %w", cronExpr, err)
+	}
+	return &ResourceTimeReconciler{
+		showRepo: showRepo,
+		schedule: schedule,
+		stopChan: make(chan struct{}),
+	}, nil
+}
+
+// Start begins running the reconciler on its cron schedule in the background.
+func (r *ResourceTimeReconciler) Start() {
+	go r.run()
+}
+
+// Stop stops the reconciler.
+func (r *ResourceTimeReconciler) Stop() {
+	close(r.stopChan)
+}
+
+func (r *ResourceTimeReconciler) run() {
+	for {
+		next := r.schedule.Next(timeutil.Now())
+		select {
+		case <-time.After(time.Until(next)):
+			if updated, err := r.RunNow(); err != nil {
+				log.Printf("resource time reconciler: run failed: %v", err)
+			} else {
+				log.Printf("resource time reconciler: updated %d show(s)", updated)
+			}
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+// RunNow re-infers ResourceTime for every non-manual show immediately and
+// returns how many shows were updated.
+func (r *ResourceTimeReconciler) RunNow() (int, error) {
+	shows, err := r.showRepo.GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get shows: %w", err)
+	}
+
+	updated := 0
+	for _, show := range shows {
+		if show.ResourceTimeIsManual {
+			continue
+		}
+
+		inferred := InferResourceTime(show.OriginCountry)
+		if inferred == show.ResourceTime {
+			continue
+		}
+
+		show.ResourceTime = inferred
+		if err := r.showRepo.Update(&show); err != nil {
+			log.Printf("resource time reconciler: failed to update show %d (%s): %v", show.TMDBID, show.Name, err)
+			continue
+		}
+		updated++
+	}
+	return updated, nil
+}
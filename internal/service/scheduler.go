@@ -1,133 +1,273 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
+	"sync"
 	"time"
+
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
 )
 
-// ReportSender interface for sending daily reports
-type ReportSender interface {
-	SendDailyReport() error
+// JobFunc is one scheduled unit of work, passed a context so a long-running
+// job (e.g. a cache warmer) can observe cancellation if Scheduler grows one
+// later.
+type JobFunc func(context.Context) error
+
+// jobOptions configures one registered job; set via the JobOption functions
+// below and passed to Scheduler.Register.
+type jobOptions struct {
+	timezone         *time.Location
+	jitter           time.Duration
+	maxCatchupWindow time.Duration
+}
+
+// JobOption configures a job registered with Scheduler.Register.
+type JobOption func(*jobOptions)
+
+// WithTimezone evaluates the job's cron expression in loc instead of the
+// server's local timezone, e.g. for a per-user report time.
+func WithTimezone(loc *time.Location) JobOption {
+	return func(o *jobOptions) { o.timezone = loc }
+}
+
+// WithJitter adds a random delay in [0, max) before each firing, so jobs
+// sharing a schedule (e.g. several cache warmers all set to run hourly)
+// don't all hit downstream services at the same instant.
+func WithJitter(max time.Duration) JobOption {
+	return func(o *jobOptions) { o.jitter = max }
+}
+
+// WithMaxCatchupWindow lets a job that was due while the process was down
+// run once immediately on Start, as long as the missed firing is within
+// window of now. The zero value (the default) never catches up a missed
+// run - the job just resumes its normal schedule.
+func WithMaxCatchupWindow(window time.Duration) JobOption {
+	return func(o *jobOptions) { o.maxCatchupWindow = window }
+}
+
+// job is one unit of work registered with Scheduler.
+type job struct {
+	id       string
+	schedule *cronutil.Schedule
+	fn       JobFunc
+	opts     jobOptions
+	stopChan chan struct{}
+}
+
+// next computes j's next scheduled firing after now, evaluated in
+// j.opts.timezone if one was set.
+func (j *job) next(now time.Time) time.Time {
+	if j.opts.timezone != nil {
+		now = now.In(j.opts.timezone)
+	}
+	return j.schedule.Next(now)
 }
 
-// Scheduler handles scheduled tasks
-// Requirements: 9.1, 11.1
+// NextRun reports a registered job's id and next scheduled firing, for
+// Scheduler.ListNextRuns.
+type NextRun struct {
+	ID   string
+	Next time.Time
+}
+
+// Scheduler runs an arbitrary number of cron-scheduled jobs, each in its own
+// goroutine, in place of the old hard-coded daily-report/weekly-backup pair:
+// new recurring work (a weekly digest, a per-user report time, a cache
+// warmer) is a Register call away instead of a new hand-written loop. Each
+// job's last successful run is persisted via runRepo, so a job registered
+// with WithMaxCatchupWindow runs immediately on Start if its schedule was
+// missed while the process was down, the way an asynq-style periodic task
+// scheduler catches up.
 type Scheduler struct {
-	reportSender ReportSender
-	backupSvc    *BackupService
-	reportTime   string // Format: "HH:MM"
-	stopChan     chan struct{}
+	runRepo *repository.SchedulerRunRepository
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
 }
 
-// NewScheduler creates a new Scheduler
-func NewScheduler(reportSender ReportSender, backupSvc *BackupService, reportTime string) *Scheduler {
+// NewScheduler creates a new Scheduler. runRepo persists each job's last run
+// so catch-up survives a restart; pass nil to disable catch-up entirely.
+func NewScheduler(runRepo *repository.SchedulerRunRepository) *Scheduler {
 	return &Scheduler{
-		reportSender: reportSender,
-		backupSvc:    backupSvc,
-		reportTime:   reportTime,
-		stopChan:     make(chan struct{}),
+		runRepo: runRepo,
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Register adds a job under id, scheduled per the 5-field cron expression
+// spec (e.g. "0 8 * * *" for daily at 08:00). Registering an id that's
+// already running stops the old job and replaces it. If the Scheduler has
+// already been started, the new job starts running immediately; otherwise
+// it starts when Start is called.
+func (s *Scheduler) Register(id string, spec string, fn JobFunc, opts ...JobOption) error {
+	schedule, err := cronutil.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %q: invalid cron expression %q: %w", id, spec, err)
+	}
+
+	var o jobOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	j := &job{
+		id:       id,
+		schedule: schedule,
+		fn:       fn,
+		opts:     o,
+		stopChan: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if existing, ok := s.jobs[id]; ok {
+		close(existing.stopChan)
+	}
+	s.jobs[id] = j
+	started := s.started
+	s.mu.Unlock()
+
+	if started {
+		go s.runJob(j)
 	}
+	return nil
 }
 
-// Start starts all scheduled tasks
+// Unregister stops id's job, if any. Unregistering an unknown id is a no-op.
+func (s *Scheduler) Unregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j, ok := s.jobs[id]; ok {
+		close(j.stopChan)
+		delete(s.jobs, id)
+	}
+}
+
+// Start begins running every registered job on its own cron schedule in the
+// background.
 func (s *Scheduler) Start() {
-	go s.runDailyReportScheduler()
-	go s.runWeeklyBackupScheduler()
-	log.Printf("Scheduler started - Daily report at %s, Weekly backup on Sundays at 03:00", s.reportTime)
+	s.mu.Lock()
+	s.started = true
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		go s.runJob(j)
+	}
+	log.Printf("scheduler started with %d job(s)", len(jobs))
 }
 
-// Stop stops all scheduled tasks
+// Stop stops every running job.
 func (s *Scheduler) Stop() {
-	close(s.stopChan)
-}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-// runDailyReportScheduler runs the daily report scheduler
-// Requirements: 9.1
-func (s *Scheduler) runDailyReportScheduler() {
-	for {
-		// Calculate time until next report
-		nextRun := s.calculateNextReportTime()
-		duration := time.Until(nextRun)
+	for _, j := range s.jobs {
+		close(j.stopChan)
+	}
+}
 
-		log.Printf("Next daily report scheduled at %s (in %v)", nextRun.Format("2006-01-02 15:04:05"), duration.Round(time.Minute))
+// ListNextRuns reports every registered job's id and next scheduled firing.
+func (s *Scheduler) ListNextRuns() []NextRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		select {
-		case <-time.After(duration):
-			log.Println("Sending daily report...")
-			if err := s.reportSender.SendDailyReport(); err != nil {
-				log.Printf("Failed to send daily report: %v", err)
-			} else {
-				log.Println("Daily report sent successfully")
-			}
-		case <-s.stopChan:
-			return
-		}
+	now := timeutil.Now()
+	runs := make([]NextRun, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		runs = append(runs, NextRun{ID: j.id, Next: j.next(now)})
 	}
+	return runs
 }
 
-// runWeeklyBackupScheduler runs the weekly backup scheduler
-// Requirements: 11.1
-func (s *Scheduler) runWeeklyBackupScheduler() {
+// runJob first gives j a chance to catch up a missed firing, then loops on
+// its cron schedule until stopped.
+func (s *Scheduler) runJob(j *job) {
+	s.maybeCatchUp(j)
+
 	for {
-		// Calculate time until next Sunday at 03:00
-		nextRun := s.calculateNextBackupTime()
-		duration := time.Until(nextRun)
+		next := j.next(timeutil.Now())
+		delay := time.Until(next)
+		if j.opts.jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(j.opts.jitter)))
+		}
 
-		log.Printf("Next backup scheduled at %s (in %v)", nextRun.Format("2006-01-02 15:04:05"), duration.Round(time.Hour))
+		log.Printf("job %q: next run at %s (in %v)", j.id, next.Format("2006-01-02 15:04:05"), delay.Round(time.Second))
 
 		select {
-		case <-time.After(duration):
-			log.Println("Running weekly backup...")
-			backupPath, err := s.backupSvc.Backup()
-			if err != nil {
-				log.Printf("Failed to create backup: %v", err)
-			} else {
-				log.Printf("Backup created successfully: %s", backupPath)
-			}
-		case <-s.stopChan:
+		case <-time.After(delay):
+			s.fire(j)
+		case <-j.stopChan:
 			return
 		}
 	}
 }
 
+// maybeCatchUp runs j immediately if it had a scheduled firing within
+// j.opts.maxCatchupWindow of now that runRepo shows was never recorded as
+// run - i.e. it was missed while the process was down.
+func (s *Scheduler) maybeCatchUp(j *job) {
+	if j.opts.maxCatchupWindow <= 0 || s.runRepo == nil {
+		return
+	}
 
-// calculateNextReportTime calculates the next time to send the daily report
-func (s *Scheduler) calculateNextReportTime() time.Time {
-	now := time.Now()
-
-	// Parse report time
-	hour, minute := 8, 0 // Default to 08:00
-	if s.reportTime != "" {
-		fmt.Sscanf(s.reportTime, "%d:%d", &hour, &minute)
+	now := timeutil.Now()
+	evalNow := now
+	if j.opts.timezone != nil {
+		evalNow = now.In(j.opts.timezone)
 	}
 
-	// Create today's report time
-	reportTime := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	// Walk the schedule forward from the start of the catch-up window to
+	// find the most recent firing at or before evalNow, if any.
+	var missed time.Time
+	cursor := evalNow.Add(-j.opts.maxCatchupWindow)
+	for {
+		next := j.schedule.Next(cursor)
+		if next.After(evalNow) {
+			break
+		}
+		missed = next
+		cursor = next
+	}
+	if missed.IsZero() {
+		return // nothing was due within the catch-up window
+	}
 
-	// If we've already passed today's report time, schedule for tomorrow
-	if now.After(reportTime) {
-		reportTime = reportTime.Add(24 * time.Hour)
+	lastRunStr, ok, err := s.runRepo.GetLastRun(j.id)
+	if err != nil {
+		log.Printf("job %q: failed to check last run for catch-up: %v", j.id, err)
+		return
+	}
+	if ok {
+		if lastRun, err := time.Parse(time.RFC3339, lastRunStr); err == nil && !lastRun.Before(missed) {
+			return // already ran at or after the missed firing
+		}
 	}
 
-	return reportTime
+	log.Printf("job %q: missed run at %s, catching up now", j.id, missed.Format("2006-01-02 15:04:05"))
+	s.fire(j)
 }
 
-// calculateNextBackupTime calculates the next Sunday at 03:00
-func (s *Scheduler) calculateNextBackupTime() time.Time {
-	now := time.Now()
+// fire runs j.fn and, on success, records the outcome in runRepo.
+func (s *Scheduler) fire(j *job) {
+	if err := j.fn(context.Background()); err != nil {
+		log.Printf("job %q: failed: %v", j.id, err)
+		return
+	}
+	log.Printf("job %q: completed successfully", j.id)
 
-	// Find next Sunday
-	daysUntilSunday := (7 - int(now.Weekday())) % 7
-	if daysUntilSunday == 0 {
-		// Today is Sunday, check if we've passed 03:00
-		backupTime := time.Date(now.Year(), now.Month(), now.Day(), 3, 0, 0, 0, now.Location())
-		if now.After(backupTime) {
-			// Already passed, schedule for next Sunday
-			daysUntilSunday = 7
+	if s.runRepo != nil {
+		if err := s.runRepo.SetLastRun(j.id, timeutil.Now().Format(time.RFC3339)); err != nil {
+			log.Printf("job %q: failed to record run: %v", j.id, err)
 		}
 	}
-
-	nextSunday := now.AddDate(0, 0, daysUntilSunday)
-	return time.Date(nextSunday.Year(), nextSunday.Month(), nextSunday.Day(), 3, 0, 0, 0, now.Location())
 }
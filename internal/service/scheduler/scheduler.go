@@ -0,0 +1,123 @@
+// Package scheduler materializes tasks onto the task board from recurring
+// rules (see repository.ScheduleRule): "every Friday, ORGANIZE shows that
+// have gone quiet for 30 days", "every Monday, UPDATE shows airing again
+// within a week". It's kept separate from the top-level service package
+// (whose unrelated Scheduler runs registered Go job functions on cron
+// schedules) since materializing tasks from rules stored in the database is
+// a different shape of problem from running in-process jobs.
+package scheduler
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/metrics"
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+)
+
+// TaskScheduler runs every enabled repository.ScheduleRule on its own cron
+// schedule, each in its own goroutine, materializing a task for every show
+// the rule's selector matches. Generated tasks reuse
+// TaskRepository.Create's dedup-key-based INSERT OR IGNORE, so a rule that
+// keeps matching the same show every firing only ever creates one pending
+// task for it.
+type TaskScheduler struct {
+	ruleRepo  *repository.ScheduleRuleRepository
+	showRepo  *repository.TVShowRepository
+	taskRepo  *repository.TaskRepository
+	runOnInit bool
+	stopChan  chan struct{}
+}
+
+// NewTaskScheduler creates a new TaskScheduler. If runOnInit is true, every
+// enabled rule fires once immediately when Start is called, in addition to
+// its normal cron schedule — this is a deliberate "catch up once on boot"
+// knob, not an attempt to backfill every firing missed while the process
+// was down; cronutil.Schedule.Next always computes forward from now, so a
+// rule simply resumes its schedule rather than replaying history.
+func NewTaskScheduler(ruleRepo *repository.ScheduleRuleRepository, showRepo *repository.TVShowRepository, taskRepo *repository.TaskRepository, runOnInit bool) *TaskScheduler {
+	return &TaskScheduler{
+		ruleRepo:  ruleRepo,
+		showRepo:  showRepo,
+		taskRepo:  taskRepo,
+		runOnInit: runOnInit,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start loads every enabled rule and begins running each on its own cron
+// schedule in the background.
+func (s *TaskScheduler) Start() error {
+	rules, err := s.ruleRepo.GetEnabled()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		schedule, err := cronutil.Parse(rule.CronExpr)
+		if err != nil {
+			log.Printf("schedule rule %q: skipping, invalid cron expression %q: %v", rule.Name, rule.CronExpr, err)
+			continue
+		}
+
+		go s.runRuleLoop(rule, schedule)
+	}
+	return nil
+}
+
+// Stop stops every running rule loop.
+func (s *TaskScheduler) Stop() {
+	close(s.stopChan)
+}
+
+func (s *TaskScheduler) runRuleLoop(rule repository.ScheduleRule, schedule *cronutil.Schedule) {
+	if s.runOnInit {
+		s.fire(rule)
+	}
+
+	for {
+		next := schedule.Next(timeutil.Now())
+		log.Printf("schedule rule %q: next run at %s", rule.Name, next.Format("2006-01-02 15:04:05"))
+
+		select {
+		case <-time.After(time.Until(next)):
+			s.fire(rule)
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// fire runs rule's selector and creates a task for every matching show.
+func (s *TaskScheduler) fire(rule repository.ScheduleRule) {
+	metrics.ScheduleRulesFiredTotal.WithLabelValues(rule.Name).Inc()
+
+	shows, err := runSelector(rule.Selector, s.showRepo, rule.ThresholdDays)
+	if err != nil {
+		log.Printf("schedule rule %q: selector failed: %v", rule.Name, err)
+		return
+	}
+
+	for _, show := range shows {
+		description := strings.ReplaceAll(rule.DescriptionTemplate, "{{show}}", show.Name)
+		task := &models.Task{
+			TVShowID:    show.ID,
+			TaskType:    rule.TaskType,
+			Description: description,
+			IsCompleted: false,
+		}
+
+		created, err := s.taskRepo.Create(task)
+		if err != nil {
+			log.Printf("schedule rule %q: failed to create task for show %d: %v", rule.Name, show.ID, err)
+			continue
+		}
+		if created {
+			metrics.ScheduleTasksCreatedTotal.WithLabelValues(rule.Name).Inc()
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository"
+)
+
+// Named selectors a ScheduleRule can reference. Rules store these as plain
+// strings (see repository.ScheduleRule.Selector) rather than a raw SQL
+// predicate, so an operator defining a rule can't inject arbitrary SQL
+// through the rules table.
+const (
+	SelectorStaleLastEpisode    = "stale_last_episode"
+	SelectorUpcomingNextEpisode = "upcoming_next_episode"
+)
+
+// selectorFuncs maps each named selector to the TVShowRepository query it
+// runs, parameterized by a rule's ThresholdDays.
+var selectorFuncs = map[string]func(showRepo *repository.TVShowRepository, days int) ([]models.TVShow, error){
+	SelectorStaleLastEpisode: func(showRepo *repository.TVShowRepository, days int) ([]models.TVShow, error) {
+		return showRepo.GetStaleForOrganize(days)
+	},
+	SelectorUpcomingNextEpisode: func(showRepo *repository.TVShowRepository, days int) ([]models.TVShow, error) {
+		return showRepo.GetUpcomingForUpdate(days)
+	},
+}
+
+// runSelector executes the named selector, returning an error for an
+// unrecognized name instead of silently matching nothing.
+func runSelector(name string, showRepo *repository.TVShowRepository, days int) ([]models.TVShow, error) {
+	fn, ok := selectorFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schedule rule selector %q", name)
+	}
+	return fn(showRepo, days)
+}
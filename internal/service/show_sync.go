@@ -2,7 +2,9 @@ package service
 
 import (
 	"fmt"
+	"strconv"
 
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/tmdb"
@@ -14,36 +16,61 @@ type ShowSyncService struct {
 	taskGen     *TaskGenerator
 	showRepo    *repository.TVShowRepository
 	episodeRepo *repository.EpisodeRepository
+	providers   map[string]metadata.Provider
 }
 
-// NewShowSyncService creates a new ShowSyncService.
+// NewShowSyncService creates a new ShowSyncService. providers is keyed by
+// TVShow.MetadataSource and consulted for any show not pinned to TMDB
+// (the "tmdb" source keeps going through cacheSvc so caching and language
+// fallback still apply).
 func NewShowSyncService(
 	cacheSvc *TMDBCacheService,
 	taskGen *TaskGenerator,
 	showRepo *repository.TVShowRepository,
 	episodeRepo *repository.EpisodeRepository,
+	providers map[string]metadata.Provider,
 ) *ShowSyncService {
 	return &ShowSyncService{
 		cacheSvc:    cacheSvc,
 		taskGen:     taskGen,
 		showRepo:    showRepo,
 		episodeRepo: episodeRepo,
+		providers:   providers,
 	}
 }
 
-// RefreshShow fetches TMDB details and syncs local data for a show.
+// RefreshShow fetches the latest details for a show and syncs local data.
+// The tmdb_id column doubles as the show's external ID regardless of which
+// provider actually owns it.
 func (s *ShowSyncService) RefreshShow(tmdbID int) (*tmdb.TVDetails, error) {
-	// Refresh cache with latest TMDB data.
-	details, err := s.cacheSvc.Refresh(tmdbID)
+	// Ensure show exists locally before syncing.
+	existing, err := s.showRepo.GetByTMDBID(tmdbID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load show: %w", err)
 	}
 
-	// Ensure show exists locally before syncing.
-	show, err := s.showRepo.GetByTMDBID(tmdbID)
+	source := metadata.ProviderTMDB
+	if existing != nil && existing.MetadataSource != "" {
+		source = existing.MetadataSource
+	}
+
+	if source != metadata.ProviderTMDB {
+		return s.refreshFromProvider(source, tmdbID, existing)
+	}
+
+	// Refresh cache with latest TMDB data. A conditional GET may come back
+	// 304 Not Modified, or TMDB may be erroring and the service falls back to
+	// serving a stale cached payload - either way there is nothing new to
+	// sync.
+	details, notModified, stale, err := s.cacheSvc.RefreshInLanguage(tmdbID, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to load show: %w", err)
+		return nil, err
 	}
+	if (notModified || stale) && existing != nil {
+		return details, nil
+	}
+
+	show := existing
 	if show == nil {
 		show = &models.TVShow{
 			TMDBID:               details.ID,
@@ -89,6 +116,11 @@ func (s *ShowSyncService) RefreshShow(tmdbID int) (*tmdb.TVDetails, error) {
 		}
 	}
 
+	// Specials (season 0) are optional; not every show has any.
+	if err := s.taskGen.syncSeasonEpisodes(tmdbID, 0); err != nil {
+		fmt.Printf("Warning: failed to sync specials for show %d: %v\n", tmdbID, err)
+	}
+
 	// Generate tasks based on refreshed data.
 	if _, err := s.taskGen.checkEpisodeUpdate(show, details); err != nil {
 		return nil, err
@@ -99,3 +131,73 @@ func (s *ShowSyncService) RefreshShow(tmdbID int) (*tmdb.TVDetails, error) {
 
 	return details, nil
 }
+
+// refreshFromProvider handles the refresh flow for a show pinned to a
+// non-TMDB metadata source. It mirrors RefreshShow's TMDB flow but goes
+// through the registered metadata.Provider instead of cacheSvc, since
+// caching and language fallback are TMDB-specific concerns.
+func (s *ShowSyncService) refreshFromProvider(source string, tmdbID int, existing *models.TVShow) (*tmdb.TVDetails, error) {
+	provider, ok := s.providers[source]
+	if !ok {
+		return nil, fmt.Errorf("show sync: no provider registered for metadata source %q", source)
+	}
+
+	externalID := strconv.Itoa(tmdbID)
+	details, err := provider.GetShowDetails(externalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch show details from provider %q: %w", source, err)
+	}
+
+	show := existing
+	if show == nil {
+		show = &models.TVShow{
+			TMDBID:               tmdbID,
+			Name:                 details.Name,
+			TotalSeasons:         details.NumberOfSeasons,
+			Status:               details.Status,
+			OriginCountry:        details.OriginCountry,
+			ResourceTime:         "待定",
+			ResourceTimeIsManual: false,
+			MetadataSource:       source,
+			IsArchived:           false,
+		}
+		if show.OriginCountry != "" && !show.ResourceTimeIsManual {
+			show.ResourceTime = InferResourceTime(show.OriginCountry)
+		}
+		if err := s.showRepo.Create(show); err != nil {
+			return nil, fmt.Errorf("failed to create show: %w", err)
+		}
+	} else {
+		show.Name = details.Name
+		show.TotalSeasons = details.NumberOfSeasons
+		show.Status = details.Status
+		if details.OriginCountry != "" && show.OriginCountry != details.OriginCountry {
+			show.OriginCountry = details.OriginCountry
+			if !show.ResourceTimeIsManual {
+				show.ResourceTime = InferResourceTime(details.OriginCountry)
+			}
+		}
+		if err := s.showRepo.Update(show); err != nil {
+			return nil, fmt.Errorf("failed to update show: %w", err)
+		}
+	}
+
+	if details.NumberOfSeasons > 0 {
+		if err := s.taskGen.syncSeasonEpisodesFromProvider(provider, externalID, tmdbID, details.NumberOfSeasons); err != nil {
+			return nil, fmt.Errorf("failed to sync episodes: %w", err)
+		}
+	}
+	if err := s.taskGen.syncSeasonEpisodesFromProvider(provider, externalID, tmdbID, 0); err != nil {
+		fmt.Printf("Warning: failed to sync specials for show %d: %v\n", tmdbID, err)
+	}
+
+	tvDetails := metadataToTVDetails(tmdbID, details)
+	if _, err := s.taskGen.checkEpisodeUpdate(show, tvDetails); err != nil {
+		return nil, err
+	}
+	if _, err := s.taskGen.checkShowEnded(show, tvDetails); err != nil {
+		return nil, err
+	}
+
+	return tvDetails, nil
+}
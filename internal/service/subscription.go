@@ -2,33 +2,65 @@ package service
 
 import (
 	"fmt"
-	"strings"
+
+	"github.com/hibiken/asynq"
 
 	"tv-tracker/internal/models"
+	"tv-tracker/internal/release"
 	"tv-tracker/internal/repository"
+	"tv-tracker/internal/tasks"
+	"tv-tracker/internal/timeutil"
 	"tv-tracker/internal/tmdb"
 )
 
 // SubscriptionManager manages TV show subscriptions
 type SubscriptionManager struct {
-	tmdbClient  *tmdb.Client
-	cacheSvc    *TMDBCacheService
-	showRepo    *repository.TVShowRepository
-	episodeRepo *repository.EpisodeRepository
+	tmdbClient     *tmdb.Client
+	cacheSvc       *TMDBCacheService
+	showRepo       *repository.TVShowRepository
+	episodeRepo    *repository.EpisodeRepository
+	subscriberRepo *repository.ShowSubscriberRepository
+
+	taskClient *asynq.Client
+
+	resourceRepo *repository.EpisodeResourceRepository
+	dispatcher   *Dispatcher
+}
+
+// SetTaskClient wires s to the asynq task queue, so Subscribe enqueues its
+// initial episode sync instead of running it inline and blocking the
+// caller on TMDB. Left unset, Subscribe falls back to its old synchronous,
+// warning-only behavior.
+func (s *SubscriptionManager) SetTaskClient(c *asynq.Client) {
+	s.taskClient = c
 }
 
-// NewSubscriptionManager creates a new SubscriptionManager
+// SetResourceDispatch wires s to the repository and Dispatcher AttachResource
+// needs. Left unset, AttachResource fails with an error rather than
+// panicking.
+func (s *SubscriptionManager) SetResourceDispatch(resourceRepo *repository.EpisodeResourceRepository, dispatcher *Dispatcher) {
+	s.resourceRepo = resourceRepo
+	s.dispatcher = dispatcher
+}
+
+// NewSubscriptionManager creates a new SubscriptionManager. subscriberRepo
+// may be nil, in which case GetSubscriptionsFor and UnsubscribeFor (the
+// per-chat scoping used by a multi-user TelegramBot) return an error,
+// SubscribeFor subscribes without recording the chat, and every other
+// method behaves exactly as before.
 func NewSubscriptionManager(
 	tmdbClient *tmdb.Client,
 	cacheSvc *TMDBCacheService,
 	showRepo *repository.TVShowRepository,
 	episodeRepo *repository.EpisodeRepository,
+	subscriberRepo *repository.ShowSubscriberRepository,
 ) *SubscriptionManager {
 	return &SubscriptionManager{
-		tmdbClient:  tmdbClient,
-		cacheSvc:    cacheSvc,
-		showRepo:    showRepo,
-		episodeRepo: episodeRepo,
+		tmdbClient:     tmdbClient,
+		cacheSvc:       cacheSvc,
+		showRepo:       showRepo,
+		episodeRepo:    episodeRepo,
+		subscriberRepo: subscriberRepo,
 	}
 }
 
@@ -72,9 +104,19 @@ func (s *SubscriptionManager) Subscribe(tmdbID int) (*models.TVShow, bool, error
 		return nil, false, fmt.Errorf("failed to create subscription: %w", err)
 	}
 
-	// Sync latest season episodes (manual refresh only when cache is empty)
+	// Sync latest season episodes (manual refresh only when cache is empty).
+	// With a task client configured this is handed off to the queue so the
+	// caller isn't blocked on TMDB; otherwise it falls back to running
+	// inline, same as before.
 	if details.NumberOfSeasons > 0 {
-		if err := s.syncSeasonEpisodes(tmdbID, details.NumberOfSeasons); err != nil {
+		if s.taskClient != nil {
+			task, err := tasks.NewSyncSeasonEpisodesTask(tmdbID, details.NumberOfSeasons)
+			if err != nil {
+				fmt.Printf("Warning: failed to build episode sync task for show %d: %v\n", tmdbID, err)
+			} else if _, err := s.taskClient.Enqueue(task); err != nil {
+				fmt.Printf("Warning: failed to enqueue episode sync for show %d: %v\n", tmdbID, err)
+			}
+		} else if err := s.SyncSeasonEpisodes(tmdbID, details.NumberOfSeasons); err != nil {
 			// Log error but don't fail the subscription
 			fmt.Printf("Warning: failed to sync episodes for show %d: %v\n", tmdbID, err)
 		}
@@ -83,8 +125,97 @@ func (s *SubscriptionManager) Subscribe(tmdbID int) (*models.TVShow, bool, error
 	return show, false, nil
 }
 
-// syncSeasonEpisodes syncs episodes for a specific season
-func (s *SubscriptionManager) syncSeasonEpisodes(tmdbID, seasonNumber int) error {
+// SubscribeFor subscribes chatID to a TV show by TMDB ID, recording the
+// subscriber link on top of Subscribe's normal create-or-return behavior so
+// GetSubscriptionsFor can later scope the library down to what this chat
+// actually asked for.
+func (s *SubscriptionManager) SubscribeFor(chatID int64, tmdbID int) (*models.TVShow, bool, error) {
+	show, alreadyExists, err := s.Subscribe(tmdbID)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.subscriberRepo != nil {
+		if err := s.subscriberRepo.Add(show.ID, chatID); err != nil {
+			return nil, false, fmt.Errorf("failed to record subscriber: %w", err)
+		}
+	}
+	return show, alreadyExists, nil
+}
+
+// GetSubscriptionsFor returns only the shows chatID has subscribed to via
+// SubscribeFor, as opposed to GetAllSubscriptions' whole-library view.
+func (s *SubscriptionManager) GetSubscriptionsFor(chatID int64) ([]models.TVShow, error) {
+	if s.subscriberRepo == nil {
+		return nil, fmt.Errorf("subscription manager has no subscriber repository configured")
+	}
+
+	showIDs, err := s.subscriberRepo.GetShowIDsForChat(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscribed show ids: %w", err)
+	}
+
+	shows := make([]models.TVShow, 0, len(showIDs))
+	for _, id := range showIDs {
+		show, err := s.showRepo.GetByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load show %d: %w", id, err)
+		}
+		if show != nil {
+			shows = append(shows, *show)
+		}
+	}
+	return shows, nil
+}
+
+// GetShow returns the show by its internal ID, e.g. for building the
+// Telegram bot's per-show settings panel.
+func (s *SubscriptionManager) GetShow(showID int64) (*models.TVShow, error) {
+	return s.showRepo.GetByID(showID)
+}
+
+// Search runs a fuzzy title search over the subscribed library, e.g. for
+// the Telegram bot's "/searchsubs <query>" command.
+func (s *SubscriptionManager) Search(query string) ([]models.TVShow, error) {
+	hits, err := s.showRepo.SearchShows(query, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search shows: %w", err)
+	}
+
+	shows := make([]models.TVShow, 0, len(hits))
+	for _, hit := range hits {
+		shows = append(shows, hit.Show)
+	}
+	return shows, nil
+}
+
+// UnsubscribeByTMDBID resolves tmdbID to its internal show and removes
+// chatID's subscriber link, the "/unsubscribe <TMDB ID>" command's
+// equivalent of UnsubscribeFor.
+func (s *SubscriptionManager) UnsubscribeByTMDBID(chatID int64, tmdbID int) error {
+	show, err := s.showRepo.GetByTMDBID(tmdbID)
+	if err != nil {
+		return fmt.Errorf("failed to look up show: %w", err)
+	}
+	if show == nil {
+		return fmt.Errorf("show not found: %d", tmdbID)
+	}
+	return s.UnsubscribeFor(chatID, show.ID)
+}
+
+// UnsubscribeFor removes chatID's subscriber link to showID without
+// affecting the show itself or any other chat's subscription - unlike
+// Unsubscribe, which archives the show globally for everyone.
+func (s *SubscriptionManager) UnsubscribeFor(chatID, showID int64) error {
+	if s.subscriberRepo == nil {
+		return fmt.Errorf("subscription manager has no subscriber repository configured")
+	}
+	return s.subscriberRepo.Remove(showID, chatID)
+}
+
+// SyncSeasonEpisodes syncs episodes for a specific season. Subscribe calls it
+// inline when no task client is set; otherwise the asynq handler that
+// NewTaskQueueMux registers for tasks.TypeSyncSeasonEpisodes calls it.
+func (s *SubscriptionManager) SyncSeasonEpisodes(tmdbID, seasonNumber int) error {
 	episodes, err := s.tmdbClient.GetSeasonEpisodes(tmdbID, seasonNumber)
 	if err != nil {
 		return err
@@ -121,6 +252,14 @@ func (s *SubscriptionManager) GetAllSubscriptions() ([]models.TVShow, error) {
 	return s.showRepo.GetAll()
 }
 
+// GetAllSubscriptionsWithProgress is GetAllSubscriptions with each show's
+// WatchedEpisodes/SkippedEpisodes counts attached, for a library view that
+// wants progress without a separate per-show query, mirroring the queue's
+// existing GetActiveQueue progress display.
+func (s *SubscriptionManager) GetAllSubscriptionsWithProgress() ([]repository.TVShowWithProgress, error) {
+	return s.showRepo.GetAllWithProgress()
+}
+
 // Unsubscribe removes a subscription by show ID
 func (s *SubscriptionManager) Unsubscribe(showID int64) error {
 	// Get the show to find its TMDB ID
@@ -142,25 +281,209 @@ func (s *SubscriptionManager) Unsubscribe(showID int64) error {
 		return fmt.Errorf("failed to archive show: %w", err)
 	}
 
+	// Evict any cached TMDB responses for this show, so a later re-subscribe
+	// doesn't serve a stale snapshot from before it was unsubscribed. Best
+	// effort: a cache eviction failure shouldn't fail the unsubscribe itself.
+	if err := s.tmdbClient.Purge(show.TMDBID); err != nil {
+		fmt.Printf("Warning: failed to purge TMDB cache for show %d: %v\n", show.TMDBID, err)
+	}
+
 	return nil
 }
 
-// InferResourceTime infers the expected resource availability time based on origin country
-// US/UK/CA -> "18:00"
-// CN/TW -> "20:00"
-// JP/KR -> "23:00"
-// Others -> "待定"
-func InferResourceTime(originCountry string) string {
-	country := strings.ToUpper(strings.TrimSpace(originCountry))
-
-	switch country {
-	case "US", "UK", "CA", "GB": // GB is the ISO code for UK
-		return "18:00"
-	case "CN", "TW":
-		return "20:00"
-	case "JP", "KR":
-		return "23:00"
-	default:
-		return "待定"
+// SkipSpecials marks the given episode codes (typically season 00 specials
+// or OVAs) as ignored for the given show, so TaskGenerator stops creating
+// UPDATE tasks for them, without requiring the whole show to be archived. It
+// returns how many codes were newly marked, skipping ones already skipped.
+// The underlying inserts run in a single transaction via BeginTx.
+func (s *SubscriptionManager) SkipSpecials(showID int64, episodeIDs []string) (int, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return 0, fmt.Errorf("show not found: %d", showID)
+	}
+
+	tx, err := s.episodeRepo.BeginTx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	skipped, err := s.episodeRepo.WithTx(tx).SkipSpecials(show.TMDBID, episodeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip specials: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return skipped, nil
+}
+
+// SkipAllSpecials marks every known season-00 episode of a show as skipped,
+// so a caller doesn't need to look up their codes first - the common case
+// of "ignore all the specials" that SkipSpecials otherwise requires an
+// explicit code list for.
+func (s *SubscriptionManager) SkipAllSpecials(showID int64) (int, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return 0, fmt.Errorf("show not found: %d", showID)
+	}
+
+	episodes, err := s.episodeRepo.GetByTMDBID(show.TMDBID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list episodes: %w", err)
+	}
+
+	var codes []string
+	for _, ep := range episodes {
+		if ep.Season == 0 {
+			codes = append(codes, FormatEpisodeID(ep.Season, ep.Episode))
+		}
+	}
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	return s.SkipSpecials(showID, codes)
+}
+
+// MarkSeasonWatched marks every known episode of a season watched for the
+// given show, skipping episodes already marked, and returns how many were
+// newly marked. The underlying inserts run in a single transaction so a
+// binge-catch-up either records in full or not at all.
+func (s *SubscriptionManager) MarkSeasonWatched(showID int64, season int) (int, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return 0, fmt.Errorf("show not found: %d", showID)
+	}
+
+	tx, err := s.episodeRepo.BeginTx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	marked, err := s.episodeRepo.WithTx(tx).MarkSeasonWatched(show.TMDBID, season, timeutil.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark season watched: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return marked, nil
+}
+
+// MarkRangeWatched marks every known episode between fromCode and toCode
+// (inclusive, both "SxxExx") watched for the given show, skipping episodes
+// already marked, and returns how many were newly marked.
+func (s *SubscriptionManager) MarkRangeWatched(showID int64, fromCode, toCode string) (int, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return 0, fmt.Errorf("show not found: %d", showID)
+	}
+
+	tx, err := s.episodeRepo.BeginTx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	marked, err := s.episodeRepo.WithTx(tx).MarkRangeWatched(show.TMDBID, fromCode, toCode, timeutil.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark range watched: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return marked, nil
+}
+
+// SetResourceTime updates showID's ResourceTime and ResourceTimeIsManual
+// atomically, so a manual override is recorded as such and is never
+// silently re-inferred over by ResourceTimeReconciler or by a TMDB refresh
+// running InferResourceTime again.
+func (s *SubscriptionManager) SetResourceTime(showID int64, value string, manual bool) (*models.TVShow, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return nil, nil
+	}
+
+	show.ResourceTime = value
+	show.ResourceTimeIsManual = manual
+	if err := s.showRepo.Update(show); err != nil {
+		return nil, fmt.Errorf("failed to update resource time: %w", err)
+	}
+	return show, nil
+}
+
+// AttachResource parses title into quality metadata, rejects it if it fails
+// the owning show's Dispatcher policy (cam rip, below MinQuality, ...), and
+// upserts it as a candidate resource on the episode. Requires
+// SetResourceDispatch to have been called.
+func (s *SubscriptionManager) AttachResource(episodeID int64, title, url string) (*models.EpisodeResource, error) {
+	if s.resourceRepo == nil || s.dispatcher == nil {
+		return nil, fmt.Errorf("subscription manager has no resource dispatch configured")
+	}
+
+	episode, err := s.episodeRepo.GetByID(episodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode: %w", err)
+	}
+	if episode == nil {
+		return nil, fmt.Errorf("episode not found: %d", episodeID)
+	}
+
+	show, err := s.showRepo.GetByTMDBID(episode.TMDBID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show: %w", err)
+	}
+	if show == nil {
+		return nil, fmt.Errorf("show not found for episode %d", episodeID)
+	}
+
+	info := release.ParseRelease(title)
+	if info.Season != episode.Season || info.Episode != episode.Episode {
+		return nil, fmt.Errorf("candidate %q does not match episode S%02dE%02d", title, episode.Season, episode.Episode)
+	}
+	if !s.dispatcher.Accepts(show, info) {
+		return nil, fmt.Errorf("candidate %q rejected by show policy", title)
+	}
+
+	resource := &models.EpisodeResource{
+		EpisodeID:  episodeID,
+		Title:      title,
+		URL:        url,
+		Resolution: info.Resolution,
+		Source:     info.Source,
+		Codec:      info.Codec,
+		Group:      info.Group,
+		Qiangban:   info.Qiangban,
+	}
+	if err := s.resourceRepo.Upsert(resource); err != nil {
+		return nil, fmt.Errorf("failed to upsert episode resource: %w", err)
 	}
+	return resource, nil
 }
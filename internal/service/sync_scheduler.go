@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"tv-tracker/internal/cronutil"
+	"tv-tracker/internal/jobs"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+)
+
+// SyncJobName identifies the scheduled job in the sync_runs ledger.
+const SyncJobName = "tmdb_refresh_and_sync"
+
+// SyncRunStatus reports the outcome of the most recent run and when the
+// next one is due, for the /api/sync/status endpoint.
+type SyncRunStatus struct {
+	LastRun        *repository.SyncRun
+	NextRun        time.Time
+	ShowsRefreshed int
+	Errors         int
+	Running        bool
+}
+
+// SyncScheduler runs the TMDB refresh job (cacheSvc.Refresh for every active
+// show, rate-limited by the tmdb.Client's own token bucket) followed by
+// TaskGenerator.SyncAll on a cron schedule, replacing the old "sync only
+// happens when someone clicks refresh" flow. Like Scheduler's jobs, it is
+// driven by a parsed cron expression so operators can pick any cadence;
+// unlike them, it records every run in the sync_runs ledger.
+type SyncScheduler struct {
+	cacheSvc *TMDBCacheService
+	showRepo *repository.TVShowRepository
+	taskGen  *TaskGenerator
+	runRepo  *repository.SyncRunRepository
+	schedule *cronutil.Schedule
+	stopChan chan struct{}
+	mu       sync.Mutex
+	running  bool
+
+	jobsClient *jobs.Client
+}
+
+// SetJobsClient wires RunNow to enqueue each show's refresh as a
+// JobTypeTMDBRefresh job instead of calling cacheSvc.Refresh inline, so a
+// transient TMDB failure is retried with backoff rather than just logged
+// and skipped until the next scheduled run. Left unset, RunNow refreshes
+// inline as before.
+func (s *SyncScheduler) SetJobsClient(c *jobs.Client) {
+	s.jobsClient = c
+}
+
+// NewSyncScheduler creates a new SyncScheduler from a 5-field cron
+// expression (e.g. "0 */6 * * *").
+func NewSyncScheduler(cacheSvc *TMDBCacheService, showRepo *repository.TVShowRepository, taskGen *TaskGenerator, runRepo *repository.SyncRunRepository, cronExpr string) (*SyncScheduler, error) {
+	schedule, err := cronutil.Parse(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync cron expression %q: %w", cronExpr, err)
+	}
+	return &SyncScheduler{
+		cacheSvc: cacheSvc,
+		showRepo: showRepo,
+		taskGen:  taskGen,
+		runRepo:  runRepo,
+		schedule: schedule,
+		stopChan: make(chan struct{}),
+	}, nil
+}
+
+// Start begins running the job on its cron schedule in the background.
+func (s *SyncScheduler) Start() {
+	go s.run()
+}
+
+// Stop stops the scheduler.
+func (s *SyncScheduler) Stop() {
+	close(s.stopChan)
+}
+
+func (s *SyncScheduler) run() {
+	for {
+		next := s.schedule.Next(timeutil.Now())
+		duration := time.Until(next)
+		log.Printf("Next TMDB refresh + sync scheduled at %s (in %v)", next.Format("2006-01-02 15:04:05"), duration.Round(time.Minute))
+
+		select {
+		case <-time.After(duration):
+			if _, err := s.RunNow("schedule"); err != nil {
+				log.Printf("Scheduled TMDB refresh + sync failed: %v", err)
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// RunNow performs one refresh-then-sync cycle immediately, regardless of
+// the schedule, and records it in the sync_runs ledger. triggeredBy is
+// "schedule" or "manual" (see the /api/sync/run handler). If SetJobsClient
+// was called, refreshes are handed off to the job queue rather than awaited
+// here, so SyncAll may run against cache entries that are still mid-refresh
+// - acceptable since it just means that show's tasks catch up on the next
+// cycle instead of this one.
+func (s *SyncScheduler) RunNow(triggeredBy string) (*repository.SyncRun, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("a sync run is already in progress")
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	startedAt := timeutil.Now()
+
+	shows, err := s.showRepo.GetAllActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active shows: %w", err)
+	}
+
+	refreshed, refreshErrors := 0, 0
+	for _, show := range shows {
+		if s.jobsClient != nil {
+			_, err := s.jobsClient.Enqueue(JobTypeTMDBRefresh, TMDBRefreshPayload{TMDBID: show.TMDBID},
+				jobs.WithUniqueKey(strconv.Itoa(show.TMDBID)))
+			if err != nil {
+				log.Printf("TMDB refresh enqueue failed for show %d (%s): %v", show.TMDBID, show.Name, err)
+				refreshErrors++
+				continue
+			}
+			refreshed++
+			continue
+		}
+
+		if _, err := s.cacheSvc.Refresh(show.TMDBID); err != nil {
+			log.Printf("TMDB refresh failed for show %d (%s): %v", show.TMDBID, show.Name, err)
+			refreshErrors++
+			continue
+		}
+		refreshed++
+	}
+
+	result, syncErr := s.taskGen.SyncAll()
+	syncErrors := 0
+	if syncErr != nil {
+		log.Printf("SyncAll failed after TMDB refresh: %v", syncErr)
+		syncErrors++
+	} else {
+		syncErrors = result.Errors
+	}
+
+	finishedAt := timeutil.Now()
+	run := repository.SyncRun{
+		JobName:        SyncJobName,
+		StartedAt:      startedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt:     finishedAt.Format("2006-01-02 15:04:05"),
+		ShowsRefreshed: refreshed,
+		Errors:         refreshErrors + syncErrors,
+		TriggeredBy:    triggeredBy,
+	}
+	if err := s.runRepo.Record(run); err != nil {
+		return nil, fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// Status reports the last recorded run and the next scheduled one.
+func (s *SyncScheduler) Status() (SyncRunStatus, error) {
+	last, err := s.runRepo.Latest(SyncJobName)
+	if err != nil {
+		return SyncRunStatus{}, err
+	}
+
+	s.mu.Lock()
+	running := s.running
+	s.mu.Unlock()
+
+	status := SyncRunStatus{
+		LastRun: last,
+		NextRun: s.schedule.Next(timeutil.Now()),
+		Running: running,
+	}
+	if last != nil {
+		status.ShowsRefreshed = last.ShowsRefreshed
+		status.Errors = last.Errors
+	}
+	return status, nil
+}
@@ -1,13 +1,69 @@
 package service
 
 import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/models"
+	"tv-tracker/internal/release"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/timeutil"
 )
 
+// taskTypeWeight biases the dashboard's composite score towards task types
+// that block other work if left pending: an ORGANIZE task keeps a finished
+// show archived from the library, so it outweighs an UPDATE task waiting on
+// a single episode, which in turn outweighs a RESOURCE_FOUND task that's
+// merely informational until someone acts on it.
+var taskTypeWeight = map[models.TaskType]float64{
+	models.TaskTypeOrganize:      20,
+	models.TaskTypeUpdate:        10,
+	models.TaskTypeResourceFound: 5,
+}
+
+// taskScore computes the dashboard sort score for task as of now: explicit
+// Priority, plus the task type's weight, plus an age component that grows
+// faster once a task is overdue than while it's merely waiting. Ties should
+// be rare given the continuous age term, but favor the lower task ID (i.e.
+// older task) for determinism.
+func taskScore(task models.Task, now time.Time) float64 {
+	score := float64(task.Priority) + taskTypeWeight[task.TaskType]
+
+	if task.DueDate != nil {
+		overdueDays := now.Sub(*task.DueDate).Hours() / 24
+		if overdueDays > 0 {
+			score += overdueDays * 5
+		}
+	} else {
+		ageDays := now.Sub(task.CreatedAt).Hours() / 24
+		score += ageDays * 0.5
+	}
+
+	return score
+}
+
+// sortByScore orders tasks by taskScore descending (highest priority first),
+// breaking ties by ID so the ordering is stable across calls.
+func sortByScore(tasks []models.Task) {
+	now := timeutil.Now()
+	sort.SliceStable(tasks, func(i, j int) bool {
+		si, sj := taskScore(tasks[i], now), taskScore(tasks[j], now)
+		if si != sj {
+			return si > sj
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
 // DashboardData contains the data for the task dashboard
 type DashboardData struct {
 	UpdateTasks   []models.Task `json:"update_tasks"`
@@ -16,18 +72,39 @@ type DashboardData struct {
 
 // TaskBoardService handles task board operations
 type TaskBoardService struct {
-	taskRepo *repository.TaskRepository
-	showRepo *repository.TVShowRepository
+	taskRepo   *repository.TaskRepository
+	showRepo   *repository.TVShowRepository
+	dispatcher *Dispatcher
+	auditRepo  *repository.TaskAuditRepository
+	providers  map[string]metadata.Provider
 }
 
-// NewTaskBoardService creates a new TaskBoardService
-func NewTaskBoardService(taskRepo *repository.TaskRepository, showRepo *repository.TVShowRepository) *TaskBoardService {
+// NewTaskBoardService creates a new TaskBoardService. auditRepo backs
+// CompleteMany/PostponeMany/UndoLastAction's undo trail. providers is keyed
+// by TVShow.MetadataSource, the same map ShowSyncService holds, and is
+// consulted by Import's resolveShow to create a show on demand when it's
+// referenced by a provider:externalID pair that isn't in the library yet.
+func NewTaskBoardService(taskRepo *repository.TaskRepository, showRepo *repository.TVShowRepository, dispatcher *Dispatcher, auditRepo *repository.TaskAuditRepository, providers map[string]metadata.Provider) *TaskBoardService {
 	return &TaskBoardService{
-		taskRepo: taskRepo,
-		showRepo: showRepo,
+		taskRepo:   taskRepo,
+		showRepo:   showRepo,
+		dispatcher: dispatcher,
+		auditRepo:  auditRepo,
+		providers:  providers,
 	}
 }
 
+// generateActionID returns a random hex identifier grouping the audit rows
+// produced by one bulk operation, so UndoLastAction can reverse every task
+// a single CompleteMany/PostponeMany call touched together.
+func generateActionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate action id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // GetDashboardData retrieves all pending tasks grouped by type
 // Requirements: 6.4, 7.1, 7.2
 func (s *TaskBoardService) GetDashboardData() (*DashboardData, error) {
@@ -51,6 +128,9 @@ func (s *TaskBoardService) GetDashboardData() (*DashboardData, error) {
 		organizeTasks = []models.Task{}
 	}
 
+	sortByScore(updateTasks)
+	sortByScore(organizeTasks)
+
 	return &DashboardData{
 		UpdateTasks:   updateTasks,
 		OrganizeTasks: organizeTasks,
@@ -101,8 +181,56 @@ func (s *TaskBoardService) CompleteTask(taskID int64) error {
 	return nil
 }
 
+// AttachResourceCandidate parses a candidate resource filename and creates a
+// RESOURCE_FOUND task recording its quality metadata, so the dashboard can
+// show parsed resolution/source/codec and grey out cam rips.
+func (s *TaskBoardService) AttachResourceCandidate(showID int64, filename string) (*release.ReleaseInfo, error) {
+	show, err := s.showRepo.GetByID(showID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load show: %w", err)
+	}
+	if show == nil {
+		return nil, fmt.Errorf("show not found: %d", showID)
+	}
+
+	info := release.ParseRelease(filename)
+
+	if !s.dispatcher.Accepts(show, info) {
+		return info, fmt.Errorf("candidate %q rejected by show policy", filename)
+	}
+
+	description := fmt.Sprintf("候选资源: %s (%s %s %s)", filename, info.Resolution, info.Source, info.Codec)
+	if info.Qiangban {
+		description = fmt.Sprintf("[枪版] 候选资源: %s (%s %s %s)", filename, info.Resolution, info.Source, info.Codec)
+	}
+
+	// EpisodeID doubles as the dedup key's per-filename discriminator here,
+	// so reattaching the same candidate filename is a no-op instead of a
+	// duplicate task, while distinct filenames for the same show coexist.
+	task := &models.Task{
+		TVShowID:    show.ID,
+		TaskType:    models.TaskTypeResourceFound,
+		EpisodeID:   filename,
+		Description: description,
+		IsCompleted: false,
+	}
+
+	if _, err := s.taskRepo.Create(task); err != nil {
+		return nil, fmt.Errorf("failed to create RESOURCE_FOUND task: %w", err)
+	}
+
+	return info, nil
+}
+
 // PostponeTask postpones a task to tomorrow by deleting it and recreating it with tomorrow's date
 func (s *TaskBoardService) PostponeTask(taskID int64) error {
+	return s.Snooze(taskID, 1)
+}
+
+// Snooze postpones a task by days by deleting it and recreating it with a
+// created_at of now+days, generalizing PostponeTask's hardcoded "tomorrow"
+// to an arbitrary interval. A negative days pulls the task earlier.
+func (s *TaskBoardService) Snooze(taskID int64, days int) error {
 	// Get the task first
 	task, err := s.taskRepo.GetByID(taskID)
 	if err != nil {
@@ -122,24 +250,463 @@ func (s *TaskBoardService) PostponeTask(taskID int64) error {
 
 	taskRepo := s.taskRepo.WithTx(tx)
 
-	// Calculate tomorrow's date based on current time
-	tomorrow := timeutil.Now().AddDate(0, 0, 1).Format("2006-01-02 15:04:05")
+	snoozedTo := timeutil.Now().AddDate(0, 0, days).Format("2006-01-02 15:04:05")
 
-	// Create a new task for tomorrow
+	// Delete the original task first so its dedup key is free for the
+	// recreated one below (both share the same show+type+episode key).
+	if err := taskRepo.Delete(taskID); err != nil {
+		return fmt.Errorf("failed to delete original task: %w", err)
+	}
+
+	// Create a new task for the snoozed date
 	newTask := &models.Task{
 		TVShowID:    task.TVShowID,
 		TaskType:    task.TaskType,
+		EpisodeID:   task.EpisodeID,
 		Description: task.Description,
 		IsCompleted: false,
+		Priority:    task.Priority,
+		DueDate:     task.DueDate,
 	}
 
-	if err := taskRepo.CreateWithDate(newTask, tomorrow); err != nil {
-		return fmt.Errorf("failed to create postponed task: %w", err)
+	if err := taskRepo.CreateWithDate(newTask, snoozedTo); err != nil {
+		return fmt.Errorf("failed to create snoozed task: %w", err)
 	}
 
-	// Delete the original task
-	if err := taskRepo.Delete(taskID); err != nil {
-		return fmt.Errorf("failed to delete original task: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetPriority sets a task's dashboard priority score (0-100; not enforced,
+// just the convention the dashboard sort expects).
+func (s *TaskBoardService) SetPriority(taskID int64, score int) error {
+	return s.taskRepo.SetPriority(taskID, score)
+}
+
+// SetDueDate sets or clears (due == nil) a task's due date.
+func (s *TaskBoardService) SetDueDate(taskID int64, due *time.Time) error {
+	return s.taskRepo.SetDueDate(taskID, due)
+}
+
+// BulkResult is one task's outcome within a CompleteMany/PostponeMany call.
+// Error is empty on success, so callers can report partial failures without
+// losing track of which of the requested IDs succeeded.
+type BulkResult struct {
+	TaskID int64  `json:"task_id"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CompleteMany marks every task in taskIDs as completed in a single
+// transaction, archiving each ORGANIZE task's show exactly like
+// CompleteTask. An unknown or already-completed task ID is recorded as a
+// failed BulkResult rather than aborting the whole batch; a database error
+// partway through aborts and rolls back everything, since that indicates a
+// systemic problem rather than a bad input. actionID identifies the batch
+// for a later UndoLastAction call.
+func (s *TaskBoardService) CompleteMany(taskIDs []int64) (actionID string, results []BulkResult, err error) {
+	actionID, err = generateActionID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	tx, err := s.taskRepo.BeginTx()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	taskRepo := s.taskRepo.WithTx(tx)
+	showRepo := s.showRepo.WithTx(tx)
+	auditRepo := s.auditRepo.WithTx(tx)
+
+	for _, taskID := range taskIDs {
+		task, err := taskRepo.GetByID(taskID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get task %d: %w", taskID, err)
+		}
+		if task == nil {
+			results = append(results, BulkResult{TaskID: taskID, Error: "task not found"})
+			continue
+		}
+		if task.IsCompleted {
+			results = append(results, BulkResult{TaskID: taskID, Error: "task already completed"})
+			continue
+		}
+
+		priorTaskJSON, err := json.Marshal(task)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to snapshot task %d: %w", taskID, err)
+		}
+
+		var priorShowArchived *bool
+		if task.TaskType == models.TaskTypeOrganize {
+			show, err := showRepo.GetByID(task.TVShowID)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to get show for task %d: %w", taskID, err)
+			}
+			if show != nil {
+				archived := show.IsArchived
+				priorShowArchived = &archived
+				if err := showRepo.Archive(task.TVShowID); err != nil {
+					return "", nil, fmt.Errorf("failed to archive show for task %d: %w", taskID, err)
+				}
+			}
+		}
+
+		if err := taskRepo.Complete(taskID); err != nil {
+			return "", nil, fmt.Errorf("failed to complete task %d: %w", taskID, err)
+		}
+
+		if err := auditRepo.Record(repository.TaskAudit{
+			ActionID:          actionID,
+			Action:            "complete",
+			TaskID:            taskID,
+			PriorTaskJSON:     string(priorTaskJSON),
+			PriorShowArchived: priorShowArchived,
+		}); err != nil {
+			return "", nil, fmt.Errorf("failed to record audit for task %d: %w", taskID, err)
+		}
+
+		results = append(results, BulkResult{TaskID: taskID})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return actionID, results, nil
+}
+
+// PostponeMany snoozes every task in taskIDs by days in a single
+// transaction, the same delete-and-recreate each Snooze call performs.
+// See CompleteMany for how unknown task IDs and database errors are
+// handled differently.
+func (s *TaskBoardService) PostponeMany(taskIDs []int64, days int) (actionID string, results []BulkResult, err error) {
+	actionID, err = generateActionID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	tx, err := s.taskRepo.BeginTx()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	taskRepo := s.taskRepo.WithTx(tx)
+	auditRepo := s.auditRepo.WithTx(tx)
+
+	snoozedTo := timeutil.Now().AddDate(0, 0, days).Format("2006-01-02 15:04:05")
+
+	for _, taskID := range taskIDs {
+		task, err := taskRepo.GetByID(taskID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get task %d: %w", taskID, err)
+		}
+		if task == nil {
+			results = append(results, BulkResult{TaskID: taskID, Error: "task not found"})
+			continue
+		}
+
+		priorTaskJSON, err := json.Marshal(task)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to snapshot task %d: %w", taskID, err)
+		}
+
+		if err := taskRepo.Delete(taskID); err != nil {
+			return "", nil, fmt.Errorf("failed to delete task %d: %w", taskID, err)
+		}
+
+		newTask := &models.Task{
+			TVShowID:    task.TVShowID,
+			TaskType:    task.TaskType,
+			EpisodeID:   task.EpisodeID,
+			Description: task.Description,
+			IsCompleted: false,
+			Priority:    task.Priority,
+			DueDate:     task.DueDate,
+		}
+		if err := taskRepo.CreateWithDate(newTask, snoozedTo); err != nil {
+			return "", nil, fmt.Errorf("failed to create snoozed task for %d: %w", taskID, err)
+		}
+
+		if err := auditRepo.Record(repository.TaskAudit{
+			ActionID:      actionID,
+			Action:        "postpone",
+			TaskID:        taskID,
+			NewTaskID:     &newTask.ID,
+			PriorTaskJSON: string(priorTaskJSON),
+		}); err != nil {
+			return "", nil, fmt.Errorf("failed to record audit for task %d: %w", taskID, err)
+		}
+
+		results = append(results, BulkResult{TaskID: taskID})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return actionID, results, nil
+}
+
+// exportLine is the text-format line schema for Export/Import: one task per
+// line, pipe-delimited. It's also used internally to normalize a decoded
+// JSON task before it goes through the same resolveShow/Create path a text
+// import does.
+type exportLine struct {
+	TaskType    models.TaskType
+	ShowTitle   string
+	Description string
+	DueDate     string // YYYY-MM-DD, empty if unset
+}
+
+// Export writes every pending task to w in the requested format: "json"
+// (a JSON array of models.Task, the richest representation; Import reads
+// back each task's type, show, description and due date) or "text" (one
+// "TYPE | show_title | description | due_date" line per task, meant to be
+// checked into version control or edited by hand like a todo file).
+func (s *TaskBoardService) Export(w io.Writer, format string) error {
+	tasks, err := s.taskRepo.GetAllPending()
+	if err != nil {
+		return fmt.Errorf("failed to load pending tasks: %w", err)
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+	case "text":
+		for _, task := range tasks {
+			due := ""
+			if task.DueDate != nil {
+				due = task.DueDate.Format("2006-01-02")
+			}
+			if _, err := fmt.Fprintf(w, "%s | %s | %s | %s\n", task.TaskType, task.TVShowName, task.Description, due); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format %q (want \"json\" or \"text\")", format)
+	}
+}
+
+// ImportResult is one line's outcome within an Import call, mirroring
+// BulkResult's success/failure shape so a bad line is reported without
+// aborting the rest of the import.
+type ImportResult struct {
+	ShowTitle string `json:"show_title"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Import reads tasks from r in the given format ("json" or "text") and
+// creates each as a pending task. show_title is resolved to a TVShow via
+// resolveShow, which can create a show on demand for a provider:externalID
+// reference; a title that can't be resolved at all is recorded as a failed
+// ImportResult rather than aborting the batch. Duplicate tasks (same show,
+// type and episode) are silently absorbed by taskRepo.Create's dedup key,
+// the same idempotence CompleteMany/PostponeMany rely on elsewhere.
+func (s *TaskBoardService) Import(r io.Reader, format string) ([]ImportResult, error) {
+	var lines []exportLine
+
+	switch format {
+	case "json":
+		var tasks []models.Task
+		if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+			return nil, fmt.Errorf("failed to decode json import: %w", err)
+		}
+		for _, t := range tasks {
+			due := ""
+			if t.DueDate != nil {
+				due = t.DueDate.Format("2006-01-02")
+			}
+			lines = append(lines, exportLine{TaskType: t.TaskType, ShowTitle: t.TVShowName, Description: t.Description, DueDate: due})
+		}
+	case "text":
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			raw := strings.TrimSpace(scanner.Text())
+			if raw == "" {
+				continue
+			}
+			fields := strings.Split(raw, "|")
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("malformed import line %q: expected TYPE | show_title | description | due_date", raw)
+			}
+			lines = append(lines, exportLine{
+				TaskType:    models.TaskType(strings.TrimSpace(fields[0])),
+				ShowTitle:   strings.TrimSpace(fields[1]),
+				Description: strings.TrimSpace(fields[2]),
+				DueDate:     strings.TrimSpace(fields[3]),
+			})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read text import: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported import format %q (want \"json\" or \"text\")", format)
+	}
+
+	results := make([]ImportResult, 0, len(lines))
+	for _, line := range lines {
+		show, err := s.resolveShow(line.ShowTitle)
+		if err != nil {
+			results = append(results, ImportResult{ShowTitle: line.ShowTitle, Error: err.Error()})
+			continue
+		}
+
+		task := &models.Task{
+			TVShowID:    show.ID,
+			TaskType:    line.TaskType,
+			Description: line.Description,
+			IsCompleted: false,
+		}
+		if line.DueDate != "" {
+			due, err := time.Parse("2006-01-02", line.DueDate)
+			if err != nil {
+				results = append(results, ImportResult{ShowTitle: line.ShowTitle, Error: fmt.Sprintf("invalid due date %q: %v", line.DueDate, err)})
+				continue
+			}
+			task.DueDate = &due
+		}
+
+		if _, err := s.taskRepo.Create(task); err != nil {
+			results = append(results, ImportResult{ShowTitle: line.ShowTitle, Error: err.Error()})
+			continue
+		}
+		results = append(results, ImportResult{ShowTitle: line.ShowTitle})
+	}
+
+	return results, nil
+}
+
+// resolveShow looks up the TVShow an Import line's show_title refers to.
+// A title of the form "provider:externalID" (e.g. "tmdb:1399") pins an
+// exact show, fetching it via the matching metadata.Provider and creating
+// it - mirroring ShowSyncService.refreshFromProvider's minimal show row -
+// if it isn't in the library yet. Any other title is matched
+// case-insensitively against existing shows, falling back to SearchShows'
+// fuzzy ranking if nothing matches exactly.
+func (s *TaskBoardService) resolveShow(title string) (*models.TVShow, error) {
+	if providerID, externalID, ok := strings.Cut(title, ":"); ok {
+		if provider, registered := s.providers[providerID]; registered {
+			tmdbID, err := strconv.Atoi(externalID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid external id %q for provider %q: %w", externalID, providerID, err)
+			}
+
+			show, err := s.showRepo.GetByTMDBID(tmdbID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up show %d: %w", tmdbID, err)
+			}
+			if show != nil {
+				return show, nil
+			}
+
+			details, err := provider.GetShowDetails(externalID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch show from provider %q: %w", providerID, err)
+			}
+
+			show = &models.TVShow{
+				TMDBID:         tmdbID,
+				Name:           details.Name,
+				TotalSeasons:   details.NumberOfSeasons,
+				Status:         details.Status,
+				OriginCountry:  details.OriginCountry,
+				ResourceTime:   "待定",
+				MetadataSource: providerID,
+			}
+			if show.OriginCountry != "" {
+				show.ResourceTime = InferResourceTime(show.OriginCountry)
+			}
+			if err := s.showRepo.Create(show); err != nil {
+				return nil, fmt.Errorf("failed to create show: %w", err)
+			}
+			return show, nil
+		}
+	}
+
+	shows, err := s.showRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shows: %w", err)
+	}
+	for i := range shows {
+		if strings.EqualFold(shows[i].Name, title) {
+			return &shows[i], nil
+		}
+	}
+
+	hits, err := s.showRepo.SearchShows(title, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search shows: %w", err)
+	}
+	if len(hits) > 0 {
+		return &hits[0].Show, nil
+	}
+
+	return nil, fmt.Errorf("no show matching %q", title)
+}
+
+// UndoLastAction reverses every task touched by the CompleteMany or
+// PostponeMany call identified by actionID, replaying the inverse within a
+// single transaction: a completed task goes back to pending (and its
+// show's archive flag is restored), a postponed task's replacement is
+// deleted and the original is restored at its original ID and created_at.
+func (s *TaskBoardService) UndoLastAction(actionID string) error {
+	audits, err := s.auditRepo.GetByActionID(actionID)
+	if err != nil {
+		return fmt.Errorf("failed to load audit trail: %w", err)
+	}
+	if len(audits) == 0 {
+		return fmt.Errorf("no audit record found for action %q", actionID)
+	}
+
+	tx, err := s.taskRepo.BeginTx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	taskRepo := s.taskRepo.WithTx(tx)
+	showRepo := s.showRepo.WithTx(tx)
+
+	for _, audit := range audits {
+		var priorTask models.Task
+		if err := json.Unmarshal([]byte(audit.PriorTaskJSON), &priorTask); err != nil {
+			return fmt.Errorf("failed to decode prior task for audit %d: %w", audit.ID, err)
+		}
+
+		switch audit.Action {
+		case "complete":
+			if err := taskRepo.SetCompleted(audit.TaskID, false); err != nil {
+				return fmt.Errorf("failed to uncomplete task %d: %w", audit.TaskID, err)
+			}
+			if audit.PriorShowArchived != nil {
+				if err := showRepo.SetArchived(priorTask.TVShowID, *audit.PriorShowArchived); err != nil {
+					return fmt.Errorf("failed to restore show archive state for task %d: %w", audit.TaskID, err)
+				}
+			}
+		case "postpone":
+			if audit.NewTaskID != nil {
+				if err := taskRepo.Delete(*audit.NewTaskID); err != nil {
+					return fmt.Errorf("failed to remove snoozed task for %d: %w", audit.TaskID, err)
+				}
+			}
+			if err := taskRepo.Restore(priorTask); err != nil {
+				return fmt.Errorf("failed to restore task %d: %w", audit.TaskID, err)
+			}
+		default:
+			return fmt.Errorf("unknown audit action %q", audit.Action)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -2,12 +2,18 @@ package service
 
 import (
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
+	"tv-tracker/internal/metadata"
+	"tv-tracker/internal/metrics"
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/timeutil"
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/webhook"
 )
 
 // SyncResult contains the results of a sync operation
@@ -17,29 +23,53 @@ type SyncResult struct {
 	Errors        int `json:"errors"`
 }
 
+// EventNotifier fans out per-show events to notification channels gated by
+// severity. Satisfied by *notify.Registry; declared here instead of
+// importing internal/notify directly to avoid an import cycle (notify
+// depends on service for TelegramBot's dependencies).
+type EventNotifier interface {
+	SendEvent(severity models.Severity, tasks []models.Task) map[string]error
+	SendEventTo(severity models.Severity, tasks []models.Task, only []string) map[string]error
+}
+
 // TaskGenerator handles task generation based on show status and episode updates
 type TaskGenerator struct {
-	tmdbClient  *tmdb.Client
-	cacheSvc    *TMDBCacheService
-	showRepo    *repository.TVShowRepository
-	episodeRepo *repository.EpisodeRepository
-	taskRepo    *repository.TaskRepository
+	tmdbClient    *tmdb.Client
+	cacheSvc      *TMDBCacheService
+	showRepo      *repository.TVShowRepository
+	episodeRepo   *repository.EpisodeRepository
+	taskRepo      *repository.TaskRepository
+	providers     map[string]metadata.Provider
+	webhookMgr    *webhook.Manager
+	eventNotifier EventNotifier
+	dispatcher    *Dispatcher
 }
 
-// NewTaskGenerator creates a new TaskGenerator
+// NewTaskGenerator creates a new TaskGenerator. providers is keyed by
+// TVShow.MetadataSource and consulted for any show not pinned to TMDB.
+// webhookMgr and eventNotifier may both be nil, in which case new UPDATE/
+// ORGANIZE tasks simply aren't broadcast anywhere beyond the task board.
 func NewTaskGenerator(
 	tmdbClient *tmdb.Client,
 	cacheSvc *TMDBCacheService,
 	showRepo *repository.TVShowRepository,
 	episodeRepo *repository.EpisodeRepository,
 	taskRepo *repository.TaskRepository,
+	providers map[string]metadata.Provider,
+	webhookMgr *webhook.Manager,
+	eventNotifier EventNotifier,
+	dispatcher *Dispatcher,
 ) *TaskGenerator {
 	return &TaskGenerator{
-		tmdbClient:  tmdbClient,
-		cacheSvc:    cacheSvc,
-		showRepo:    showRepo,
-		episodeRepo: episodeRepo,
-		taskRepo:    taskRepo,
+		tmdbClient:    tmdbClient,
+		cacheSvc:      cacheSvc,
+		showRepo:      showRepo,
+		episodeRepo:   episodeRepo,
+		taskRepo:      taskRepo,
+		providers:     providers,
+		webhookMgr:    webhookMgr,
+		eventNotifier: eventNotifier,
+		dispatcher:    dispatcher,
 	}
 }
 
@@ -49,9 +79,37 @@ func FormatEpisodeID(season, episode int) string {
 	return fmt.Sprintf("S%02dE%02d", season, episode)
 }
 
+// qualityHint builds the bracketed search hint appended to a generated
+// UPDATE_Task description, e.g. "[1080p x265 -CAM]", from a show's release
+// preferences. Returns "" if no preference is set and CAM isn't rejected.
+func qualityHint(show *models.TVShow) string {
+	var parts []string
+	if show.PreferredQuality != "" {
+		parts = append(parts, show.PreferredQuality)
+	}
+	if show.PreferredCodec != "" {
+		parts = append(parts, show.PreferredCodec)
+	}
+	if show.PreferredGroup != "" {
+		parts = append(parts, "-"+show.PreferredGroup)
+	}
+	if show.RejectCam {
+		parts = append(parts, "-CAM")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
 // SyncAll iterates through all non-archived subscriptions, syncs latest season episodes,
 // and generates tasks as needed
 func (t *TaskGenerator) SyncAll() (*SyncResult, error) {
+	start := timeutil.Now()
+	defer func() {
+		metrics.SyncDuration.Observe(time.Since(start).Seconds())
+	}()
+
 	result := &SyncResult{}
 
 	// Get all active (non-archived) shows
@@ -61,10 +119,23 @@ func (t *TaskGenerator) SyncAll() (*SyncResult, error) {
 	}
 
 	for _, show := range shows {
+		source := metadata.ProviderTMDB
+		if show.MetadataSource != "" {
+			source = show.MetadataSource
+		}
+
+		if source != metadata.ProviderTMDB {
+			if err := t.syncShowFromProvider(source, &show, result); err != nil {
+				slog.Warn("sync show via provider failed", "stage", "provider_sync", "show_id", show.ID, "tmdb_id", show.TMDBID, "provider", source, "error", err)
+				result.Errors++
+			}
+			continue
+		}
+
 		// Fetch latest data from cache only.
 		tmdbData, ok, err := t.cacheSvc.GetCached(show.TMDBID)
 		if err != nil {
-			fmt.Printf("Warning: failed to load cached TMDB data for show %d (%s): %v\n", show.TMDBID, show.Name, err)
+			slog.Warn("load cached TMDB data failed", "stage", "load_cache", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
 			result.Errors++
 			continue
 		}
@@ -75,21 +146,29 @@ func (t *TaskGenerator) SyncAll() (*SyncResult, error) {
 
 		// Update local show data
 		if err := t.updateShowData(&show, tmdbData); err != nil {
-			fmt.Printf("Warning: failed to update show data for %s: %v\n", show.Name, err)
+			slog.Warn("update show data failed", "stage", "update_show", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
 			result.Errors++
 		}
 
 		// Sync latest season episodes (manual refresh only)
 		if tmdbData.NumberOfSeasons > 0 {
 			if err := t.syncSeasonEpisodes(show.TMDBID, tmdbData.NumberOfSeasons); err != nil {
-				fmt.Printf("Warning: failed to sync episodes for show %d: %v\n", show.TMDBID, err)
+				slog.Warn("sync episodes failed", "stage", "sync_episodes", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
+			}
+		}
+
+		// Specials (season 0) are optional; not every show has any, and a
+		// show's policy may opt out of them entirely.
+		if !t.dispatcher.SkipsSpecials(&show) {
+			if err := t.syncSeasonEpisodes(show.TMDBID, 0); err != nil {
+				slog.Warn("sync specials failed", "stage", "sync_specials", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
 			}
 		}
 
 		// Check for episode updates and generate UPDATE_Task if needed
 		task, err := t.checkEpisodeUpdate(&show, tmdbData)
 		if err != nil {
-			fmt.Printf("Warning: failed to check episode update for %s: %v\n", show.Name, err)
+			slog.Warn("check episode update failed", "stage", "check_episode_update", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
 			result.Errors++
 		} else if task != nil {
 			result.UpdateTasks++
@@ -98,19 +177,29 @@ func (t *TaskGenerator) SyncAll() (*SyncResult, error) {
 		// Check if show ended and generate ORGANIZE_Task if needed
 		task, err = t.checkShowEnded(&show, tmdbData)
 		if err != nil {
-			fmt.Printf("Warning: failed to check show ended for %s: %v\n", show.Name, err)
+			slog.Warn("check show ended failed", "stage", "check_show_ended", "show_id", show.ID, "tmdb_id", show.TMDBID, "error", err)
 			result.Errors++
 		} else if task != nil {
 			result.OrganizeTasks++
 		}
 	}
 
+	metrics.ActiveShows.Set(float64(len(shows)))
+	if result.Errors > 0 {
+		metrics.SyncRunsTotal.WithLabelValues("error").Inc()
+	} else {
+		metrics.SyncRunsTotal.WithLabelValues("ok").Inc()
+	}
+
 	return result, nil
 }
 
-// syncSeasonEpisodes syncs episodes for a specific season from TMDB
+// syncSeasonEpisodes syncs episodes for a specific season from TMDB. It goes
+// through cacheSvc rather than calling the TMDB client directly, since
+// SyncAll calls this for every active show on every sync and most seasons
+// haven't changed between runs.
 func (t *TaskGenerator) syncSeasonEpisodes(tmdbID, seasonNumber int) error {
-	episodes, err := t.tmdbClient.GetSeasonEpisodes(tmdbID, seasonNumber)
+	episodes, err := t.cacheSvc.GetOrRefreshSeason(tmdbID, seasonNumber)
 	if err != nil {
 		return err
 	}
@@ -132,12 +221,103 @@ func (t *TaskGenerator) syncSeasonEpisodes(tmdbID, seasonNumber int) error {
 	return nil
 }
 
+// syncSeasonEpisodesFromProvider is the provider-agnostic counterpart to
+// syncSeasonEpisodes, used for shows pinned to a non-TMDB metadata source.
+func (t *TaskGenerator) syncSeasonEpisodesFromProvider(provider metadata.Provider, externalID string, tmdbID, seasonNumber int) error {
+	episodes, err := provider.GetSeasonEpisodes(externalID, seasonNumber)
+	if err != nil {
+		return err
+	}
+
+	for _, ep := range episodes {
+		episode := &models.Episode{
+			TMDBID:   tmdbID,
+			Season:   ep.Season,
+			Episode:  ep.Episode,
+			Title:    ep.Name,
+			Overview: ep.Overview,
+			AirDate:  ep.AirDate,
+		}
+		if err := t.episodeRepo.Upsert(episode); err != nil {
+			return fmt.Errorf("failed to upsert episode %s: %w", FormatEpisodeID(ep.Season, ep.Episode), err)
+		}
+	}
+
+	return nil
+}
+
+// syncShowFromProvider runs the same update/episode-sync/task-generation
+// flow as the main SyncAll loop, but for a show pinned to a registered
+// non-TMDB metadata source.
+func (t *TaskGenerator) syncShowFromProvider(source string, show *models.TVShow, result *SyncResult) error {
+	provider, ok := t.providers[source]
+	if !ok {
+		return fmt.Errorf("no provider registered for metadata source %q", source)
+	}
+
+	externalID := strconv.Itoa(show.TMDBID)
+	details, err := provider.GetShowDetails(externalID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch show details: %w", err)
+	}
+
+	show.Name = details.Name
+	show.TotalSeasons = details.NumberOfSeasons
+	show.Status = details.Status
+	if details.OriginCountry != "" && show.OriginCountry != details.OriginCountry {
+		show.OriginCountry = details.OriginCountry
+		if !show.ResourceTimeIsManual {
+			show.ResourceTime = InferResourceTime(details.OriginCountry)
+		}
+	}
+	if err := t.showRepo.Update(show); err != nil {
+		return fmt.Errorf("failed to update show: %w", err)
+	}
+
+	if details.NumberOfSeasons > 0 {
+		if err := t.syncSeasonEpisodesFromProvider(provider, externalID, show.TMDBID, details.NumberOfSeasons); err != nil {
+			return fmt.Errorf("failed to sync episodes: %w", err)
+		}
+	}
+	if !t.dispatcher.SkipsSpecials(show) {
+		if err := t.syncSeasonEpisodesFromProvider(provider, externalID, show.TMDBID, 0); err != nil {
+			fmt.Printf("Warning: failed to sync specials for show %d: %v\n", show.TMDBID, err)
+		}
+	}
+
+	tvDetails := metadataToTVDetails(show.TMDBID, details)
+
+	task, err := t.checkEpisodeUpdate(show, tvDetails)
+	if err != nil {
+		return fmt.Errorf("failed to check episode update: %w", err)
+	} else if task != nil {
+		result.UpdateTasks++
+	}
+
+	task, err = t.checkShowEnded(show, tvDetails)
+	if err != nil {
+		return fmt.Errorf("failed to check show ended: %w", err)
+	} else if task != nil {
+		result.OrganizeTasks++
+	}
+
+	return nil
+}
+
 // updateShowData updates local show data with TMDB data
 func (t *TaskGenerator) updateShowData(show *models.TVShow, tmdbData *tmdb.TVDetails) error {
 	show.Name = tmdbData.Name
+	show.OriginalName = tmdbData.OriginalName
+	show.Overview = tmdbData.Overview
 	show.TotalSeasons = tmdbData.NumberOfSeasons
 	show.Status = tmdbData.Status
 
+	genreNames := make([]string, 0, len(tmdbData.Genres))
+	for _, g := range tmdbData.Genres {
+		genreNames = append(genreNames, g.Name)
+	}
+	show.Genres = strings.Join(genreNames, ",")
+
 	// Update origin country if available
 	if len(tmdbData.OriginCountry) > 0 {
 		originCountry := tmdbData.OriginCountry[0]
@@ -207,28 +387,52 @@ func (t *TaskGenerator) createUpdateTaskIfNeeded(show *models.TVShow, episode *t
 	// Format episode ID
 	episodeID := FormatEpisodeID(episode.SeasonNumber, episode.EpisodeNumber)
 
-	// Check if task already exists for this episode
-	existingTask, err := t.taskRepo.GetByShowAndEpisode(show.ID, episodeID)
+	skipped, err := t.episodeRepo.IsSkipped(show.TMDBID, episodeID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing task: %w", err)
+		return nil, fmt.Errorf("failed to check skip status: %w", err)
 	}
-	if existingTask != nil {
-		// Task already exists, don't create duplicate
+	if skipped {
 		return nil, nil
 	}
 
-	// Create UPDATE_Task. Use stable prefix "SxxExx|" for exact matching.
-	description := fmt.Sprintf("%s|新剧集更新: %s - %s", episodeID, episodeID, episode.Name)
+	// Create UPDATE_Task. EpisodeID is the structured dedup key field; Create
+	// is idempotent on (TaskType, TVShowID, EpisodeID), so no separate
+	// existence check is needed to avoid a duplicate.
+	description := fmt.Sprintf("新剧集更新: %s - %s", episodeID, episode.Name)
+	if hint := qualityHint(show); hint != "" {
+		description += " " + hint
+	}
 	task := &models.Task{
 		TVShowID:    show.ID,
 		TaskType:    models.TaskTypeUpdate,
+		EpisodeID:   episodeID,
 		Description: description,
 		IsCompleted: false,
 	}
 
-	if err := t.taskRepo.Create(task); err != nil {
+	created, err := t.taskRepo.Create(task)
+	if err != nil {
 		return nil, fmt.Errorf("failed to create UPDATE_Task: %w", err)
 	}
+	if !created {
+		// Task already exists for this episode; nothing new to notify.
+		return nil, nil
+	}
+	metrics.TasksCreatedTotal.WithLabelValues(string(task.TaskType)).Inc()
+
+	if t.webhookMgr != nil {
+		t.webhookMgr.NotifyNewEpisode(webhook.Event{
+			TMDBID:       show.TMDBID,
+			ShowName:     show.Name,
+			Season:       episode.SeasonNumber,
+			Episode:      episode.EpisodeNumber,
+			ResourceTime: show.ResourceTime,
+		})
+	}
+
+	if t.eventNotifier != nil {
+		t.eventNotifier.SendEventTo(models.SeverityNewEpisode, []models.Task{*task}, t.dispatcher.Channels(show))
+	}
 
 	return task, nil
 }
@@ -241,28 +445,28 @@ func (t *TaskGenerator) checkShowEnded(show *models.TVShow, tmdbData *tmdb.TVDet
 		return nil, nil
 	}
 
-	// Check if ORGANIZE_Task already exists
-	exists, err := t.taskRepo.ExistsOrganizeTask(show.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check existing ORGANIZE_Task: %w", err)
-	}
-	if exists {
-		// Task already exists, don't create duplicate
-		return nil, nil
-	}
-
-	// Create ORGANIZE_Task
-	description := fmt.Sprintf("剧集已完结，请整理归档本地文件")
+	// Create ORGANIZE_Task. ORGANIZE tasks have no EpisodeID, so Create's
+	// dedup key is just (TaskType, TVShowID) — idempotent per show, same as
+	// the old ExistsOrganizeTask pre-check used to enforce by hand.
 	task := &models.Task{
 		TVShowID:    show.ID,
 		TaskType:    models.TaskTypeOrganize,
-		Description: description,
+		Description: "剧集已完结，请整理归档本地文件",
 		IsCompleted: false,
 	}
 
-	if err := t.taskRepo.Create(task); err != nil {
+	created, err := t.taskRepo.Create(task)
+	if err != nil {
 		return nil, fmt.Errorf("failed to create ORGANIZE_Task: %w", err)
 	}
+	if !created {
+		return nil, nil
+	}
+	metrics.TasksCreatedTotal.WithLabelValues(string(task.TaskType)).Inc()
+
+	if t.eventNotifier != nil {
+		t.eventNotifier.SendEventTo(models.SeverityShowEnded, []models.Task{*task}, t.dispatcher.Channels(show))
+	}
 
 	return task, nil
 }
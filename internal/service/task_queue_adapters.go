@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"tv-tracker/internal/tasks"
+)
+
+// NewTaskQueueMux registers an asynq.HandlerFunc for each tasks.Type*
+// constant against the given services, ready to hand to asynq.Server.Run.
+// It's the asynq counterpart to jobs_adapters.go's NewTMDBRefreshHandler and
+// NewDailyReportHandler, just for the Redis-backed queue instead of the
+// sqlite-backed one: both end up calling the same TMDBCacheService, so a
+// refresh triggered via either queue shares the same underlying cache entry
+// and TMDB never gets double-hit for the same show.
+func NewTaskQueueMux(subMgr *SubscriptionManager, cacheSvc *TMDBCacheService, taskGen *TaskGenerator) *asynq.ServeMux {
+	mux := asynq.NewServeMux()
+
+	mux.HandleFunc(tasks.TypeSyncSeasonEpisodes, func(ctx context.Context, t *asynq.Task) error {
+		var p tasks.SyncSeasonEpisodesPayload
+		if err := json.Unmarshal(t.Payload(), &p); err != nil {
+			return fmt.Errorf("%s: %v: %w", tasks.TypeSyncSeasonEpisodes, err, asynq.SkipRetry)
+		}
+		return subMgr.SyncSeasonEpisodes(p.TMDBID, p.Season)
+	})
+
+	mux.HandleFunc(tasks.TypeRefreshShow, func(ctx context.Context, t *asynq.Task) error {
+		var p tasks.RefreshShowPayload
+		if err := json.Unmarshal(t.Payload(), &p); err != nil {
+			return fmt.Errorf("%s: %v: %w", tasks.TypeRefreshShow, err, asynq.SkipRetry)
+		}
+		_, err := cacheSvc.Refresh(p.TMDBID)
+		return err
+	})
+
+	mux.HandleFunc(tasks.TypeSyncAllSubscriptions, func(ctx context.Context, t *asynq.Task) error {
+		_, err := taskGen.SyncAll()
+		return err
+	})
+
+	return mux
+}
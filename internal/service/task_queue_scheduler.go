@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/tasks"
+	"tv-tracker/internal/timeutil"
+)
+
+// TaskQueueScheduler enqueues a TypeRefreshShow task for every subscribed
+// show as its ResourceTime ("HH:MM", the time its region typically makes an
+// episode available) comes due, the per-show counterpart to SyncScheduler's
+// single shared cron cadence - a US show's 18:00 and a JP show's 23:00 have
+// nothing to do with each other, so refreshing them all on one cron
+// expression means most shows get checked hours before or after they
+// actually update.
+type TaskQueueScheduler struct {
+	client   *asynq.Client
+	showRepo *repository.TVShowRepository
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewTaskQueueScheduler creates a TaskQueueScheduler that polls showRepo
+// every interval (typically one minute) for shows whose ResourceTime
+// matches the current wall-clock minute.
+func NewTaskQueueScheduler(client *asynq.Client, showRepo *repository.TVShowRepository, interval time.Duration) *TaskQueueScheduler {
+	return &TaskQueueScheduler{
+		client:   client,
+		showRepo: showRepo,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background.
+func (s *TaskQueueScheduler) Start() {
+	go s.run()
+}
+
+// Stop stops polling.
+func (s *TaskQueueScheduler) Stop() {
+	close(s.stopChan)
+}
+
+func (s *TaskQueueScheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.enqueueDue(); err != nil {
+				log.Printf("task queue scheduler: failed to enqueue due refreshes: %v", err)
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// enqueueDue enqueues a TypeRefreshShow task for every active show whose
+// ResourceTime matches the current minute. The task ID is derived from the
+// show and the current minute so a slow tick or a restart landing on the
+// same minute doesn't double-enqueue the same show.
+func (s *TaskQueueScheduler) enqueueDue() error {
+	shows, err := s.showRepo.GetAllActive()
+	if err != nil {
+		return fmt.Errorf("failed to get active shows: %w", err)
+	}
+
+	now := timeutil.Now().Format("15:04")
+	for _, show := range shows {
+		if show.ResourceTime != now {
+			continue
+		}
+
+		task, err := tasks.NewRefreshShowTask(show.TMDBID)
+		if err != nil {
+			log.Printf("task queue scheduler: failed to build refresh task for show %d: %v", show.TMDBID, err)
+			continue
+		}
+
+		taskID := "refresh-show-" + strconv.Itoa(show.TMDBID) + "-" + now
+		if _, err := s.client.Enqueue(task, asynq.TaskID(taskID)); err != nil {
+			log.Printf("task queue scheduler: failed to enqueue refresh for show %d (%s): %v", show.TMDBID, show.Name, err)
+		}
+	}
+	return nil
+}
@@ -1,77 +1,451 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"tv-tracker/internal/events"
+	"tv-tracker/internal/metadata"
+	"tv-tracker/internal/metrics"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/timeutil"
 	"tv-tracker/internal/tmdb"
 )
 
-const tmdbCacheLanguage = "zh-CN"
+// tmdbCacheLanguage is the fallback default when no preferred language is configured.
+const tmdbCacheLanguage = tmdb.DefaultLanguage
+
+const (
+	// ttlActiveDetails gates re-fetching TV details for a show still airing
+	// when TMDB gave no ETag to revalidate against, since next_episode_to_air
+	// needs to stay fresh.
+	ttlActiveDetails = 1 * time.Hour
+	// ttlEndedDetails gates re-fetching TV details for an ended/canceled
+	// show, whose metadata rarely changes once final.
+	ttlEndedDetails = 7 * 24 * time.Hour
+	// ttlSeasonEpisodes gates re-fetching a season's episode list when TMDB
+	// gave no ETag, used by the periodic sync loop.
+	ttlSeasonEpisodes = 6 * time.Hour
+	// ttlNegativeResult gates re-querying a TMDB ID that previously came
+	// back 404, much shorter than a positive entry's TTL since the only
+	// thing it's protecting against is hammering TMDB for an ID that's
+	// unlikely to ever resolve.
+	ttlNegativeResult = 1 * time.Hour
+
+	cacheTimestampLayout = "2006-01-02 15:04:05"
+)
 
-// TMDBCacheService provides manual-refresh caching for TMDB TV details.
+// TMDBCacheService provides manual-refresh caching for TMDB TV details and
+// season episode lists. Entries are cached per language so a show can carry
+// both a user's preferred language and any fallback languages at once.
+// Refreshes send If-None-Match when TMDB previously returned an ETag, which
+// is cheap (a 304 response) and always safe to do; when TMDB gave no ETag,
+// refreshes instead wait out a TTL before re-fetching, since there would be
+// no way to tell whether the round trip was wasted.
 type TMDBCacheService struct {
-	client *tmdb.Client
-	repo   *repository.TMDBCacheRepository
+	client            *tmdb.Client
+	repo              *repository.MetadataCacheRepository
+	preferredLanguage string
+	fallbackLanguages []string
+
+	// refreshGroup coalesces concurrent RefreshInLanguage calls for the same
+	// (tmdbID, language) into a single upstream request, so e.g. several
+	// dashboard requests racing a cold cache don't each fire off their own
+	// TMDB call.
+	refreshGroup singleflight.Group
+
+	router *events.Router
 }
 
-// NewTMDBCacheService creates a new TMDBCacheService.
-func NewTMDBCacheService(client *tmdb.Client, repo *repository.TMDBCacheRepository) *TMDBCacheService {
+// NewTMDBCacheService creates a new TMDBCacheService using the default language.
+func NewTMDBCacheService(client *tmdb.Client, repo *repository.MetadataCacheRepository) *TMDBCacheService {
 	return &TMDBCacheService{
-		client: client,
-		repo:   repo,
+		client:            client,
+		repo:              repo,
+		preferredLanguage: tmdbCacheLanguage,
 	}
 }
 
-// GetCached returns cached details for a TMDB ID.
+// SetLanguagePreference sets the preferred TMDB language and an ordered list
+// of fallback languages to try when a show lacks a translation in the
+// preferred one (e.g. shows without a Chinese translation falling through to English).
+func (s *TMDBCacheService) SetLanguagePreference(preferred string, fallbacks []string) {
+	if preferred == "" {
+		preferred = tmdbCacheLanguage
+	}
+	s.preferredLanguage = preferred
+	s.fallbackLanguages = fallbacks
+}
+
+// SetRouter configures an events.Router to alert on events.KindTMDBRateLimited
+// when TMDB responds 429/5xx. Left nil, this is only tracked via
+// metrics.TMDBRefreshErrorsTotal.
+func (s *TMDBCacheService) SetRouter(router *events.Router) {
+	s.router = router
+}
+
+// notifyRateLimited alerts the configured router, if any, that TMDB is
+// throttling or unhealthy.
+func (s *TMDBCacheService) notifyRateLimited(apiErr *tmdb.APIError) {
+	if s.router == nil {
+		return
+	}
+	s.router.Route(context.Background(), events.Notification{
+		Kind:  events.KindTMDBRateLimited,
+		Title: "TMDB 请求受限",
+		Body:  apiErr.Error(),
+		Data:  map[string]any{"status_code": apiErr.StatusCode},
+	})
+}
+
+// GetCached returns cached details for a TMDB ID in the service's preferred language.
 func (s *TMDBCacheService) GetCached(tmdbID int) (*tmdb.TVDetails, bool, error) {
-	payload, ok, err := s.repo.Get(tmdbID)
+	return s.GetCachedInLanguage(tmdbID, s.preferredLanguage)
+}
+
+// GetCachedInLanguage returns cached details for a TMDB ID in a specific language.
+func (s *TMDBCacheService) GetCachedInLanguage(tmdbID int, language string) (*tmdb.TVDetails, bool, error) {
+	entry, ok, err := s.getCacheEntry(tmdbID, language)
 	if err != nil || !ok {
+		if err == nil {
+			metrics.TMDBCacheMissesTotal.Inc()
+		}
 		return nil, ok, err
 	}
+	metrics.TMDBCacheHitsTotal.Inc()
 
 	var details tmdb.TVDetails
-	if err := json.Unmarshal([]byte(payload), &details); err != nil {
+	if err := json.Unmarshal([]byte(entry.PayloadJSON), &details); err != nil {
 		return nil, false, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
 	}
 	return &details, true, nil
 }
 
-// Refresh fetches TMDB details and updates the cache.
+// getCacheEntry returns the raw cache entry (payload and ETag) for a TMDB ID
+// in a specific language.
+func (s *TMDBCacheService) getCacheEntry(tmdbID int, language string) (*repository.CacheEntry, bool, error) {
+	if language == "" {
+		language = s.preferredLanguage
+	}
+	return s.repo.Get(metadata.ProviderTMDB, strconv.Itoa(tmdbID), language, repository.ShowDetailsSeason)
+}
+
+// Refresh fetches TMDB details in the service's preferred language and updates the cache.
 func (s *TMDBCacheService) Refresh(tmdbID int) (*tmdb.TVDetails, error) {
-	details, err := s.client.GetTVDetails(tmdbID)
+	details, _, _, err := s.RefreshInLanguage(tmdbID, s.preferredLanguage)
+	return details, err
+}
+
+// refreshResult is what refreshGroup coalesces concurrent callers onto, so
+// every waiter gets the one call's (details, notModified, stale) together
+// instead of just its error.
+type refreshResult struct {
+	details     *tmdb.TVDetails
+	notModified bool
+	stale       bool
+}
+
+// RefreshInLanguage sends a conditional (If-None-Match) request for TMDB
+// details in a specific language. When TMDB responds 304 Not Modified, it
+// bumps fetched_at without rewriting the cached payload and returns
+// notModified=true with the previously cached details, so callers can skip
+// any downstream work that depends on the payload having actually changed.
+// Concurrent calls for the same (tmdbID, language) are coalesced onto a
+// single upstream request via refreshGroup, so a cold cache doesn't turn
+// into a thundering herd against TMDB.
+func (s *TMDBCacheService) RefreshInLanguage(tmdbID int, language string) (details *tmdb.TVDetails, notModified bool, stale bool, err error) {
+	if language == "" {
+		language = s.preferredLanguage
+	}
+
+	key := fmt.Sprintf("%d:%s", tmdbID, language)
+	v, err, _ := s.refreshGroup.Do(key, func() (any, error) {
+		return s.doRefresh(tmdbID, language)
+	})
+	if err != nil {
+		return nil, false, false, err
+	}
+	result := v.(refreshResult)
+	return result.details, result.notModified, result.stale, nil
+}
+
+// doRefresh is RefreshInLanguage's body, run by at most one goroutine at a
+// time per (tmdbID, language) via refreshGroup.
+func (s *TMDBCacheService) doRefresh(tmdbID int, language string) (refreshResult, error) {
+	externalID := strconv.Itoa(tmdbID)
+
+	if _, status, err := s.repo.GetWithMeta(metadata.ProviderTMDB, externalID, language, repository.ShowDetailsSeason, 0, 0); err != nil {
+		return refreshResult{}, err
+	} else if status == repository.CacheNegative {
+		return refreshResult{}, &tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "cached: TMDB id not found"}
+	}
+
+	entry, hasCached, err := s.repo.Get(metadata.ProviderTMDB, externalID, language, repository.ShowDetailsSeason)
+	if err != nil {
+		return refreshResult{}, err
+	}
+	etag, lastModified := "", ""
+	if hasCached {
+		etag = entry.ETag
+		lastModified = entry.LastModified
+
+		if etag == "" && lastModified == "" && !cacheExpired(entry.FetchedAt, ttlForDetails(entry)) {
+			var cached tmdb.TVDetails
+			if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+				return refreshResult{}, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+			}
+			return refreshResult{details: &cached, notModified: true}, nil
+		}
+	}
+
+	fetched, newETag, newLastModified, notModified, err := s.client.GetTVDetailsConditionalWithLastModified(tmdbID, language, etag, lastModified)
+	if err != nil {
+		metrics.TMDBRefreshErrorsTotal.Inc()
+
+		var apiErr *tmdb.APIError
+		if errors.As(err, &apiErr) {
+			switch {
+			case apiErr.StatusCode == http.StatusNotFound:
+				fetchedAt := timeutil.Now().Format(cacheTimestampLayout)
+				if uerr := s.repo.UpsertNegative(metadata.ProviderTMDB, externalID, language, repository.ShowDetailsSeason, fetchedAt, ttlNegativeResult); uerr != nil {
+					return refreshResult{}, uerr
+				}
+			case apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500:
+				// TMDB is throttling or unhealthy; serve the last good
+				// payload rather than surfacing the error, and track the
+				// failure so an operator can see a provider going bad.
+				s.notifyRateLimited(apiErr)
+				if hasCached {
+					_ = s.repo.IncrementErrorCount(metadata.ProviderTMDB, externalID, language, repository.ShowDetailsSeason)
+					var cached tmdb.TVDetails
+					if jerr := json.Unmarshal([]byte(entry.PayloadJSON), &cached); jerr == nil {
+						return refreshResult{details: &cached, stale: true}, nil
+					}
+				}
+			}
+		}
+		return refreshResult{}, err
+	}
+
+	fetchedAt := timeutil.Now().Format(cacheTimestampLayout)
+
+	if notModified {
+		metrics.TMDBCacheConditional304Total.Inc()
+		if err := s.repo.TouchFetchedAt(metadata.ProviderTMDB, externalID, language, repository.ShowDetailsSeason, fetchedAt); err != nil {
+			return refreshResult{}, err
+		}
+		var cached tmdb.TVDetails
+		if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+			return refreshResult{}, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+		}
+		return refreshResult{details: &cached, notModified: true}, nil
+	}
+
+	payload, err := json.Marshal(fetched)
 	if err != nil {
+		return refreshResult{}, fmt.Errorf("failed to encode TMDB payload: %w", err)
+	}
+
+	if err := s.repo.Upsert(metadata.ProviderTMDB, externalID, string(payload), newETag, newLastModified, fetchedAt, language, repository.ShowDetailsSeason); err != nil {
+		return refreshResult{}, err
+	}
+
+	return refreshResult{details: fetched}, nil
+}
+
+// GetOrRefresh returns cached data in the preferred language, transparently
+// falling through to the configured fallback languages when a language has
+// no translated name. A fresh or stale-but-within-ttlEndedDetails entry is
+// returned immediately without blocking the caller - a stale one also kicks
+// off a background refresh - while a missing or long-expired entry blocks
+// for a synchronous one, since there's nothing safe to serve yet. A cached
+// negative result (the ID previously came back 404) skips that language
+// without hitting TMDB again, and is an error for the last language tried.
+func (s *TMDBCacheService) GetOrRefresh(tmdbID int) (*tmdb.TVDetails, bool, error) {
+	languages := s.languagesToTry()
+	for i, language := range languages {
+		isLast := i == len(languages)-1
+
+		entry, status, err := s.repo.GetWithMeta(metadata.ProviderTMDB, strconv.Itoa(tmdbID), language, repository.ShowDetailsSeason, ttlActiveDetails, ttlEndedDetails)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch status {
+		case repository.CacheFresh, repository.CacheStale:
+			var cached tmdb.TVDetails
+			if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+				return nil, false, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+			}
+			if !hasLocalizedName(&cached) && !isLast {
+				continue // no translation in this language; try the next one
+			}
+			if status == repository.CacheStale {
+				go func(tmdbID int, language string) {
+					if _, _, _, err := s.RefreshInLanguage(tmdbID, language); err != nil {
+						metrics.TMDBRefreshErrorsTotal.Inc()
+					}
+				}(tmdbID, language)
+			}
+			return &cached, true, nil
+		case repository.CacheNegative:
+			if isLast {
+				return nil, false, fmt.Errorf("no TMDB data available for id %d in any configured language", tmdbID)
+			}
+			continue
+		default: // CacheExpired or CacheMissing: nothing safe to serve yet
+			refreshed, _, _, err := s.RefreshInLanguage(tmdbID, language)
+			if err != nil {
+				return nil, false, err
+			}
+			if hasLocalizedName(refreshed) || isLast {
+				return refreshed, false, nil
+			}
+		}
+	}
+	return nil, false, fmt.Errorf("no TMDB data available for id %d in any configured language", tmdbID)
+}
+
+// GetStaleWhileRevalidate returns cached details for a TMDB ID in the
+// service's preferred language, classified against freshTTL/staleTTL. A
+// Fresh or Stale entry is returned immediately (a Stale one also kicks off a
+// background RefreshInLanguage so the next call sees current data); an
+// Expired or Missing entry blocks for a synchronous refresh instead, since
+// there is nothing safe to serve yet.
+func (s *TMDBCacheService) GetStaleWhileRevalidate(tmdbID int, freshTTL, staleTTL time.Duration) (*tmdb.TVDetails, repository.CacheStatus, error) {
+	entry, status, err := s.repo.GetWithMeta(metadata.ProviderTMDB, strconv.Itoa(tmdbID), s.preferredLanguage, repository.ShowDetailsSeason, freshTTL, staleTTL)
+	if err != nil {
+		return nil, repository.CacheMissing, err
+	}
+
+	switch status {
+	case repository.CacheFresh, repository.CacheStale:
+		var cached tmdb.TVDetails
+		if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+			return nil, status, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+		}
+		if status == repository.CacheStale {
+			go func() {
+				if _, _, _, err := s.RefreshInLanguage(tmdbID, s.preferredLanguage); err != nil {
+					metrics.TMDBRefreshErrorsTotal.Inc()
+				}
+			}()
+		}
+		return &cached, status, nil
+	default:
+		refreshed, _, _, err := s.RefreshInLanguage(tmdbID, s.preferredLanguage)
+		if err != nil {
+			return nil, status, err
+		}
+		return refreshed, status, nil
+	}
+}
+
+// PurgeExpired deletes cached TMDB entries that haven't been fetched within
+// olderThan, reporting how many rows were removed.
+func (s *TMDBCacheService) PurgeExpired(olderThan time.Duration) (int64, error) {
+	return s.repo.Purge(olderThan)
+}
+
+// GetOrRefreshSeason returns the cached episode list for a season in the
+// service's preferred language when present, otherwise fetches it (subject
+// to the ETag/TTL rules described on TMDBCacheService), used by the
+// periodic sync loop to avoid refetching unchanged seasons on every run.
+func (s *TMDBCacheService) GetOrRefreshSeason(tmdbID, season int) ([]tmdb.EpisodeInfo, error) {
+	entry, hasCached, err := s.repo.Get(metadata.ProviderTMDB, strconv.Itoa(tmdbID), s.preferredLanguage, season)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, lastModified := "", ""
+	if hasCached {
+		etag = entry.ETag
+		lastModified = entry.LastModified
+
+		if etag == "" && lastModified == "" && !cacheExpired(entry.FetchedAt, ttlSeasonEpisodes) {
+			var cached []tmdb.EpisodeInfo
+			if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+				return nil, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+			}
+			return cached, nil
+		}
+	}
+
+	episodes, newETag, newLastModified, notModified, err := s.client.GetSeasonEpisodesConditionalWithLastModified(tmdbID, season, s.preferredLanguage, etag, lastModified)
+	if err != nil {
+		metrics.TMDBRefreshErrorsTotal.Inc()
 		return nil, err
 	}
 
-	payload, err := json.Marshal(details)
+	fetchedAt := timeutil.Now().Format(cacheTimestampLayout)
+
+	if notModified {
+		metrics.TMDBCacheConditional304Total.Inc()
+		if err := s.repo.TouchFetchedAt(metadata.ProviderTMDB, strconv.Itoa(tmdbID), s.preferredLanguage, season, fetchedAt); err != nil {
+			return nil, err
+		}
+		var cached []tmdb.EpisodeInfo
+		if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+			return nil, fmt.Errorf("failed to decode cached TMDB payload: %w", err)
+		}
+		return cached, nil
+	}
+
+	payload, err := json.Marshal(episodes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode TMDB payload: %w", err)
 	}
 
-	fetchedAt := timeutil.Now().Format("2006-01-02 15:04:05")
-	if err := s.repo.Upsert(tmdbID, string(payload), fetchedAt, tmdbCacheLanguage); err != nil {
+	if err := s.repo.Upsert(metadata.ProviderTMDB, strconv.Itoa(tmdbID), string(payload), newETag, newLastModified, fetchedAt, s.preferredLanguage, season); err != nil {
 		return nil, err
 	}
 
-	return details, nil
+	return episodes, nil
 }
 
-// GetOrRefresh returns cached data when present, otherwise refreshes.
-func (s *TMDBCacheService) GetOrRefresh(tmdbID int) (*tmdb.TVDetails, bool, error) {
-	cached, ok, err := s.GetCached(tmdbID)
-	if err != nil {
-		return nil, false, err
+// languagesToTry returns the preferred language followed by any fallbacks.
+func (s *TMDBCacheService) languagesToTry() []string {
+	languages := make([]string, 0, len(s.fallbackLanguages)+1)
+	languages = append(languages, s.preferredLanguage)
+	languages = append(languages, s.fallbackLanguages...)
+	return languages
+}
+
+// hasLocalizedName reports whether TMDB returned a non-empty localized name,
+// which is how a missing translation shows up for a given language.
+func hasLocalizedName(details *tmdb.TVDetails) bool {
+	return details != nil && details.Name != ""
+}
+
+// ttlForDetails picks the TV-details TTL based on the cached show's status:
+// short while a show is still airing (next_episode_to_air needs to stay
+// fresh), long once it has ended or been canceled.
+func ttlForDetails(entry *repository.CacheEntry) time.Duration {
+	var cached tmdb.TVDetails
+	if err := json.Unmarshal([]byte(entry.PayloadJSON), &cached); err != nil {
+		return ttlActiveDetails
 	}
-	if ok {
-		return cached, true, nil
+	switch cached.Status {
+	case "Ended", "Canceled":
+		return ttlEndedDetails
+	default:
+		return ttlActiveDetails
 	}
+}
 
-	refreshed, err := s.Refresh(tmdbID)
+// cacheExpired reports whether fetchedAt (in cacheTimestampLayout) is older
+// than ttl. An unparseable timestamp is treated as expired.
+func cacheExpired(fetchedAt string, ttl time.Duration) bool {
+	t, err := time.Parse(cacheTimestampLayout, fetchedAt)
 	if err != nil {
-		return nil, false, err
+		return true
 	}
-	return refreshed, false, nil
+	return timeutil.Now().Sub(t) >= ttl
 }
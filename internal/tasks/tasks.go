@@ -0,0 +1,73 @@
+// Package tasks defines the typed jobs run through the asynq-backed (Redis)
+// task queue: a payload struct and a constructor per job type, the asynq
+// counterpart to internal/jobs' Job/Handler pair. It depends only on asynq
+// itself, not on internal/service, so internal/service can import it to
+// build tasks without an import cycle back from the handlers that consume
+// them (those live in internal/service, next to the services they call).
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// Task type names, passed to asynq.NewTask and registered on the
+// asynq.ServeMux returned by service.NewTaskQueueMux.
+const (
+	TypeSyncSeasonEpisodes   = "sync:season_episodes"
+	TypeRefreshShow          = "sync:refresh_show"
+	TypeSyncAllSubscriptions = "sync:all_subscriptions"
+)
+
+// defaultMaxRetry and defaultTimeout apply to every task built in this
+// package, mirroring internal/jobs' defaultMaxRetries - a TMDB hiccup or a
+// transient network error should retry with asynq's exponential backoff
+// rather than dead-lettering on the first failure.
+const (
+	defaultMaxRetry = 5
+	defaultTimeout  = 2 * time.Minute
+)
+
+// SyncSeasonEpisodesPayload identifies the show and season to sync.
+type SyncSeasonEpisodesPayload struct {
+	TMDBID int `json:"tmdb_id"`
+	Season int `json:"season"`
+}
+
+// NewSyncSeasonEpisodesTask builds a TypeSyncSeasonEpisodes task, enqueued
+// by SubscriptionManager.Subscribe in place of the synchronous
+// SyncSeasonEpisodes call it used to make inline.
+func NewSyncSeasonEpisodesTask(tmdbID, season int) (*asynq.Task, error) {
+	payload, err := json.Marshal(SyncSeasonEpisodesPayload{TMDBID: tmdbID, Season: season})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", TypeSyncSeasonEpisodes, err)
+	}
+	return asynq.NewTask(TypeSyncSeasonEpisodes, payload, asynq.MaxRetry(defaultMaxRetry), asynq.Timeout(defaultTimeout)), nil
+}
+
+// RefreshShowPayload identifies the show to refresh against TMDB.
+type RefreshShowPayload struct {
+	TMDBID int `json:"tmdb_id"`
+}
+
+// NewRefreshShowTask builds a TypeRefreshShow task, enqueued by
+// TaskQueueScheduler for each subscribed show as its ResourceTime comes due.
+func NewRefreshShowTask(tmdbID int) (*asynq.Task, error) {
+	payload, err := json.Marshal(RefreshShowPayload{TMDBID: tmdbID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", TypeRefreshShow, err)
+	}
+	return asynq.NewTask(TypeRefreshShow, payload, asynq.MaxRetry(defaultMaxRetry), asynq.Timeout(defaultTimeout)), nil
+}
+
+// NewSyncAllSubscriptionsTask builds a TypeSyncAllSubscriptions task, the
+// asynq equivalent of a manual "/api/sync/run" trigger. It carries no
+// payload, so it retries at most once - re-running TaskGenerator.SyncAll
+// from scratch on a transient failure is cheap, but retrying it five times
+// back to back isn't worth the noise.
+func NewSyncAllSubscriptionsTask() (*asynq.Task, error) {
+	return asynq.NewTask(TypeSyncAllSubscriptions, nil, asynq.MaxRetry(1), asynq.Timeout(10*time.Minute)), nil
+}
@@ -0,0 +1,75 @@
+package tmdb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// CacheStore is a pluggable write-through cache for raw TMDB responses,
+// keyed by strings like "tmdb.tv.{id}.{lang}" (see cacheKeyTV, cacheKeySeason,
+// and cacheKeySearch below). Client consults it in GetTVDetails,
+// GetSeasonEpisodes and SearchTV (and their InLanguage variants) before
+// making an HTTP call and writes through to it on a successful response, so
+// those calls can be served from what was cached on a previous run instead
+// of hitting TMDB. The conditional (ETag) fetches, SearchTVPage and
+// SetAPIKey always go to TMDB and never touch the store.
+type CacheStore interface {
+	// Get returns the cached payload for key, and whether it was found and
+	// has not yet expired. A missing or expired entry is reported the same
+	// way (found=false), so a caller never needs to special-case expiry.
+	Get(key string) (data []byte, found bool, err error)
+	// Set stores data under key, to expire after ttl.
+	Set(key string, data []byte, ttl time.Duration) error
+	// Delete evicts key. Deleting a key that isn't present is not an error.
+	Delete(key string) error
+	// Keys lists every key currently stored, expired or not, for the
+	// /admin/cache inspection endpoint.
+	Keys() ([]string, error)
+}
+
+// WithCacheStore configures Client to consult store for the calls listed on
+// CacheStore and write through to it on a successful response. Left unset, Client
+// behaves exactly as before and always hits TMDB directly.
+func WithCacheStore(store CacheStore) ClientOption {
+	return func(c *Client) {
+		c.cacheStore = store
+	}
+}
+
+// Per-key TTLs used when writing through to the configured CacheStore.
+// ttlEndedShowDetails is long because a show TMDB reports as ended or
+// canceled essentially never changes again; ttlActiveShowDetails is short
+// enough that a returning series' status/episode counts stay reasonably
+// current.
+const (
+	ttlActiveShowDetails = 6 * time.Hour
+	ttlEndedShowDetails  = 7 * 24 * time.Hour
+	ttlSeasonEpisodes    = 24 * time.Hour
+	ttlSearchResults     = 24 * time.Hour
+)
+
+func cacheKeyTV(tmdbID int, language string) string {
+	return fmt.Sprintf("tmdb.tv.%d.%s", tmdbID, language)
+}
+
+func cacheKeySeason(tmdbID, season int, language string) string {
+	return fmt.Sprintf("tmdb.season.%d.%d.%s", tmdbID, season, language)
+}
+
+// cacheKeySearch hex-encodes query so arbitrary search text (spaces,
+// punctuation, non-ASCII titles) still makes a safe, stable cache key.
+func cacheKeySearch(query, language string) string {
+	return fmt.Sprintf("tmdb.search.%s.%s", hex.EncodeToString([]byte(query)), language)
+}
+
+// showDetailsTTL picks the write-through TTL for a TV details response based
+// on whether TMDB reports the show as still running.
+func showDetailsTTL(status string) time.Duration {
+	switch status {
+	case "Ended", "Canceled":
+		return ttlEndedShowDetails
+	default:
+		return ttlActiveShowDetails
+	}
+}
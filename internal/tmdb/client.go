@@ -6,21 +6,28 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
-	defaultBaseURL    = "https://api.themoviedb.org/3"
-	defaultTimeout    = 10 * time.Second
-	requestInterval   = 100 * time.Millisecond // 请求间隔，避免触发限流
+	defaultBaseURL  = "https://api.themoviedb.org/3"
+	defaultTimeout  = 10 * time.Second
+	DefaultLanguage = "zh-CN"
 )
 
 // Client handles all interactions with the TMDB API
 type Client struct {
-	apiKey      string
-	baseURL     string
-	httpClient  *http.Client
-	lastRequest time.Time
+	apiKey     atomic.Value // string, swapped live by SetAPIKey
+	baseURL    string
+	language   string
+	httpClient *http.Client
+	limiter    *rate.Limiter
+	stats      *clientStats
+	cacheStore CacheStore // set via WithCacheStore; nil means always hit TMDB
 }
 
 // SearchResult represents a single TV show from search results
@@ -41,10 +48,19 @@ type EpisodeInfo struct {
 	Overview      string `json:"overview"`
 }
 
+// Genre is a TMDB genre tag, e.g. {ID: 18, Name: "Drama"}.
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 // TVDetails represents detailed TV show information
 type TVDetails struct {
 	ID               int          `json:"id"`
 	Name             string       `json:"name"`
+	OriginalName     string       `json:"original_name"`
+	Overview         string       `json:"overview"`
+	Genres           []Genre      `json:"genres"`
 	Status           string       `json:"status"`
 	PosterPath       string       `json:"poster_path"`
 	OriginCountry    []string     `json:"origin_country"`
@@ -58,10 +74,12 @@ type SeasonDetail struct {
 	Episodes []EpisodeInfo `json:"episodes"`
 }
 
-
 // searchResponse wraps the TMDB search API response
 type searchResponse struct {
-	Results []SearchResult `json:"results"`
+	Page         int            `json:"page"`
+	Results      []SearchResult `json:"results"`
+	TotalPages   int            `json:"total_pages"`
+	TotalResults int            `json:"total_results"`
 }
 
 // APIError represents an error returned by the TMDB API
@@ -75,23 +93,60 @@ func (e *APIError) Error() string {
 }
 
 // NewClient creates a new TMDB API client
-func NewClient(apiKey string) *Client {
-	return &Client{
-		apiKey:  apiKey,
-		baseURL: defaultBaseURL,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
-	}
+func NewClient(apiKey string, opts ...ClientOption) *Client {
+	return newClient(apiKey, &http.Client{Timeout: defaultTimeout}, opts...)
 }
 
 // NewClientWithHTTP creates a new TMDB API client with a custom HTTP client
-func NewClientWithHTTP(apiKey string, httpClient *http.Client) *Client {
-	return &Client{
-		apiKey:     apiKey,
+func NewClientWithHTTP(apiKey string, httpClient *http.Client, opts ...ClientOption) *Client {
+	return newClient(apiKey, httpClient, opts...)
+}
+
+func newClient(apiKey string, httpClient *http.Client, opts ...ClientOption) *Client {
+	c := &Client{
 		baseURL:    defaultBaseURL,
+		language:   DefaultLanguage,
 		httpClient: httpClient,
+		limiter:    rate.NewLimiter(rate.Limit(defaultRequestsPerSecond), defaultBurst),
+		stats:      &clientStats{},
 	}
+	c.apiKey.Store(apiKey)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// currentAPIKey returns the key currently used for outgoing requests,
+// reflecting the latest SetAPIKey call if any.
+func (c *Client) currentAPIKey() string {
+	key, _ := c.apiKey.Load().(string)
+	return key
+}
+
+// SetAPIKey validates key against TMDB's /configuration endpoint and, on
+// success, atomically swaps it in for all future requests. It does not
+// persist the key itself - callers that want it to survive a restart should
+// save it via repository.SettingsRepository first (see
+// notify.TelegramBot.handleAPIKeyInput).
+func (c *Client) SetAPIKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("API key must not be empty")
+	}
+
+	endpoint := fmt.Sprintf("%s/configuration?api_key=%s", c.baseURL, url.QueryEscape(key))
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to validate API key: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return fmt.Errorf("API key rejected by TMDB: %w", err)
+	}
+
+	c.apiKey.Store(key)
+	return nil
 }
 
 // SetBaseURL allows overriding the base URL (useful for testing)
@@ -99,19 +154,44 @@ func (c *Client) SetBaseURL(baseURL string) {
 	c.baseURL = baseURL
 }
 
-// SearchTV searches for TV shows by query string
-// Calls TMDB /search/tv API with Chinese language
+// SetLanguage sets the default TMDB language (e.g. "zh-CN", "en-US") used
+// when a request doesn't specify one explicitly.
+func (c *Client) SetLanguage(language string) {
+	if language == "" {
+		language = DefaultLanguage
+	}
+	c.language = language
+}
+
+// SearchTV searches for TV shows by query string using the client's default language.
 func (c *Client) SearchTV(query string) ([]SearchResult, error) {
+	return c.SearchTVInLanguage(query, c.language)
+}
+
+// SearchTVInLanguage searches for TV shows by query string in the given TMDB language.
+// Calls TMDB /search/tv API.
+func (c *Client) SearchTVInLanguage(query, language string) ([]SearchResult, error) {
 	if query == "" {
 		return []SearchResult{}, nil
 	}
+	if language == "" {
+		language = c.language
+	}
 
-	c.rateLimit() // 限流
+	cacheKey := cacheKeySearch(query, language)
+	if c.cacheStore != nil {
+		if cached, found, err := c.cacheStore.Get(cacheKey); err == nil && found {
+			var results []SearchResult
+			if err := json.Unmarshal(cached, &results); err == nil {
+				return results, nil
+			}
+		}
+	}
 
-	endpoint := fmt.Sprintf("%s/search/tv?api_key=%s&query=%s&language=zh-CN",
-		c.baseURL, c.apiKey, url.QueryEscape(query))
+	endpoint := fmt.Sprintf("%s/search/tv?api_key=%s&query=%s&language=%s",
+		c.baseURL, c.currentAPIKey(), url.QueryEscape(query), url.QueryEscape(language))
 
-	resp, err := c.httpClient.Get(endpoint)
+	resp, err := c.get(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search TV shows: %w", err)
 	}
@@ -126,22 +206,83 @@ func (c *Client) SearchTV(query string) ([]SearchResult, error) {
 		return nil, fmt.Errorf("failed to decode search response: %w", err)
 	}
 
+	if c.cacheStore != nil {
+		if raw, err := json.Marshal(result.Results); err == nil {
+			_ = c.cacheStore.Set(cacheKey, raw, ttlSearchResults)
+		}
+	}
+
 	return result.Results, nil
 }
 
+// SearchTVPage searches for TV shows by query string and page number using
+// the client's default language, returning the total page count TMDB
+// reports alongside the results (for SearchResultsKeyboard's pagination).
+func (c *Client) SearchTVPage(query string, page int) ([]SearchResult, int, error) {
+	return c.SearchTVPageInLanguage(query, page, c.language)
+}
+
+// SearchTVPageInLanguage is SearchTVPage with an explicit TMDB language.
+func (c *Client) SearchTVPageInLanguage(query string, page int, language string) ([]SearchResult, int, error) {
+	if query == "" {
+		return []SearchResult{}, 0, nil
+	}
+	if language == "" {
+		language = c.language
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	endpoint := fmt.Sprintf("%s/search/tv?api_key=%s&query=%s&language=%s&page=%d",
+		c.baseURL, c.currentAPIKey(), url.QueryEscape(query), url.QueryEscape(language), page)
+
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search TV shows: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, 0, err
+	}
+
+	var result searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode search response: %w", err)
+	}
 
-// GetTVDetails fetches detailed information for a TV show
-// Calls TMDB /tv/{id} API with Chinese language
+	return result.Results, result.TotalPages, nil
+}
+
+// GetTVDetails fetches detailed information for a TV show using the client's default language.
 func (c *Client) GetTVDetails(tmdbID int) (*TVDetails, error) {
+	return c.GetTVDetailsInLanguage(tmdbID, c.language)
+}
+
+// GetTVDetailsInLanguage fetches detailed information for a TV show in the given TMDB language.
+// Calls TMDB /tv/{id} API.
+func (c *Client) GetTVDetailsInLanguage(tmdbID int, language string) (*TVDetails, error) {
 	if tmdbID <= 0 {
 		return nil, fmt.Errorf("invalid TMDB ID: %d", tmdbID)
 	}
+	if language == "" {
+		language = c.language
+	}
 
-	c.rateLimit() // 限流
+	cacheKey := cacheKeyTV(tmdbID, language)
+	if c.cacheStore != nil {
+		if cached, found, err := c.cacheStore.Get(cacheKey); err == nil && found {
+			var details TVDetails
+			if err := json.Unmarshal(cached, &details); err == nil {
+				return &details, nil
+			}
+		}
+	}
 
-	endpoint := fmt.Sprintf("%s/tv/%d?api_key=%s&language=zh-CN", c.baseURL, tmdbID, c.apiKey)
+	endpoint := fmt.Sprintf("%s/tv/%d?api_key=%s&language=%s", c.baseURL, tmdbID, c.currentAPIKey(), url.QueryEscape(language))
 
-	resp, err := c.httpClient.Get(endpoint)
+	resp, err := c.get(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get TV details: %w", err)
 	}
@@ -156,25 +297,97 @@ func (c *Client) GetTVDetails(tmdbID int) (*TVDetails, error) {
 		return nil, fmt.Errorf("failed to decode TV details response: %w", err)
 	}
 
+	if c.cacheStore != nil {
+		if raw, err := json.Marshal(details); err == nil {
+			_ = c.cacheStore.Set(cacheKey, raw, showDetailsTTL(details.Status))
+		}
+	}
+
 	return &details, nil
 }
 
-// GetSeasonEpisodes fetches all episodes for a specific season
-// Calls TMDB /tv/{id}/season/{season} API with Chinese language
+// GetTVDetailsConditional is like GetTVDetailsInLanguage but sends an
+// If-None-Match request header when etag is non-empty. If TMDB responds 304
+// Not Modified, it returns notModified=true and a nil TVDetails so the
+// caller can skip re-processing an unchanged payload; otherwise it returns
+// the decoded details along with the response's new ETag header.
+func (c *Client) GetTVDetailsConditional(tmdbID int, language, etag string) (details *TVDetails, newETag string, notModified bool, err error) {
+	details, newETag, _, notModified, err = c.GetTVDetailsConditionalWithLastModified(tmdbID, language, etag, "")
+	return details, newETag, notModified, err
+}
+
+// GetTVDetailsConditionalWithLastModified is like GetTVDetailsConditional
+// but also sends If-Modified-Since when lastModified is non-empty, and
+// returns the response's Last-Modified header alongside its ETag. TMDB
+// itself only ever revalidates on ETag, but sending both costs nothing and
+// lets a caching proxy in front of TMDB revalidate on either.
+func (c *Client) GetTVDetailsConditionalWithLastModified(tmdbID int, language, etag, lastModified string) (details *TVDetails, newETag, newLastModified string, notModified bool, err error) {
+	if tmdbID <= 0 {
+		return nil, "", "", false, fmt.Errorf("invalid TMDB ID: %d", tmdbID)
+	}
+	if language == "" {
+		language = c.language
+	}
+
+	endpoint := fmt.Sprintf("%s/tv/%d?api_key=%s&language=%s", c.baseURL, tmdbID, c.currentAPIKey(), url.QueryEscape(language))
+
+	resp, err := c.getWithHeaders(endpoint, map[string]string{
+		"If-None-Match":     etag,
+		"If-Modified-Since": lastModified,
+	})
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to get TV details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, lastModified, true, nil
+	}
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, "", "", false, err
+	}
+
+	var result TVDetails
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to decode TV details response: %w", err)
+	}
+
+	return &result, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), false, nil
+}
+
+// GetSeasonEpisodes fetches all episodes for a specific season using the client's default language.
 func (c *Client) GetSeasonEpisodes(tmdbID, seasonNumber int) ([]EpisodeInfo, error) {
+	return c.GetSeasonEpisodesInLanguage(tmdbID, seasonNumber, c.language)
+}
+
+// GetSeasonEpisodesInLanguage fetches all episodes for a specific season in the given TMDB language.
+// Calls TMDB /tv/{id}/season/{season} API.
+func (c *Client) GetSeasonEpisodesInLanguage(tmdbID, seasonNumber int, language string) ([]EpisodeInfo, error) {
 	if tmdbID <= 0 {
 		return nil, fmt.Errorf("invalid TMDB ID: %d", tmdbID)
 	}
 	if seasonNumber < 0 {
 		return nil, fmt.Errorf("invalid season number: %d", seasonNumber)
 	}
+	if language == "" {
+		language = c.language
+	}
 
-	c.rateLimit() // 限流
+	cacheKey := cacheKeySeason(tmdbID, seasonNumber, language)
+	if c.cacheStore != nil {
+		if cached, found, err := c.cacheStore.Get(cacheKey); err == nil && found {
+			var episodes []EpisodeInfo
+			if err := json.Unmarshal(cached, &episodes); err == nil {
+				return episodes, nil
+			}
+		}
+	}
 
-	endpoint := fmt.Sprintf("%s/tv/%d/season/%d?api_key=%s&language=zh-CN",
-		c.baseURL, tmdbID, seasonNumber, c.apiKey)
+	endpoint := fmt.Sprintf("%s/tv/%d/season/%d?api_key=%s&language=%s",
+		c.baseURL, tmdbID, seasonNumber, c.currentAPIKey(), url.QueryEscape(language))
 
-	resp, err := c.httpClient.Get(endpoint)
+	resp, err := c.get(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get season episodes: %w", err)
 	}
@@ -189,9 +402,115 @@ func (c *Client) GetSeasonEpisodes(tmdbID, seasonNumber int) ([]EpisodeInfo, err
 		return nil, fmt.Errorf("failed to decode season response: %w", err)
 	}
 
+	if c.cacheStore != nil {
+		if raw, err := json.Marshal(season.Episodes); err == nil {
+			_ = c.cacheStore.Set(cacheKey, raw, ttlSeasonEpisodes)
+		}
+	}
+
 	return season.Episodes, nil
 }
 
+// GetSeasonEpisodesConditional is like GetSeasonEpisodesInLanguage but sends
+// an If-None-Match request header when etag is non-empty. If TMDB responds
+// 304 Not Modified, it returns notModified=true and a nil episode slice so
+// the caller can skip re-processing an unchanged payload; otherwise it
+// returns the decoded episodes along with the response's new ETag header.
+func (c *Client) GetSeasonEpisodesConditional(tmdbID, seasonNumber int, language, etag string) (episodes []EpisodeInfo, newETag string, notModified bool, err error) {
+	episodes, newETag, _, notModified, err = c.GetSeasonEpisodesConditionalWithLastModified(tmdbID, seasonNumber, language, etag, "")
+	return episodes, newETag, notModified, err
+}
+
+// GetSeasonEpisodesConditionalWithLastModified is like
+// GetSeasonEpisodesConditional but also sends If-Modified-Since when
+// lastModified is non-empty, and returns the response's Last-Modified
+// header alongside its ETag.
+func (c *Client) GetSeasonEpisodesConditionalWithLastModified(tmdbID, seasonNumber int, language, etag, lastModified string) (episodes []EpisodeInfo, newETag, newLastModified string, notModified bool, err error) {
+	if tmdbID <= 0 {
+		return nil, "", "", false, fmt.Errorf("invalid TMDB ID: %d", tmdbID)
+	}
+	if seasonNumber < 0 {
+		return nil, "", "", false, fmt.Errorf("invalid season number: %d", seasonNumber)
+	}
+	if language == "" {
+		language = c.language
+	}
+
+	endpoint := fmt.Sprintf("%s/tv/%d/season/%d?api_key=%s&language=%s",
+		c.baseURL, tmdbID, seasonNumber, c.currentAPIKey(), url.QueryEscape(language))
+
+	resp, err := c.getWithHeaders(endpoint, map[string]string{
+		"If-None-Match":     etag,
+		"If-Modified-Since": lastModified,
+	})
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to get season episodes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, lastModified, true, nil
+	}
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, "", "", false, err
+	}
+
+	var season SeasonDetail
+	if err := json.NewDecoder(resp.Body).Decode(&season); err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to decode season response: %w", err)
+	}
+
+	return season.Episodes, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), false, nil
+}
+
+// Purge evicts every cached response for tmdbID (show details and every
+// cached season, in every language) from the configured CacheStore. It's a
+// no-op if no CacheStore is configured. SubscriptionManager.Unsubscribe
+// calls this so a re-subscribe later doesn't serve a stale cached show
+// that's since changed.
+func (c *Client) Purge(tmdbID int) error {
+	if c.cacheStore == nil {
+		return nil
+	}
+
+	keys, err := c.cacheStore.Keys()
+	if err != nil {
+		return fmt.Errorf("failed to list TMDB cache keys: %w", err)
+	}
+
+	tvPrefix := fmt.Sprintf("tmdb.tv.%d.", tmdbID)
+	seasonPrefix := fmt.Sprintf("tmdb.season.%d.", tmdbID)
+	for _, key := range keys {
+		if strings.HasPrefix(key, tvPrefix) || strings.HasPrefix(key, seasonPrefix) {
+			if err := c.cacheStore.Delete(key); err != nil {
+				return fmt.Errorf("failed to evict TMDB cache key %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CacheKeys lists every key in the configured CacheStore, for the
+// /admin/cache inspection endpoint. It errors if no CacheStore is
+// configured.
+func (c *Client) CacheKeys() ([]string, error) {
+	if c.cacheStore == nil {
+		return nil, fmt.Errorf("no TMDB cache store configured")
+	}
+	return c.cacheStore.Keys()
+}
+
+// EvictCacheKey deletes a single key from the configured CacheStore, for
+// the /admin/cache inspection endpoint. It errors if no CacheStore is
+// configured.
+func (c *Client) EvictCacheKey(key string) error {
+	if c.cacheStore == nil {
+		return fmt.Errorf("no TMDB cache store configured")
+	}
+	return c.cacheStore.Delete(key)
+}
+
 // checkResponse checks the HTTP response for errors
 func (c *Client) checkResponse(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
@@ -223,12 +542,3 @@ func (c *Client) checkResponse(resp *http.Response) error {
 
 	return &apiErr
 }
-
-// rateLimit ensures requests are spaced out to avoid hitting API limits
-func (c *Client) rateLimit() {
-	elapsed := time.Since(c.lastRequest)
-	if elapsed < requestInterval {
-		time.Sleep(requestInterval - elapsed)
-	}
-	c.lastRequest = time.Now()
-}
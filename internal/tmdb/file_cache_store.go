@@ -0,0 +1,99 @@
+package tmdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileCacheStore is a CacheStore backed by one JSON file per key under dir,
+// so the app can cold-start (or run property tests) against previously
+// fetched TMDB responses without a network connection.
+type FileCacheStore struct {
+	dir string
+}
+
+// NewFileCacheStore creates a FileCacheStore rooted at dir, creating it if
+// it doesn't already exist.
+func NewFileCacheStore(dir string) (*FileCacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create TMDB cache dir %s: %w", dir, err)
+	}
+	return &FileCacheStore{dir: dir}, nil
+}
+
+// fileCacheEntry is the on-disk shape of one cached response.
+type fileCacheEntry struct {
+	ExpiresAt time.Time       `json:"expires_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Get returns the cached payload for key. An expired entry is treated the
+// same as a missing one (found=false) rather than returned along with an
+// error, so the caller always just falls through to a live TMDB call.
+func (s *FileCacheStore) Get(key string) ([]byte, bool, error) {
+	raw, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read TMDB cache entry %s: %w", key, err)
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to decode TMDB cache entry %s: %w", key, err)
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, false, nil
+	}
+	return entry.Payload, true, nil
+}
+
+// Set stores data under key, to expire after ttl.
+func (s *FileCacheStore) Set(key string, data []byte, ttl time.Duration) error {
+	entry := fileCacheEntry{
+		ExpiresAt: time.Now().Add(ttl),
+		Payload:   data,
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode TMDB cache entry %s: %w", key, err)
+	}
+	if err := os.WriteFile(s.path(key), raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write TMDB cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete evicts key. Deleting a key that isn't present is not an error.
+func (s *FileCacheStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete TMDB cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+// Keys lists every key currently stored, expired or not.
+func (s *FileCacheStore) Keys() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list TMDB cache dir %s: %w", s.dir, err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return keys, nil
+}
+
+func (s *FileCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
@@ -0,0 +1,166 @@
+package tmdb
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestsPerSecond stays comfortably under TMDB's documented
+	// ~50 requests/second budget.
+	defaultRequestsPerSecond = 40
+	defaultBurst             = 10
+
+	maxRetries  = 3
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 8 * time.Second
+)
+
+// ClientOption configures optional Client behavior, e.g. substituting a
+// deterministic rate limiter in tests.
+type ClientOption func(*Client)
+
+// WithLimiter overrides the client's token-bucket limiter.
+func WithLimiter(limiter *rate.Limiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithRateLimit configures the token-bucket limiter from a requests-per-second
+// rate and burst size, without requiring callers to import golang.org/x/time/rate.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+}
+
+// Stats is a snapshot of request counters tracked by a Client.
+type Stats struct {
+	Requests       int64 `json:"requests"`
+	ThrottledWaits int64 `json:"throttled_waits"`
+	Retries        int64 `json:"retries"`
+	Throttled429s  int64 `json:"throttled_429s"`
+}
+
+// clientStats holds the atomic counters backing Client.Stats().
+type clientStats struct {
+	requests       int64
+	throttledWaits int64
+	retries        int64
+	throttled429s  int64
+}
+
+// Stats returns a snapshot of the client's request counters.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:       atomic.LoadInt64(&c.stats.requests),
+		ThrottledWaits: atomic.LoadInt64(&c.stats.throttledWaits),
+		Retries:        atomic.LoadInt64(&c.stats.retries),
+		Throttled429s:  atomic.LoadInt64(&c.stats.throttled429s),
+	}
+}
+
+// rateLimit blocks until the token-bucket limiter admits one more request,
+// safe for concurrent callers (unlike the old lastRequest/sleep approach).
+func (c *Client) rateLimit() {
+	reservation := c.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		atomic.AddInt64(&c.stats.throttledWaits, 1)
+		time.Sleep(delay)
+	}
+}
+
+// get performs an HTTP GET against endpoint, honoring the rate limiter and
+// retrying on HTTP 429 with exponential backoff and jitter, preferring the
+// server's Retry-After header when present.
+func (c *Client) get(endpoint string) (*http.Response, error) {
+	return c.getWithHeaders(endpoint, nil)
+}
+
+// getWithHeader is like get but sets a single extra request header (e.g.
+// If-None-Match) when headerValue is non-empty.
+func (c *Client) getWithHeader(endpoint, headerName, headerValue string) (*http.Response, error) {
+	if headerName == "" || headerValue == "" {
+		return c.getWithHeaders(endpoint, nil)
+	}
+	return c.getWithHeaders(endpoint, map[string]string{headerName: headerValue})
+}
+
+// getWithHeaders is like get but sets any number of extra request headers
+// (e.g. If-None-Match alongside If-Modified-Since for a conditional
+// revalidation); entries with an empty value are skipped.
+func (c *Client) getWithHeaders(endpoint string, headers map[string]string) (*http.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		c.rateLimit()
+
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range headers {
+			if name != "" && value != "" {
+				req.Header.Set(name, value)
+			}
+		}
+
+		resp, err := c.httpClient.Do(req)
+		atomic.AddInt64(&c.stats.requests, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		atomic.AddInt64(&c.stats.throttled429s, 1)
+		wait := retryAfterDelay(resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+
+		if attempt == maxRetries {
+			lastErr = &APIError{StatusCode: http.StatusTooManyRequests, StatusMessage: "rate limited after exhausting retries"}
+			break
+		}
+
+		if wait <= 0 {
+			wait = backoffWithJitter(attempt)
+		}
+		atomic.AddInt64(&c.stats.retries, 1)
+		time.Sleep(wait)
+	}
+
+	return nil, lastErr
+}
+
+// retryAfterDelay parses a Retry-After header value (seconds), returning 0
+// if it is absent or malformed.
+func retryAfterDelay(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// backoffWithJitter returns an exponential backoff duration for the given
+// retry attempt (0-indexed), capped at maxBackoff and jittered by up to 50%
+// to avoid retry storms from concurrent callers.
+func backoffWithJitter(attempt int) time.Duration {
+	backoff := baseBackoff * time.Duration(1<<uint(attempt))
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+	return backoff/2 + jitter
+}
@@ -0,0 +1,267 @@
+// Package tmdbtest provides an in-process fake TMDB HTTP server for gopter
+// property tests, so tests exercise tmdb.Client's real request/response/retry
+// path instead of ad-hoc httptest.NewServer handlers that only ever answer
+// one hardcoded shape.
+package tmdbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/tmdb"
+)
+
+// Request records one inbound call for test assertions.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+// showFixture holds everything Server needs to answer /tv/{id} and
+// /tv/{id}/season/{n} for one show.
+type showFixture struct {
+	show     models.TVShow
+	episodes map[int][]tmdb.EpisodeInfo // by season number
+}
+
+// Server is a fake TMDB backend wired up via tmdb.Client.SetBaseURL. It is
+// safe for concurrent use, since the client may issue requests from multiple
+// goroutines.
+type Server struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	shows         map[int]*showFixture
+	searchResults map[string][]tmdb.SearchResult
+	failNext      map[string]int
+	latency       time.Duration
+	messages      []Request
+}
+
+// NewServer starts a fake TMDB server and registers it to close with t.Cleanup.
+func NewServer(t *testing.T) *Server {
+	s := &Server{
+		t:             t,
+		shows:         make(map[int]*showFixture),
+		searchResults: make(map[string][]tmdb.SearchResult),
+		failNext:      make(map[string]int),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL returns the fake server's base URL, for tmdb.Client.SetBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// AddShow registers fixture data for /tv/{id} and /tv/{id}/season/{n},
+// bucketing episodes by their SeasonNumber. The earliest-airing episode
+// across all seasons is reported as next_episode_to_air, matching how TMDB
+// surfaces upcoming episodes on the show details endpoint.
+func (s *Server) AddShow(show models.TVShow, episodes []tmdb.EpisodeInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fixture := &showFixture{show: show, episodes: make(map[int][]tmdb.EpisodeInfo)}
+	for _, ep := range episodes {
+		fixture.episodes[ep.SeasonNumber] = append(fixture.episodes[ep.SeasonNumber], ep)
+	}
+	s.shows[show.TMDBID] = fixture
+}
+
+// SetSearchResults registers the results /search/tv should return for query q.
+func (s *Server) SetSearchResults(q string, results []tmdb.SearchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchResults[q] = results
+}
+
+// FailNext makes the next request whose path has the given prefix respond
+// with status instead of its normal fixture response, then reverts to normal
+// behavior. Use it to exercise rate-limit (429) retry handling.
+func (s *Server) FailNext(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext[path] = status
+}
+
+// SetLatency makes every handled request sleep for d before responding, to
+// exercise client timeout behavior.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Messages returns every request the server has received so far, in order.
+func (s *Server) Messages() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Request, len(s.messages))
+	copy(out, s.messages)
+	return out
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.messages = append(s.messages, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()})
+	latency := s.latency
+	failStatus, failPath := s.matchFailNext(r.URL.Path)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if failStatus != 0 {
+		s.mu.Lock()
+		delete(s.failNext, failPath)
+		s.mu.Unlock()
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(failStatus)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: failStatus, StatusMessage: http.StatusText(failStatus)})
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/search/tv":
+		s.handleSearch(w, r)
+	case strings.Contains(r.URL.Path, "/season/"):
+		s.handleSeason(w, r)
+	case strings.HasPrefix(r.URL.Path, "/tv/"):
+		s.handleShow(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "not found"})
+	}
+}
+
+// matchFailNext returns the registered failure status and the prefix that
+// triggered it, or (0, "") if none apply. Must be called with s.mu held.
+func (s *Server) matchFailNext(path string) (int, string) {
+	for prefix, status := range s.failNext {
+		if strings.HasPrefix(path, prefix) {
+			return status, prefix
+		}
+	}
+	return 0, ""
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("query")
+
+	s.mu.Lock()
+	results := s.searchResults[query]
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"page":          1,
+		"results":       results,
+		"total_pages":   1,
+		"total_results": len(results),
+	})
+}
+
+func (s *Server) handleShow(w http.ResponseWriter, r *http.Request) {
+	var tmdbID int
+	if _, err := fmt.Sscanf(r.URL.Path, "/tv/%d", &tmdbID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "malformed show id"})
+		return
+	}
+
+	s.mu.Lock()
+	fixture, ok := s.shows[tmdbID]
+	s.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "the resource you requested could not be found"})
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"id":                  fixture.show.TMDBID,
+		"name":                fixture.show.Name,
+		"original_name":       fixture.show.OriginalName,
+		"overview":            fixture.show.Overview,
+		"genres":              genresFromCSV(fixture.show.Genres),
+		"status":              fixture.show.Status,
+		"origin_country":      countriesFromCSV(fixture.show.OriginCountry),
+		"number_of_seasons":   fixture.show.TotalSeasons,
+		"next_episode_to_air": nextEpisode(fixture.episodes),
+	})
+}
+
+func (s *Server) handleSeason(w http.ResponseWriter, r *http.Request) {
+	var tmdbID, season int
+	if _, err := fmt.Sscanf(r.URL.Path, "/tv/%d/season/%d", &tmdbID, &season); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "malformed season path"})
+		return
+	}
+
+	s.mu.Lock()
+	fixture, ok := s.shows[tmdbID]
+	s.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(tmdb.APIError{StatusCode: http.StatusNotFound, StatusMessage: "the resource you requested could not be found"})
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"episodes": fixture.episodes[season],
+	})
+}
+
+// nextEpisode returns the earliest-airing episode across all seasons, or nil
+// if the show has none, mirroring TMDB's next_episode_to_air field.
+func nextEpisode(bySeason map[int][]tmdb.EpisodeInfo) *tmdb.EpisodeInfo {
+	var all []tmdb.EpisodeInfo
+	for _, eps := range bySeason {
+		all = append(all, eps...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].AirDate < all[j].AirDate })
+	return &all[0]
+}
+
+func genresFromCSV(csv string) []tmdb.Genre {
+	if csv == "" {
+		return nil
+	}
+	parts := strings.Split(csv, ",")
+	genres := make([]tmdb.Genre, 0, len(parts))
+	for _, name := range parts {
+		genres = append(genres, tmdb.Genre{Name: name})
+	}
+	return genres
+}
+
+func countriesFromCSV(csv string) []string {
+	if csv == "" {
+		return nil
+	}
+	return strings.Split(csv, ",")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(v)
+}
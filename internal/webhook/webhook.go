@@ -0,0 +1,211 @@
+// Package webhook implements WebSub-style push notifications: external
+// services register a callback URL and topic filter, prove they own the
+// callback via a verification handshake, and then receive signed deliveries
+// whenever a matching event occurs.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tv-tracker/internal/models"
+	"tv-tracker/internal/repository"
+	"tv-tracker/internal/timeutil"
+)
+
+const (
+	maxDeliveryAttempts = 5
+	baseRetryBackoff    = 1 * time.Second
+	maxRetryBackoff     = 30 * time.Second
+
+	verificationTimeout = 10 * time.Second
+	deliveryTimeout     = 10 * time.Second
+)
+
+// Event is a new-episode notification delivered to matching subscribers.
+type Event struct {
+	TMDBID       int    `json:"tmdb_id"`
+	ShowName     string `json:"show_name"`
+	Season       int    `json:"season"`
+	Episode      int    `json:"episode"`
+	ResourceTime string `json:"resource_time"`
+}
+
+// Manager registers and delivers webhook subscriptions.
+type Manager struct {
+	repo       *repository.WebhookRepository
+	httpClient *http.Client
+}
+
+// NewManager creates a new Manager.
+func NewManager(repo *repository.WebhookRepository) *Manager {
+	return &Manager{
+		repo:       repo,
+		httpClient: &http.Client{Timeout: deliveryTimeout},
+	}
+}
+
+// Subscribe performs the WebSub verification handshake against callbackURL
+// and, if the subscriber echoes the challenge back with 200 OK, persists the
+// subscription. topic is either "*" or a specific TMDB ID as a string.
+func (m *Manager) Subscribe(callbackURL, topic, secret string, leaseSeconds int) (*models.WebhookSubscription, error) {
+	challenge := randomChallenge()
+
+	verifyURL := fmt.Sprintf("%s?mode=subscribe&topic=%s&challenge=%s&lease_seconds=%d", callbackURL, topic, challenge, leaseSeconds)
+
+	client := &http.Client{Timeout: verificationTimeout}
+	resp, err := client.Get(verifyURL)
+	if err != nil {
+		return nil, fmt.Errorf("webhook verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || string(body) != challenge {
+		return nil, fmt.Errorf("webhook verification failed: callback did not echo the challenge")
+	}
+
+	now := timeutil.Now()
+	expiresAt := now.Add(time.Duration(leaseSeconds) * time.Second)
+	sub := &models.WebhookSubscription{
+		CallbackURL:  callbackURL,
+		Topic:        topic,
+		Secret:       secret,
+		LeaseSeconds: leaseSeconds,
+		VerifiedAt:   &now,
+		ExpiresAt:    &expiresAt,
+	}
+
+	if err := m.repo.Create(sub); err != nil {
+		return nil, fmt.Errorf("failed to persist subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription by ID.
+func (m *Manager) Unsubscribe(id int64) error {
+	return m.repo.Delete(id)
+}
+
+// List returns every registered subscription.
+func (m *Manager) List() ([]models.WebhookSubscription, error) {
+	return m.repo.GetAll()
+}
+
+// NotifyNewEpisode delivers event to every subscription matching the show's
+// TMDB ID or the wildcard topic, expiring any lease that has lapsed along
+// the way. Deliveries happen in the background so a slow or dead subscriber
+// can't block task generation.
+func (m *Manager) NotifyNewEpisode(event Event) {
+	subs, err := m.repo.GetMatchingTopic(event.TMDBID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load webhook subscriptions for tmdb_id %d: %v\n", event.TMDBID, err)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		fmt.Printf("Warning: failed to encode webhook event: %v\n", err)
+		return
+	}
+
+	for _, sub := range subs {
+		go m.deliver(sub, payload)
+	}
+}
+
+// Broadcast delivers an arbitrary JSON-encodable payload to every
+// non-expired subscription with the wildcard "*" topic, e.g. for daily
+// report notifications that aren't tied to one show.
+func (m *Manager) Broadcast(payload any) error {
+	subs, err := m.repo.GetMatchingTopic(0)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, sub := range subs {
+		if sub.Topic != "*" {
+			continue
+		}
+		go m.deliver(sub, body)
+	}
+
+	return nil
+}
+
+// deliver POSTs payload to sub's callback, signing it with the subscription
+// secret (if any) and retrying with exponential backoff on failure.
+func (m *Manager) deliver(sub models.WebhookSubscription, payload []byte) {
+	var lastErr error
+
+	for attempt := 0; attempt < maxDeliveryAttempts; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, sub.CallbackURL, bytes.NewReader(payload))
+		if err != nil {
+			lastErr = err
+			break
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sub.Secret != "" {
+			req.Header.Set("X-Hub-Signature-256", "sha256="+signPayload(sub.Secret, payload))
+		}
+
+		resp, err := m.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return
+			}
+			lastErr = fmt.Errorf("callback returned status %d", resp.StatusCode)
+		}
+
+		if attempt < maxDeliveryAttempts-1 {
+			time.Sleep(backoffWithJitter(attempt))
+		}
+	}
+
+	fmt.Printf("Warning: webhook delivery to subscription %d failed after %d attempts: %v\n", sub.ID, maxDeliveryAttempts, lastErr)
+}
+
+// signPayload computes the hex-encoded HMAC-SHA256 of payload using secret.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomChallenge generates an opaque token for the verification handshake.
+func randomChallenge() string {
+	return strconv.FormatInt(rand.Int63(), 36) + strconv.FormatInt(rand.Int63(), 36)
+}
+
+// backoffWithJitter returns an exponential backoff duration for the given
+// retry attempt (0-indexed), capped at maxRetryBackoff.
+func backoffWithJitter(attempt int) time.Duration {
+	backoff := baseRetryBackoff * time.Duration(1<<uint(attempt))
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+	return backoff/2 + jitter
+}
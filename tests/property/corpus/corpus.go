@@ -0,0 +1,149 @@
+// Package corpus adds seed pinning and failing-case persistence on top of
+// gopter's property-based tests, so a CI failure can be reproduced locally
+// (TVTRACKER_PROPTEST_SEED) and a bug gopter finds once stays covered by a
+// permanent regression case instead of only existing in that run's logs.
+package corpus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// SeedEnvVar pins gopter's random seed so a failure reported by CI can be
+// reproduced locally by re-running with the same seed.
+const SeedEnvVar = "TVTRACKER_PROPTEST_SEED"
+
+// Parameters returns gopter.DefaultTestParameters() seeded from SeedEnvVar
+// when set, and always logs the seed actually used, so any run's output
+// names the value to set SeedEnvVar to in order to reproduce it.
+func Parameters(t *testing.T) *gopter.TestParameters {
+	params := gopter.DefaultTestParameters()
+
+	seed := params.Rng.Int63()
+	if raw := os.Getenv(SeedEnvVar); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			t.Fatalf("invalid %s: %v", SeedEnvVar, err)
+		}
+		seed = parsed
+	}
+	params.Rng.Seed(seed)
+	t.Logf("%s: %s=%d reproduces this run", t.Name(), SeedEnvVar, seed)
+
+	return params
+}
+
+// RunWithCorpus wraps prop.ForAll(f, gens...): it first replays every case
+// previously saved under testdata/corpus/<t.Name()> against f, failing
+// immediately if any of them regress, then hands off to gopter as normal.
+// Any input - saved or freshly generated - that fails f is written back to
+// the corpus, so a bug caught once is guarded against forever after.
+func RunWithCorpus(t *testing.T, f interface{}, gens ...gopter.Gen) gopter.Prop {
+	dir := corpusDir(t.Name())
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+
+	for name, args := range loadCorpus(t, dir, ft) {
+		if !fv.Call(args)[0].Bool() {
+			t.Errorf("regression: saved corpus case %s still fails", name)
+		}
+	}
+
+	recording := reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		result := fv.Call(args)
+		if !result[0].Bool() {
+			saveCase(t, dir, args)
+		}
+		return result
+	})
+
+	return prop.ForAll(recording.Interface(), gens...)
+}
+
+func corpusDir(testName string) string {
+	return filepath.Join("testdata", "corpus", testName)
+}
+
+// loadCorpus reads every *.json file in dir and decodes it into args
+// matching f's parameter types, keyed by file name for error messages.
+func loadCorpus(t *testing.T, dir string, ft reflect.Type) map[string][]reflect.Value {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("reading corpus dir %s: %v", dir, err)
+	}
+
+	cases := make(map[string][]reflect.Value, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading corpus case %s: %v", path, err)
+		}
+
+		var fields []json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			t.Fatalf("decoding corpus case %s: %v", path, err)
+		}
+		if len(fields) != ft.NumIn() {
+			t.Fatalf("corpus case %s has %d fields, %s takes %d", path, len(fields), ft, ft.NumIn())
+		}
+
+		args := make([]reflect.Value, ft.NumIn())
+		for i, field := range fields {
+			argPtr := reflect.New(ft.In(i))
+			if err := json.Unmarshal(field, argPtr.Interface()); err != nil {
+				t.Fatalf("decoding corpus case %s arg %d: %v", path, i, err)
+			}
+			args[i] = argPtr.Elem()
+		}
+		cases[entry.Name()] = args
+	}
+	return cases
+}
+
+// saveCase writes args as a JSON array of its fields into a file named for
+// their content hash, so the same failing case is never saved twice.
+func saveCase(t *testing.T, dir string, args []reflect.Value) {
+	fields := make([]interface{}, len(args))
+	for i, arg := range args {
+		fields[i] = arg.Interface()
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		t.Logf("corpus: failed to encode failing case: %v", err)
+		return
+	}
+
+	sum := sha256.Sum256(encoded)
+	name := hex.EncodeToString(sum[:8]) + ".json"
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Logf("corpus: failed to create %s: %v", dir, err)
+		return
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return // already saved
+	}
+	if err := os.WriteFile(path, encoded, 0o644); err != nil {
+		t.Logf("corpus: failed to write %s: %v", path, err)
+		return
+	}
+	t.Logf("corpus: saved failing case to %s", path)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
 
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
@@ -81,13 +82,15 @@ func TestTaskRenderingCompleteness(t *testing.T) {
 				Description: description,
 				IsCompleted: false,
 			}
-			if err := taskRepo.Create(task); err != nil {
+			if _, err := taskRepo.Create(task); err != nil {
 				t.Logf("Failed to create task: %v", err)
 				return false
 			}
 
 			// Create TaskBoardService and get dashboard data
-			taskBoard := service.NewTaskBoardService(taskRepo, showRepo)
+			auditRepo := repository.NewTaskAuditRepository(db)
+			dispatcher := service.NewDispatcher()
+			taskBoard := service.NewTaskBoardService(taskRepo, showRepo, dispatcher, auditRepo, map[string]metadata.Provider{})
 			dashboardData, err := taskBoard.GetDashboardData()
 			if err != nil {
 				t.Logf("Failed to get dashboard data: %v", err)
@@ -131,10 +134,10 @@ func TestTaskRenderingCompleteness(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000),                                                     // tmdbID
-		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }),       // showName
-		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }),       // description
-		gen.OneConstOf("UPDATE", "ORGANIZE"),                                      // taskType
+		gen.IntRange(1, 1000), // tmdbID
+		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
+		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // description
+		gen.OneConstOf("UPDATE", "ORGANIZE"),                                // taskType
 	))
 
 	properties.TestingRun(t)
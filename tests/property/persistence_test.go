@@ -85,19 +85,18 @@ func TestTVShowPersistenceRoundTrip(t *testing.T) {
 				retrieved.IsArchived == original.IsArchived &&
 				retrieved.ID == original.ID
 		},
-		gen.IntRange(1, 1000000),                                                  // tmdbID
-		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }),       // name
-		gen.IntRange(0, 50),                                                       // totalSeasons
-		gen.OneConstOf("Returning Series", "Ended", "Canceled", "Unknown"),        // status
-		gen.OneConstOf("US", "UK", "CA", "CN", "TW", "JP", "KR", ""),               // originCountry
-		gen.OneConstOf("18:00", "20:00", "23:00", "待定"),                           // resourceTime
-		gen.Bool(),                                                                // isArchived
+		gen.IntRange(1, 1000000), // tmdbID
+		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // name
+		gen.IntRange(0, 50), // totalSeasons
+		gen.OneConstOf("Returning Series", "Ended", "Canceled", "Unknown"), // status
+		gen.OneConstOf("US", "UK", "CA", "CN", "TW", "JP", "KR", ""),       // originCountry
+		gen.OneConstOf("18:00", "20:00", "23:00", "待定"),                    // resourceTime
+		gen.Bool(), // isArchived
 	))
 
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 17: Task Foreign Key Integrity
 // Validates: Requirements 8.2
 // For any Task in the database, its tv_show_id SHALL reference an existing TVShow record.
@@ -164,7 +163,7 @@ func TestTaskForeignKeyIntegrity(t *testing.T) {
 				IsCompleted: false,
 			}
 
-			if err := taskRepo.Create(task); err != nil {
+			if _, err := taskRepo.Create(task); err != nil {
 				t.Logf("Failed to create Task: %v", err)
 				return false
 			}
@@ -197,7 +196,7 @@ func TestTaskForeignKeyIntegrity(t *testing.T) {
 			return retrievedTask.TVShowName == referencedShow.Name &&
 				retrievedTask.TVShowID == show.ID
 		},
-		gen.IntRange(1, 1000000),                                            // tmdbID
+		gen.IntRange(1, 1000000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
 		gen.OneConstOf("UPDATE", "ORGANIZE"),                                // taskType
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // description
@@ -0,0 +1,152 @@
+package property
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+
+	"tv-tracker/internal/release"
+)
+
+// Feature: tv-tracker, Property 16: Qiangban Token Detection
+// Validates: Requirements 12.1
+// For any of the documented cam/telesync/workprint tokens embedded in an
+// otherwise arbitrary filename, IsQiangban SHALL report true, matched
+// case-insensitively as a whole token regardless of surrounding punctuation.
+func TestQiangbanTokenDetection(t *testing.T) {
+	qiangbanTokens := []string{
+		"CAMRip", "CAM-Rip", "CAM", "HDCAM",
+		"TS", "TSRip", "HDTS", "TELESYNC",
+		"PDVD", "PreDVDRip",
+		"TC", "HDTC", "TELECINE",
+		"WP", "WORKPRINT",
+	}
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("embedding a qiangban token in a filename is always detected", prop.ForAll(
+		func(show string, season, episode int, tokenIdx int) bool {
+			token := qiangbanTokens[((tokenIdx%len(qiangbanTokens))+len(qiangbanTokens))%len(qiangbanTokens)]
+			filename := fmt.Sprintf("%s.S%02dE%02d.%s.x264-GROUP.mkv", show, season%30, episode%30, token)
+			return release.IsQiangban(filename)
+		},
+		gen.AlphaString(),
+		gen.IntRange(1, 20),
+		gen.IntRange(1, 20),
+		gen.Int(),
+	))
+
+	properties.Property("a filename with only good-quality markers is never flagged as qiangban", prop.ForAll(
+		func(show string, season, episode int) bool {
+			filename := fmt.Sprintf("%s.S%02dE%02d.1080p.WEB-DL.x264-GROUP.mkv", show, season%30, episode%30)
+			return !release.IsQiangban(filename)
+		},
+		gen.AlphaString(),
+		gen.IntRange(1, 20),
+		gen.IntRange(1, 20),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property 17: Release Parsing Extracts Season/Episode
+// Validates: Requirements 12.1
+// For any filename containing a SxxExx marker, ParseRelease SHALL extract
+// the same season and episode numbers embedded in the filename.
+func TestReleaseParsingExtractsSeasonEpisode(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("season/episode round-trip through ParseRelease", prop.ForAll(
+		func(show string, season, episode int) bool {
+			s := season%30 + 1
+			e := episode%30 + 1
+			filename := fmt.Sprintf("%s.S%02dE%02d.1080p.WEB-DL.x264-GROUP.mkv", show, s, e)
+
+			info := release.ParseRelease(filename)
+			return info.Season == s && info.Episode == e && info.Resolution == "1080p" && info.Source == "WEB-DL" && info.Codec == "X264"
+		},
+		gen.AlphaString(),
+		gen.IntRange(0, 29),
+		gen.IntRange(0, 29),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property 18: Release Parsing Handles Alternate Season/Episode Markers
+// Validates: Requirements 12.1
+// For any filename using the "1x02" or "Season.1.Episode.2" marker styles in
+// place of SxxExx, ParseRelease SHALL still extract the same season and
+// episode numbers.
+func TestReleaseParsingHandlesAlternateMarkers(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("NxEE marker round-trips through ParseRelease", prop.ForAll(
+		func(show string, season, episode int) bool {
+			s := season%30 + 1
+			e := episode%30 + 1
+			filename := fmt.Sprintf("%s.%dx%02d.720p.mkv", show, s, e)
+
+			info := release.ParseRelease(filename)
+			return info.Season == s && info.Episode == e
+		},
+		gen.AlphaString(),
+		gen.IntRange(0, 29),
+		gen.IntRange(0, 29),
+	))
+
+	properties.Property("Season.N.Episode.M marker round-trips through ParseRelease", prop.ForAll(
+		func(show string, season, episode int) bool {
+			s := season%30 + 1
+			e := episode%30 + 1
+			filename := fmt.Sprintf("%s.Season.%d.Episode.%d.720p.mkv", show, s, e)
+
+			info := release.ParseRelease(filename)
+			return info.Season == s && info.Episode == e
+		},
+		gen.AlphaString(),
+		gen.IntRange(0, 29),
+		gen.IntRange(0, 29),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property 19: Anime-Style Release Parsing
+// Validates: Requirements 12.1
+// For any filename in the "[Group] Show - NN [1080p]" anime naming
+// convention, ParseRelease SHALL default to season 1, extract the episode
+// number, and recover the release group from the leading bracket.
+func TestReleaseParsingHandlesAnimeStyle(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("anime bracket-group style round-trips through ParseRelease", prop.ForAll(
+		func(group, show string, episode int) bool {
+			e := episode%29 + 1
+			filename := fmt.Sprintf("[%s] %s - %02d [1080p].mkv", group, show, e)
+
+			info := release.ParseRelease(filename)
+			return info.Season == 1 && info.Episode == e && info.Group == group
+		},
+		gen.AlphaString().SuchThat(func(s string) bool { return s != "" }),
+		gen.AlphaString().SuchThat(func(s string) bool { return s != "" }),
+		gen.IntRange(0, 28),
+	))
+
+	properties.TestingRun(t)
+}
@@ -1,9 +1,6 @@
 package property
 
 import (
-	"encoding/json"
-	"net/http"
-	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -15,6 +12,7 @@ import (
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/tmdb/tmdbtest"
 )
 
 // Feature: tv-tracker, Property 3: Subscription Data Round-Trip
@@ -34,24 +32,15 @@ func TestSubscriptionDataRoundTrip(t *testing.T) {
 				return true
 			}
 
-			// Create mock TMDB server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Handle TV details request
-				if r.URL.Path == "/tv/"+string(rune(tmdbID)) || true {
-					response := map[string]interface{}{
-						"id":                tmdbID,
-						"name":              name,
-						"status":            status,
-						"poster_path":       "/test.jpg",
-						"origin_country":    []string{originCountry},
-						"number_of_seasons": numSeasons,
-					}
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(response)
-					return
-				}
-			}))
-			defer server.Close()
+			// Create fake TMDB server
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          name,
+				Status:        status,
+				OriginCountry: originCountry,
+				TotalSeasons:  numSeasons,
+			}, nil)
 
 			// Create temporary database
 			dbPath := "test_subscription_roundtrip.db"
@@ -72,13 +61,15 @@ func TestSubscriptionDataRoundTrip(t *testing.T) {
 			// Create repositories and services
 			showRepo := repository.NewTVShowRepository(db)
 			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
 			tmdbClient := tmdb.NewClient("test-api-key")
-			tmdbClient.SetBaseURL(server.URL)
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
 
-			subManager := service.NewSubscriptionManager(tmdbClient, showRepo, episodeRepo)
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
 
 			// Subscribe to the show
-			show, err := subManager.Subscribe(tmdbID)
+			show, _, err := subManager.Subscribe(tmdbID)
 			if err != nil {
 				t.Logf("Failed to subscribe: %v", err)
 				return false
@@ -107,11 +98,11 @@ func TestSubscriptionDataRoundTrip(t *testing.T) {
 				retrieved.Status == status &&
 				retrieved.OriginCountry == originCountry
 		},
-		gen.IntRange(1, 1000000),                                            // tmdbID
+		gen.IntRange(1, 1000000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // name
 		gen.OneConstOf("Returning Series", "Ended", "Canceled"),             // status
-		gen.OneConstOf("US", "UK", "CA", "CN", "TW", "JP", "KR"),             // originCountry
-		gen.IntRange(1, 20),                                                 // numSeasons
+		gen.OneConstOf("US", "UK", "CA", "CN", "TW", "JP", "KR"),            // originCountry
+		gen.IntRange(1, 20), // numSeasons
 	))
 
 	properties.TestingRun(t)
@@ -133,20 +124,15 @@ func TestSubscriptionIdempotence(t *testing.T) {
 				return true
 			}
 
-			// Create mock TMDB server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				response := map[string]interface{}{
-					"id":                tmdbID,
-					"name":              name,
-					"status":            "Returning Series",
-					"poster_path":       "/test.jpg",
-					"origin_country":    []string{"US"},
-					"number_of_seasons": 1,
-				}
-				w.WriteHeader(http.StatusOK)
-				json.NewEncoder(w).Encode(response)
-			}))
-			defer server.Close()
+			// Create fake TMDB server
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          name,
+				Status:        "Returning Series",
+				OriginCountry: "US",
+				TotalSeasons:  1,
+			}, nil)
 
 			// Create temporary database
 			dbPath := "test_subscription_idempotence.db"
@@ -167,15 +153,17 @@ func TestSubscriptionIdempotence(t *testing.T) {
 			// Create repositories and services
 			showRepo := repository.NewTVShowRepository(db)
 			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
 			tmdbClient := tmdb.NewClient("test-api-key")
-			tmdbClient.SetBaseURL(server.URL)
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
 
-			subManager := service.NewSubscriptionManager(tmdbClient, showRepo, episodeRepo)
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
 
 			// Subscribe multiple times
 			var firstShow *models.TVShow
 			for i := 0; i < subscribeCount; i++ {
-				show, err := subManager.Subscribe(tmdbID)
+				show, _, err := subManager.Subscribe(tmdbID)
 				if err != nil {
 					t.Logf("Failed to subscribe (attempt %d): %v", i+1, err)
 					return false
@@ -221,9 +209,9 @@ func TestSubscriptionIdempotence(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000000),                                            // tmdbID
+		gen.IntRange(1, 1000000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // name
-		gen.IntRange(1, 5),                                                  // subscribeCount
+		gen.IntRange(1, 5), // subscribeCount
 	))
 
 	properties.TestingRun(t)
@@ -280,3 +268,365 @@ func TestResourceTimeInference(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// Feature: tv-tracker, Property: Watch Progress Idempotence
+// For any show with a given number of season-1 episodes, marking that
+// season watched any number of times in a row SHALL leave WatchedEpisodes
+// equal to the episode count from the very first call - the
+// watched_episodes UNIQUE constraint means repeats never double-count.
+func TestMarkSeasonWatchedIdempotence(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("marking a season watched repeatedly never double-counts", prop.ForAll(
+		func(tmdbID, episodeCount, markCount int) bool {
+			episodes := make([]tmdb.EpisodeInfo, episodeCount)
+			for i := range episodes {
+				episodes[i] = tmdb.EpisodeInfo{SeasonNumber: 1, EpisodeNumber: i + 1, Name: "Episode"}
+			}
+
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          "Show",
+				Status:        "Returning Series",
+				OriginCountry: "US",
+				TotalSeasons:  1,
+			}, episodes)
+
+			dbPath := "test_mark_season_watched_idempotence.db"
+			defer os.Remove(dbPath)
+
+			db, err := repository.NewSQLiteDB(dbPath)
+			if err != nil {
+				t.Logf("Failed to create database: %v", err)
+				return false
+			}
+			defer db.Close()
+
+			if err := db.InitSchema(); err != nil {
+				t.Logf("Failed to init schema: %v", err)
+				return false
+			}
+
+			showRepo := repository.NewTVShowRepository(db)
+			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
+			tmdbClient := tmdb.NewClient("test-api-key")
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
+
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
+
+			show, _, err := subManager.Subscribe(tmdbID)
+			if err != nil || show == nil {
+				t.Logf("Failed to subscribe: %v", err)
+				return false
+			}
+
+			for i := 0; i < markCount; i++ {
+				if _, err := subManager.MarkSeasonWatched(show.ID, 1); err != nil {
+					t.Logf("Failed to mark season watched (attempt %d): %v", i+1, err)
+					return false
+				}
+			}
+
+			progress, err := subManager.GetAllSubscriptionsWithProgress()
+			if err != nil {
+				t.Logf("Failed to get progress: %v", err)
+				return false
+			}
+
+			for _, p := range progress {
+				if p.TMDBID != tmdbID {
+					continue
+				}
+				return p.WatchedEpisodes == episodeCount
+			}
+			t.Log("Subscribed show missing from progress list")
+			return false
+		},
+		gen.IntRange(1, 1000000), // tmdbID
+		gen.IntRange(1, 20),      // episodeCount
+		gen.IntRange(1, 5),       // markCount
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property: Watch Progress Bounded By Total
+// For any show, its WatchedEpisodes and SkippedEpisodes counts SHALL never
+// exceed its TotalEpisodes count, regardless of how many times a season is
+// marked watched or its specials skipped.
+func TestWatchProgressNeverExceedsTotal(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("watched/skipped counts never exceed total episodes", prop.ForAll(
+		func(tmdbID, episodeCount, specialsCount, repeatCount int) bool {
+			episodes := make([]tmdb.EpisodeInfo, 0, episodeCount+specialsCount)
+			for i := 0; i < episodeCount; i++ {
+				episodes = append(episodes, tmdb.EpisodeInfo{SeasonNumber: 1, EpisodeNumber: i + 1, Name: "Episode"})
+			}
+			for i := 0; i < specialsCount; i++ {
+				episodes = append(episodes, tmdb.EpisodeInfo{SeasonNumber: 0, EpisodeNumber: i + 1, Name: "Special"})
+			}
+
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          "Show",
+				Status:        "Returning Series",
+				OriginCountry: "US",
+				TotalSeasons:  1,
+			}, episodes)
+
+			dbPath := "test_watch_progress_bounded.db"
+			defer os.Remove(dbPath)
+
+			db, err := repository.NewSQLiteDB(dbPath)
+			if err != nil {
+				t.Logf("Failed to create database: %v", err)
+				return false
+			}
+			defer db.Close()
+
+			if err := db.InitSchema(); err != nil {
+				t.Logf("Failed to init schema: %v", err)
+				return false
+			}
+
+			showRepo := repository.NewTVShowRepository(db)
+			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
+			tmdbClient := tmdb.NewClient("test-api-key")
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
+
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
+
+			show, _, err := subManager.Subscribe(tmdbID)
+			if err != nil || show == nil {
+				t.Logf("Failed to subscribe: %v", err)
+				return false
+			}
+
+			for i := 0; i < repeatCount; i++ {
+				if _, err := subManager.MarkSeasonWatched(show.ID, 1); err != nil {
+					t.Logf("Failed to mark season watched: %v", err)
+					return false
+				}
+				if _, err := subManager.SkipAllSpecials(show.ID); err != nil {
+					t.Logf("Failed to skip specials: %v", err)
+					return false
+				}
+			}
+
+			progress, err := subManager.GetAllSubscriptionsWithProgress()
+			if err != nil {
+				t.Logf("Failed to get progress: %v", err)
+				return false
+			}
+
+			for _, p := range progress {
+				if p.TMDBID != tmdbID {
+					continue
+				}
+				if p.TotalEpisodes != episodeCount+specialsCount {
+					t.Logf("Unexpected total episodes: got %d, want %d", p.TotalEpisodes, episodeCount+specialsCount)
+					return false
+				}
+				return p.WatchedEpisodes <= p.TotalEpisodes && p.SkippedEpisodes <= p.TotalEpisodes
+			}
+			t.Log("Subscribed show missing from progress list")
+			return false
+		},
+		gen.IntRange(1, 1000000), // tmdbID
+		gen.IntRange(1, 20),      // episodeCount
+		gen.IntRange(0, 5),       // specialsCount
+		gen.IntRange(1, 5),       // repeatCount
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property: Manual Resource Time Survives Reconciliation
+// For any show with ResourceTimeIsManual set, ResourceTimeReconciler.RunNow
+// SHALL never change its ResourceTime, regardless of origin country or how
+// many times reconciliation runs.
+func TestManualResourceTimeSurvivesReconciliation(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("manual resource time is never overwritten by reconciliation", prop.ForAll(
+		func(tmdbID int, originCountry string, manualTime string, runCount int) bool {
+			if tmdbID <= 0 || manualTime == "" {
+				return true
+			}
+
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          "Show",
+				Status:        "Returning Series",
+				OriginCountry: originCountry,
+				TotalSeasons:  1,
+			}, []tmdb.EpisodeInfo{{SeasonNumber: 1, EpisodeNumber: 1, Name: "Episode"}})
+
+			dbPath := "test_manual_resource_time.db"
+			defer os.Remove(dbPath)
+
+			db, err := repository.NewSQLiteDB(dbPath)
+			if err != nil {
+				t.Logf("Failed to create database: %v", err)
+				return false
+			}
+			defer db.Close()
+
+			if err := db.InitSchema(); err != nil {
+				t.Logf("Failed to init schema: %v", err)
+				return false
+			}
+
+			showRepo := repository.NewTVShowRepository(db)
+			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
+			tmdbClient := tmdb.NewClient("test-api-key")
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
+
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
+
+			show, _, err := subManager.Subscribe(tmdbID)
+			if err != nil || show == nil {
+				t.Logf("Failed to subscribe: %v", err)
+				return false
+			}
+
+			if _, err := subManager.SetResourceTime(show.ID, manualTime, true); err != nil {
+				t.Logf("Failed to set resource time: %v", err)
+				return false
+			}
+
+			reconciler, err := service.NewResourceTimeReconciler(showRepo, "0 3 * * *")
+			if err != nil {
+				t.Logf("Failed to create reconciler: %v", err)
+				return false
+			}
+
+			for i := 0; i < runCount; i++ {
+				if _, err := reconciler.RunNow(); err != nil {
+					t.Logf("Reconciliation failed: %v", err)
+					return false
+				}
+			}
+
+			updated, err := showRepo.GetByID(show.ID)
+			if err != nil || updated == nil {
+				t.Logf("Failed to reload show: %v", err)
+				return false
+			}
+
+			return updated.ResourceTime == manualTime && updated.ResourceTimeIsManual
+		},
+		gen.IntRange(1, 1000000),
+		gen.OneConstOf("US", "CN", "JP", "FR", ""),
+		gen.OneConstOf("09:00", "15:30", "待定"),
+		gen.IntRange(1, 5),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: tv-tracker, Property: Non-Manual Resource Time Is Reconciled
+// For any show without a manual override, ResourceTimeReconciler.RunNow
+// SHALL set its ResourceTime to InferResourceTime(originCountry).
+func TestNonManualResourceTimeIsReconciled(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("non-manual resource time is recomputed by reconciliation", prop.ForAll(
+		func(tmdbID int, originCountry string) bool {
+			if tmdbID <= 0 {
+				return true
+			}
+
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          "Show",
+				Status:        "Returning Series",
+				OriginCountry: originCountry,
+				TotalSeasons:  1,
+			}, []tmdb.EpisodeInfo{{SeasonNumber: 1, EpisodeNumber: 1, Name: "Episode"}})
+
+			dbPath := "test_non_manual_resource_time.db"
+			defer os.Remove(dbPath)
+
+			db, err := repository.NewSQLiteDB(dbPath)
+			if err != nil {
+				t.Logf("Failed to create database: %v", err)
+				return false
+			}
+			defer db.Close()
+
+			if err := db.InitSchema(); err != nil {
+				t.Logf("Failed to init schema: %v", err)
+				return false
+			}
+
+			showRepo := repository.NewTVShowRepository(db)
+			episodeRepo := repository.NewEpisodeRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
+			tmdbClient := tmdb.NewClient("test-api-key")
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
+
+			subManager := service.NewSubscriptionManager(tmdbClient, cacheSvc, showRepo, episodeRepo, nil)
+
+			show, _, err := subManager.Subscribe(tmdbID)
+			if err != nil || show == nil {
+				t.Logf("Failed to subscribe: %v", err)
+				return false
+			}
+
+			// Corrupt the inferred value so a no-op reconciliation can't pass by accident.
+			show.ResourceTime = "00:00"
+			if err := showRepo.Update(show); err != nil {
+				t.Logf("Failed to corrupt resource time: %v", err)
+				return false
+			}
+
+			reconciler, err := service.NewResourceTimeReconciler(showRepo, "0 3 * * *")
+			if err != nil {
+				t.Logf("Failed to create reconciler: %v", err)
+				return false
+			}
+
+			if _, err := reconciler.RunNow(); err != nil {
+				t.Logf("Reconciliation failed: %v", err)
+				return false
+			}
+
+			updated, err := showRepo.GetByID(show.ID)
+			if err != nil || updated == nil {
+				t.Logf("Failed to reload show: %v", err)
+				return false
+			}
+
+			return updated.ResourceTime == service.InferResourceTime(originCountry) && !updated.ResourceTimeIsManual
+		},
+		gen.IntRange(1, 1000000),
+		gen.OneConstOf("US", "CN", "JP", "FR", ""),
+	))
+
+	properties.TestingRun(t)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
 
+	"tv-tracker/internal/metadata"
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
@@ -77,13 +78,15 @@ func TestUpdateTaskCompletion(t *testing.T) {
 				Description: fmt.Sprintf("新剧集 %s 已更新", episodeID),
 				IsCompleted: false,
 			}
-			if err := taskRepo.Create(task); err != nil {
+			if _, err := taskRepo.Create(task); err != nil {
 				t.Logf("Failed to create task: %v", err)
 				return false
 			}
 
 			// Create TaskBoardService and complete the task
-			taskBoard := service.NewTaskBoardService(taskRepo, showRepo)
+			auditRepo := repository.NewTaskAuditRepository(db)
+			dispatcher := service.NewDispatcher()
+			taskBoard := service.NewTaskBoardService(taskRepo, showRepo, dispatcher, auditRepo, map[string]metadata.Provider{})
 			if err := taskBoard.CompleteTask(task.ID); err != nil {
 				t.Logf("Failed to complete task: %v", err)
 				return false
@@ -121,16 +124,15 @@ func TestUpdateTaskCompletion(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000),                                               // tmdbID
+		gen.IntRange(1, 1000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
-		gen.IntRange(1, 10),                                                 // season
-		gen.IntRange(1, 24),                                                 // episode
+		gen.IntRange(1, 10), // season
+		gen.IntRange(1, 24), // episode
 	))
 
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 14: ORGANIZE_Task Completion Cascades to Archive
 // Validates: Requirements 6.2
 // For any ORGANIZE_Task, marking it complete SHALL set is_completed to True
@@ -190,13 +192,15 @@ func TestOrganizeTaskCompletionCascadesToArchive(t *testing.T) {
 				Description: fmt.Sprintf("《%s》已完结，请整理归档", showName),
 				IsCompleted: false,
 			}
-			if err := taskRepo.Create(task); err != nil {
+			if _, err := taskRepo.Create(task); err != nil {
 				t.Logf("Failed to create task: %v", err)
 				return false
 			}
 
 			// Create TaskBoardService and complete the task
-			taskBoard := service.NewTaskBoardService(taskRepo, showRepo)
+			auditRepo := repository.NewTaskAuditRepository(db)
+			dispatcher := service.NewDispatcher()
+			taskBoard := service.NewTaskBoardService(taskRepo, showRepo, dispatcher, auditRepo, map[string]metadata.Provider{})
 			if err := taskBoard.CompleteTask(task.ID); err != nil {
 				t.Logf("Failed to complete task: %v", err)
 				return false
@@ -234,7 +238,7 @@ func TestOrganizeTaskCompletionCascadesToArchive(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000),                                               // tmdbID
+		gen.IntRange(1, 1000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
 		gen.OneConstOf("Ended", "Canceled"),                                 // status
 	))
@@ -1,13 +1,9 @@
 package property
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/http/httptest"
 	"os"
 	"regexp"
-	"strings"
 	"testing"
 	"time"
 
@@ -19,6 +15,7 @@ import (
 	"tv-tracker/internal/repository"
 	"tv-tracker/internal/service"
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/tmdb/tmdbtest"
 )
 
 // Feature: tv-tracker, Property 9: Episode ID Format
@@ -69,8 +66,8 @@ func TestEpisodeIDFormat(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(0, 99),  // season (typical range)
-		gen.IntRange(0, 99),  // episode (typical range)
+		gen.IntRange(0, 99), // season (typical range)
+		gen.IntRange(0, 99), // episode (typical range)
 	))
 
 	// Test specific edge cases
@@ -96,14 +93,13 @@ func TestEpisodeIDFormat(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(0, 9),  // single digit season
-		gen.IntRange(0, 9),  // single digit episode
+		gen.IntRange(0, 9), // single digit season
+		gen.IntRange(0, 9), // single digit episode
 	))
 
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 5: Sync Processes Only Active Shows
 // Validates: Requirements 3.1, 5.4, 6.3
 // For any set of TVShow records, the sync operation SHALL process only those where
@@ -121,31 +117,10 @@ func TestSyncProcessesOnlyActiveShows(t *testing.T) {
 				return true
 			}
 
-			// Track which shows were processed by TMDB API calls
-			processedTMDBIDs := make(map[int]bool)
-
-			// Create mock TMDB server that tracks which shows are queried
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Extract TMDB ID from URL path
-				path := r.URL.Path
-				var tmdbID int
-				if _, err := fmt.Sscanf(path, "/tv/%d", &tmdbID); err == nil {
-					processedTMDBIDs[tmdbID] = true
-				}
-
-				// Return a valid response
-				response := map[string]interface{}{
-					"id":                1,
-					"name":              "Test Show",
-					"status":            "Returning Series",
-					"poster_path":       "/test.jpg",
-					"origin_country":    []string{"US"},
-					"number_of_seasons": 1,
-				}
-				w.WriteHeader(http.StatusOK)
-				json.NewEncoder(w).Encode(response)
-			}))
-			defer server.Close()
+			// Create fake TMDB server; fixtures for every show (active and
+			// archived) are registered below, and which ones actually get
+			// queried is read back afterwards from server.Messages().
+			server := tmdbtest.NewServer(t)
 
 			// Create temporary database
 			dbPath := fmt.Sprintf("test_sync_active_%d_%d.db", activeCount, archivedCount)
@@ -167,8 +142,10 @@ func TestSyncProcessesOnlyActiveShows(t *testing.T) {
 			showRepo := repository.NewTVShowRepository(db)
 			episodeRepo := repository.NewEpisodeRepository(db)
 			taskRepo := repository.NewTaskRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
 			tmdbClient := tmdb.NewClient("test-api-key")
-			tmdbClient.SetBaseURL(server.URL)
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
 
 			// Create active shows (is_archived = false)
 			activeTMDBIDs := make([]int, 0, activeCount)
@@ -187,6 +164,7 @@ func TestSyncProcessesOnlyActiveShows(t *testing.T) {
 					t.Logf("Failed to create active show: %v", err)
 					return false
 				}
+				server.AddShow(*show, nil)
 				activeTMDBIDs = append(activeTMDBIDs, tmdbID)
 			}
 
@@ -207,17 +185,27 @@ func TestSyncProcessesOnlyActiveShows(t *testing.T) {
 					t.Logf("Failed to create archived show: %v", err)
 					return false
 				}
+				server.AddShow(*show, nil)
 				archivedTMDBIDs = append(archivedTMDBIDs, tmdbID)
 			}
 
 			// Create TaskGenerator and run sync
-			taskGen := service.NewTaskGenerator(tmdbClient, showRepo, episodeRepo, taskRepo)
+			taskGen := service.NewTaskGenerator(tmdbClient, cacheSvc, showRepo, episodeRepo, taskRepo, nil, nil, nil, service.NewDispatcher())
 			_, err = taskGen.SyncAll()
 			if err != nil {
 				t.Logf("SyncAll failed: %v", err)
 				return false
 			}
 
+			// Determine which shows were actually queried from the request log
+			processedTMDBIDs := make(map[int]bool)
+			for _, msg := range server.Messages() {
+				var tmdbID int
+				if _, err := fmt.Sscanf(msg.Path, "/tv/%d", &tmdbID); err == nil {
+					processedTMDBIDs[tmdbID] = true
+				}
+			}
+
 			// Verify: all active shows should have been processed
 			for _, tmdbID := range activeTMDBIDs {
 				if !processedTMDBIDs[tmdbID] {
@@ -236,14 +224,13 @@ func TestSyncProcessesOnlyActiveShows(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(0, 5),  // activeCount
-		gen.IntRange(0, 5),  // archivedCount
+		gen.IntRange(0, 5), // activeCount
+		gen.IntRange(0, 5), // archivedCount
 	))
 
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 10: UPDATE_Task Idempotence
 // Validates: Requirements 4.3
 // For any TVShow and episode combination, running sync multiple times SHALL create
@@ -264,52 +251,23 @@ func TestUpdateTaskIdempotence(t *testing.T) {
 			// Today's date for air_date
 			today := time.Now().Format("2006-01-02")
 
-			// Create mock TMDB server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				path := r.URL.Path
-
-				// Handle TV details request
-				if strings.HasPrefix(path, "/tv/") && !strings.Contains(path, "/season/") {
-					response := map[string]interface{}{
-						"id":                tmdbID,
-						"name":              showName,
-						"status":            "Returning Series",
-						"poster_path":       "/test.jpg",
-						"origin_country":    []string{"US"},
-						"number_of_seasons": season,
-						"next_episode_to_air": map[string]interface{}{
-							"air_date":       today,
-							"episode_number": episode,
-							"season_number":  season,
-							"name":           "Test Episode",
-						},
-					}
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(response)
-					return
-				}
-
-				// Handle season episodes request
-				if strings.Contains(path, "/season/") {
-					response := map[string]interface{}{
-						"episodes": []map[string]interface{}{
-							{
-								"air_date":       today,
-								"episode_number": episode,
-								"season_number":  season,
-								"name":           "Test Episode",
-								"overview":       "Test overview",
-							},
-						},
-					}
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(response)
-					return
-				}
-
-				w.WriteHeader(http.StatusNotFound)
-			}))
-			defer server.Close()
+			// Create a fake TMDB server with one upcoming episode
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          showName,
+				TotalSeasons:  season,
+				Status:        "Returning Series",
+				OriginCountry: "US",
+			}, []tmdb.EpisodeInfo{
+				{
+					AirDate:       today,
+					EpisodeNumber: episode,
+					SeasonNumber:  season,
+					Name:          "Test Episode",
+					Overview:      "Test overview",
+				},
+			})
 
 			// Create temporary database
 			dbPath := fmt.Sprintf("test_update_idempotence_%d.db", tmdbID)
@@ -331,8 +289,10 @@ func TestUpdateTaskIdempotence(t *testing.T) {
 			showRepo := repository.NewTVShowRepository(db)
 			episodeRepo := repository.NewEpisodeRepository(db)
 			taskRepo := repository.NewTaskRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
 			tmdbClient := tmdb.NewClient("test-api-key")
-			tmdbClient.SetBaseURL(server.URL)
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
 
 			// Create a show
 			show := &models.TVShow{
@@ -350,7 +310,7 @@ func TestUpdateTaskIdempotence(t *testing.T) {
 			}
 
 			// Create TaskGenerator
-			taskGen := service.NewTaskGenerator(tmdbClient, showRepo, episodeRepo, taskRepo)
+			taskGen := service.NewTaskGenerator(tmdbClient, cacheSvc, showRepo, episodeRepo, taskRepo, nil, nil, nil, service.NewDispatcher())
 
 			// Run sync multiple times
 			for i := 0; i < syncCount; i++ {
@@ -372,7 +332,7 @@ func TestUpdateTaskIdempotence(t *testing.T) {
 			// Count tasks for this specific show and episode
 			count := 0
 			for _, task := range updateTasks {
-				if task.TVShowID == show.ID && strings.Contains(task.Description, episodeID) {
+				if task.TVShowID == show.ID && task.EpisodeID == episodeID {
 					count++
 				}
 			}
@@ -385,17 +345,16 @@ func TestUpdateTaskIdempotence(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000),                                               // tmdbID
+		gen.IntRange(1, 1000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
-		gen.IntRange(1, 10),                                                 // season
-		gen.IntRange(1, 24),                                                 // episode
-		gen.IntRange(1, 3),                                                  // syncCount
+		gen.IntRange(1, 10), // season
+		gen.IntRange(1, 24), // episode
+		gen.IntRange(1, 3),  // syncCount
 	))
 
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 12: ORGANIZE_Task Idempotence
 // Validates: Requirements 5.3
 // For any ended/canceled TVShow, running sync multiple times SHALL create
@@ -413,38 +372,16 @@ func TestOrganizeTaskIdempotence(t *testing.T) {
 				return true
 			}
 
-			// Create mock TMDB server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				path := r.URL.Path
-
-				// Handle TV details request
-				if strings.HasPrefix(path, "/tv/") && !strings.Contains(path, "/season/") {
-					response := map[string]interface{}{
-						"id":                tmdbID,
-						"name":              showName,
-						"status":            status, // "Ended" or "Canceled"
-						"poster_path":       "/test.jpg",
-						"origin_country":    []string{"US"},
-						"number_of_seasons": 1,
-					}
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(response)
-					return
-				}
-
-				// Handle season episodes request
-				if strings.Contains(path, "/season/") {
-					response := map[string]interface{}{
-						"episodes": []map[string]interface{}{},
-					}
-					w.WriteHeader(http.StatusOK)
-					json.NewEncoder(w).Encode(response)
-					return
-				}
-
-				w.WriteHeader(http.StatusNotFound)
-			}))
-			defer server.Close()
+			// Create a fake TMDB server reporting the show as ended/canceled
+			// with no upcoming episodes
+			server := tmdbtest.NewServer(t)
+			server.AddShow(models.TVShow{
+				TMDBID:        tmdbID,
+				Name:          showName,
+				Status:        status, // "Ended" or "Canceled"
+				OriginCountry: "US",
+				TotalSeasons:  1,
+			}, nil)
 
 			// Create temporary database
 			dbPath := fmt.Sprintf("test_organize_idempotence_%d.db", tmdbID)
@@ -466,8 +403,10 @@ func TestOrganizeTaskIdempotence(t *testing.T) {
 			showRepo := repository.NewTVShowRepository(db)
 			episodeRepo := repository.NewEpisodeRepository(db)
 			taskRepo := repository.NewTaskRepository(db)
+			cacheRepo := repository.NewMetadataCacheRepository(db)
 			tmdbClient := tmdb.NewClient("test-api-key")
-			tmdbClient.SetBaseURL(server.URL)
+			tmdbClient.SetBaseURL(server.URL())
+			cacheSvc := service.NewTMDBCacheService(tmdbClient, cacheRepo)
 
 			// Create a show (initially with "Returning Series" status, will be updated by sync)
 			show := &models.TVShow{
@@ -485,7 +424,7 @@ func TestOrganizeTaskIdempotence(t *testing.T) {
 			}
 
 			// Create TaskGenerator
-			taskGen := service.NewTaskGenerator(tmdbClient, showRepo, episodeRepo, taskRepo)
+			taskGen := service.NewTaskGenerator(tmdbClient, cacheSvc, showRepo, episodeRepo, taskRepo, nil, nil, nil, service.NewDispatcher())
 
 			// Run sync multiple times
 			for i := 0; i < syncCount; i++ {
@@ -525,7 +464,7 @@ func TestOrganizeTaskIdempotence(t *testing.T) {
 
 			return true
 		},
-		gen.IntRange(1, 1000),                                               // tmdbID
+		gen.IntRange(1, 1000), // tmdbID
 		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // showName
 		gen.OneConstOf("Ended", "Canceled"),                                 // status (only ended/canceled shows)
 		gen.IntRange(1, 3),                                                  // syncCount
@@ -12,6 +12,7 @@ import (
 	"tv-tracker/internal/models"
 	"tv-tracker/internal/notify"
 	"tv-tracker/internal/service"
+	"tv-tracker/tests/property/corpus"
 )
 
 // Feature: tv-tracker, Property 19: Daily Report Contains All Today's Episodes
@@ -19,12 +20,12 @@ import (
 // For any set of episodes where air_date equals today, the daily report SHALL include
 // all of them with show name, episode info, and resource time.
 func TestDailyReportContainsAllTodaysEpisodes(t *testing.T) {
-	parameters := gopter.DefaultTestParameters()
+	parameters := corpus.Parameters(t)
 	parameters.MinSuccessfulTests = 100
 
 	properties := gopter.NewProperties(parameters)
 
-	properties.Property("daily report contains all today's episodes with required info", prop.ForAll(
+	properties.Property("daily report contains all today's episodes with required info", corpus.RunWithCorpus(t,
 		func(tasks []testTask) bool {
 			// Convert test tasks to models.Task
 			var modelTasks []models.Task
@@ -100,13 +101,13 @@ func genTestTasks() gopter.Gen {
 // genTestTask generates a single test task
 func genTestTask() gopter.Gen {
 	return gopter.CombineGens(
-		gen.Int64Range(1, 1000),                                                 // ID
-		gen.Int64Range(1, 1000),                                                 // ShowID
-		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }),     // ShowName
-		gen.IntRange(1, 20),                                                     // Season
-		gen.IntRange(1, 30),                                                     // Episode
-		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }),     // EpisodeName
-		gen.OneConstOf("18:00", "20:00", "23:00", "待定"),                         // ResourceTime
+		gen.Int64Range(1, 1000), // ID
+		gen.Int64Range(1, 1000), // ShowID
+		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // ShowName
+		gen.IntRange(1, 20), // Season
+		gen.IntRange(1, 30), // Episode
+		gen.AnyString().SuchThat(func(s string) bool { return len(s) > 0 }), // EpisodeName
+		gen.OneConstOf("18:00", "20:00", "23:00", "待定"),                     // ResourceTime
 	).Map(func(values []interface{}) testTask {
 		return testTask{
 			ID:           values[0].(int64),
@@ -1,9 +1,6 @@
 package property
 
 import (
-	"encoding/json"
-	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -11,6 +8,8 @@ import (
 	"github.com/leanovate/gopter/prop"
 
 	"tv-tracker/internal/tmdb"
+	"tv-tracker/internal/tmdb/tmdbtest"
+	"tv-tracker/tests/property/corpus"
 )
 
 // Feature: tv-tracker, Property 2: API Error Handling
@@ -18,27 +17,22 @@ import (
 // For any TMDB API error response, the TMDB_Client SHALL return an error object
 // with a descriptive message string, never raise an unhandled exception.
 func TestAPIErrorHandling(t *testing.T) {
-	parameters := gopter.DefaultTestParameters()
+	parameters := corpus.Parameters(t)
 	parameters.MinSuccessfulTests = 100
 
 	properties := gopter.NewProperties(parameters)
 
-	properties.Property("API errors return descriptive error messages", prop.ForAll(
+	properties.Property("API errors return descriptive error messages", corpus.RunWithCorpus(t,
 		func(statusCode int, statusMessage string) bool {
-			// Create a mock server that returns an error response
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(statusCode)
-				errorResp := map[string]interface{}{
-					"status_code":    statusCode,
-					"status_message": statusMessage,
-				}
-				json.NewEncoder(w).Encode(errorResp)
-			}))
-			defer server.Close()
+			// Create a fake server that fails every path it's asked about
+			server := tmdbtest.NewServer(t)
+			server.FailNext("/search/tv", statusCode)
+			server.FailNext("/tv/12345/season/1", statusCode)
+			server.FailNext("/tv/12345", statusCode)
 
-			// Create client pointing to mock server
+			// Create client pointing to fake server
 			client := tmdb.NewClient("test-api-key")
-			client.SetBaseURL(server.URL)
+			client.SetBaseURL(server.URL())
 
 			// Test SearchTV - should return error, not panic
 			results, err := client.SearchTV("test query")
@@ -99,7 +93,6 @@ func TestAPIErrorHandling(t *testing.T) {
 	properties.TestingRun(t)
 }
 
-
 // Feature: tv-tracker, Property 1: TMDB Search Returns Valid Results
 // Validates: Requirements 1.1, 1.2
 // For any non-empty search query, the TMDB_Client SHALL return a list (possibly empty)
@@ -112,31 +105,25 @@ func TestSearchReturnsValidResults(t *testing.T) {
 
 	properties.Property("search returns valid structure with required fields", prop.ForAll(
 		func(query string, numResults int, resultIDs []int, resultNames []string) bool {
-			// Build mock response with generated data
-			results := make([]map[string]interface{}, 0, numResults)
+			// Build fixture results from generated data
+			results := make([]tmdb.SearchResult, 0, numResults)
 			for i := 0; i < numResults && i < len(resultIDs) && i < len(resultNames); i++ {
-				results = append(results, map[string]interface{}{
-					"id":             resultIDs[i],
-					"name":           resultNames[i],
-					"poster_path":    "/test_poster.jpg",
-					"first_air_date": "2024-01-15",
-					"origin_country": []string{"US"},
+				results = append(results, tmdb.SearchResult{
+					ID:            resultIDs[i],
+					Name:          resultNames[i],
+					PosterPath:    "/test_poster.jpg",
+					FirstAirDate:  "2024-01-15",
+					OriginCountry: []string{"US"},
 				})
 			}
 
-			// Create mock server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				response := map[string]interface{}{
-					"results": results,
-				}
-				w.WriteHeader(http.StatusOK)
-				json.NewEncoder(w).Encode(response)
-			}))
-			defer server.Close()
+			// Create fake server
+			server := tmdbtest.NewServer(t)
+			server.SetSearchResults(query, results)
 
-			// Create client pointing to mock server
+			// Create client pointing to fake server
 			client := tmdb.NewClient("test-api-key")
-			client.SetBaseURL(server.URL)
+			client.SetBaseURL(server.URL())
 
 			// Execute search
 			searchResults, err := client.SearchTV(query)